@@ -0,0 +1,98 @@
+// scenario-lint checks scenario files for suspicious constructs.
+//
+// Usage:
+//
+//	scenario-lint [-disable rule1,rule2] [-list-rules] <file or directory>...
+//
+// Directories are searched recursively for .scen.json and .steps.json files.
+// The exit code is 1 if any finding has error severity.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	lint "github.com/bhagyaraj1208117/andes-scenario-go/scenario-lint"
+)
+
+func main() {
+	disable := flag.String("disable", "", "comma-separated list of rule IDs to skip")
+	listRules := flag.Bool("list-rules", false, "print the available rules and exit")
+	flag.Parse()
+
+	linter := lint.NewLinter()
+	if *listRules {
+		for _, rule := range linter.Rules {
+			fmt.Printf("%-24s %-8s %s\n", rule.ID(), rule.Severity(), rule.Description())
+		}
+		return
+	}
+	if len(*disable) > 0 {
+		linter.DisableRules(strings.Split(*disable, ",")...)
+	}
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: scenario-lint [-disable rule1,rule2] [-list-rules] <file or directory>...")
+		os.Exit(2)
+	}
+
+	files, err := collectFiles(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	hasErrors := false
+	for _, file := range files {
+		findings, err := linter.LintFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			hasErrors = true
+			continue
+		}
+		for _, finding := range findings {
+			fmt.Println(finding.String())
+			if finding.Severity == mjparse.SeverityError {
+				hasErrors = true
+			}
+		}
+	}
+	if hasErrors {
+		os.Exit(1)
+	}
+}
+
+func isScenarioFile(path string) bool {
+	return strings.HasSuffix(path, ".scen.json") || strings.HasSuffix(path, ".steps.json")
+}
+
+func collectFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && isScenarioFile(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
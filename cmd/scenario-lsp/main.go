@@ -0,0 +1,20 @@
+// scenario-lsp is a language server for scenario files, communicating over stdio.
+//
+// It provides diagnostics, hover information on value expressions,
+// go-to-definition for referenced files and completion of fields, step types and addresses.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	scenlsp "github.com/bhagyaraj1208117/andes-scenario-go/scenario-lsp"
+)
+
+func main() {
+	server := scenlsp.NewServer(os.Stdin, os.Stdout)
+	if err := server.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
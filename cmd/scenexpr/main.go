@@ -0,0 +1,88 @@
+// scenexpr encodes scenario value expressions to bytes, and decodes bytes to value expressions.
+//
+// Usage:
+//
+//	scenexpr [-dir dir] [-format hex|base64|bytes] encode <expression>...
+//	scenexpr [-dir dir] decode [-hint address|number|str] <bytes>...
+//	scenexpr [-dir dir] [-format hex|base64|bytes] [repl]
+//
+// Paths in "file:" and "mxsc:" expressions are relative to dir, the current directory by default.
+// Bytes to decode are written as hex, with or without "0x", in base64 or as a byte array, e.g. "[1, 2]".
+// Without a command, or with repl, commands are read interactively; type :help for the list.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	scenexpr "github.com/bhagyaraj1208117/andes-scenario-go/scenario-expr"
+)
+
+const usage = "usage: scenexpr [-dir dir] [-format hex|base64|bytes] [encode <expression>... | decode [-hint address|number|str] <bytes>... | repl]"
+
+func main() {
+	dir := flag.String("dir", ".", "directory that file: and mxsc: paths are relative to")
+	format := flag.String("format", "hex", "output format of encoded values: hex, base64 or bytes")
+	flag.Parse()
+
+	tool := scenexpr.NewTool(*dir)
+	var err error
+	tool.Output, err = scenexpr.ParseOutputFormat(*format)
+	if err != nil {
+		exitWithUsage(err)
+	}
+
+	command := "repl"
+	if flag.NArg() > 0 {
+		command = flag.Arg(0)
+	}
+	switch command {
+	case "encode":
+		os.Exit(runEach(flag.Args()[1:], tool.Encode))
+	case "decode":
+		decodeFlags := flag.NewFlagSet("decode", flag.ExitOnError)
+		hintName := decodeFlags.String("hint", "", "how to decode the bytes: address, number or str")
+		_ = decodeFlags.Parse(flag.Args()[1:])
+		hint, err := scenexpr.ParseHint(*hintName)
+		if err != nil {
+			exitWithUsage(err)
+		}
+		os.Exit(runEach(decodeFlags.Args(), func(input string) (string, error) {
+			return tool.Decode(input, hint)
+		}))
+	case "repl":
+		err = tool.RunREPL(os.Stdin, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		exitWithUsage(fmt.Errorf("unknown command: %s", command))
+	}
+}
+
+// runEach prints the result for every argument, and yields the exit code.
+func runEach(args []string, run func(string) (string, error)) int {
+	if len(args) == 0 {
+		exitWithUsage(errors.New("nothing to convert"))
+	}
+	exitCode := 0
+	for _, arg := range args {
+		result, err := run(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", arg, err)
+			exitCode = 1
+			continue
+		}
+		fmt.Println(result)
+	}
+	return exitCode
+}
+
+func exitWithUsage(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	fmt.Fprintln(os.Stderr, usage)
+	os.Exit(2)
+}
@@ -0,0 +1,81 @@
+// scenfmt rewrites scenario files in canonical form.
+//
+// Usage:
+//
+//	scenfmt [-w] [-check] <file or directory>...
+//
+// Directories are searched recursively for .scen.json and .steps.json files.
+// By default the formatted contents are printed to stdout.
+// With -w, files are rewritten in place.
+// With -check, nothing is written: a diff is printed for every file that is not formatted,
+// and the exit code is 1 if there is any such file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	scenfmt "github.com/bhagyaraj1208117/andes-scenario-go/scenario-format"
+)
+
+func main() {
+	write := flag.Bool("w", false, "write the result to the source file instead of stdout")
+	check := flag.Bool("check", false, "print a diff and exit with status 1 if any file is not formatted")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: scenfmt [-w] [-check] <file or directory>...")
+		os.Exit(2)
+	}
+	if *write && *check {
+		fmt.Fprintln(os.Stderr, "-w and -check cannot be used together")
+		os.Exit(2)
+	}
+
+	files, err := scenfmt.CollectFiles(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	exitCode := 0
+	for _, file := range files {
+		result, err := scenfmt.FormatFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 2
+			continue
+		}
+
+		switch {
+		case *check:
+			if result.Changed() {
+				fmt.Print(result.Diff())
+				if exitCode == 0 {
+					exitCode = 1
+				}
+			}
+		case *write:
+			if result.Changed() {
+				err = writeFile(file, result.Formatted)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					exitCode = 2
+				}
+			}
+		default:
+			_, _ = os.Stdout.Write(result.Formatted)
+		}
+	}
+	os.Exit(exitCode)
+}
+
+// writeFile replaces the contents of a file, keeping its permissions.
+func writeFile(path string, contents []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, contents, info.Mode().Perm())
+}
@@ -0,0 +1,32 @@
+// scenschema generates the JSON Schemas of the scenario and test file formats.
+//
+// Usage:
+//
+//	scenschema [-out dir]
+//
+// Writes scenario.schema.json, steps.schema.json and test.schema.json to the output directory.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	schema "github.com/bhagyaraj1208117/andes-scenario-go/json/schema"
+)
+
+func main() {
+	outDir := flag.String("out", ".", "output directory")
+	flag.Parse()
+
+	// no functions are registered, tools embedding the interpreter generate their own schemas
+	for fileName, contents := range schema.SchemaFiles(&ei.ExprInterpreter{}) {
+		err := os.WriteFile(filepath.Join(*outDir, fileName), []byte(contents), 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
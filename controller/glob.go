@@ -0,0 +1,60 @@
+package scencontroller
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const globStarStar = "**"
+
+// checkGlob validates a glob pattern, so that matching cannot fail later.
+func checkGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == globStarStar {
+			continue
+		}
+		_, err := path.Match(segment, "")
+		if err != nil {
+			return fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchGlob reports whether a slash-separated path matches a glob pattern.
+// Within a path segment, patterns are those of path.Match. A "**" segment matches any number of segments, none included.
+// The pattern must have been validated with checkGlob.
+func matchGlob(pattern string, name string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(patternSegments []string, nameSegments []string) bool {
+	for len(patternSegments) > 0 {
+		if patternSegments[0] == globStarStar {
+			// collapse consecutive "**"
+			for len(patternSegments) > 0 && patternSegments[0] == globStarStar {
+				patternSegments = patternSegments[1:]
+			}
+			if len(patternSegments) == 0 {
+				return true
+			}
+			for skipped := 0; skipped <= len(nameSegments); skipped++ {
+				if matchGlobSegments(patternSegments, nameSegments[skipped:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(nameSegments) == 0 {
+			return false
+		}
+		matched, err := path.Match(patternSegments[0], nameSegments[0])
+		if err != nil || !matched {
+			return false
+		}
+		patternSegments = patternSegments[1:]
+		nameSegments = nameSegments[1:]
+	}
+	return len(nameSegments) == 0
+}
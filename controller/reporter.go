@@ -0,0 +1,184 @@
+package scencontroller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/TwiN/go-color"
+)
+
+// Reporter receives the results of a run of all the scenarios, or json tests, of a directory.
+// Its methods are called from a single goroutine, in the order of the files, also when scenarios run in parallel.
+type Reporter interface {
+	// SuiteStart is called before anything else, with the directory and the number of files, skipped ones included.
+	SuiteStart(suiteName string, total int)
+
+	// ScenarioStart is called for each file, before it runs or is skipped.
+	ScenarioStart(name string)
+
+	// ScenarioPass is called when a scenario passed.
+	ScenarioPass(name string, duration time.Duration)
+
+	// ScenarioFail is called when a scenario could not be parsed, or failed.
+	ScenarioFail(name string, err error, duration time.Duration)
+
+	// ScenarioSkip is called for the files that do not run.
+	ScenarioSkip(name string, reason string)
+
+	// SuiteEnd is called last, and yields the errors of writing the report, if any.
+	SuiteEnd(summary *ReportSummary) error
+}
+
+// ReportSummary counts the results of a run.
+type ReportSummary struct {
+	Passed   int
+	Failed   int
+	Skipped  int
+	Duration time.Duration
+}
+
+// NewReporter creates a reporter by format name: "console", "junit", "json" or "tap".
+func NewReporter(format string, out io.Writer) (Reporter, error) {
+	switch format {
+	case "console":
+		return NewConsoleReporter(out), nil
+	case "junit":
+		return NewJUnitReporter(out), nil
+	case "json":
+		return NewJSONReporter(out), nil
+	case "tap":
+		return NewTAPReporter(out), nil
+	default:
+		return nil, fmt.Errorf("unknown report format: %s, expected console, junit, json or tap", format)
+	}
+}
+
+// ConsoleReporter prints a coloured line for each file, and the counts at the end. It is the default reporter.
+type ConsoleReporter struct {
+	out   io.Writer
+	label string
+	plain bool
+	err   error
+}
+
+var _ Reporter = (*ConsoleReporter)(nil)
+
+// NewConsoleReporter creates a console reporter, writing to out.
+func NewConsoleReporter(out io.Writer) *ConsoleReporter {
+	return &ConsoleReporter{
+		out:   out,
+		label: "Scenario",
+	}
+}
+
+// newTestConsoleReporter creates the reporter with the output of json tests, without colours and errors.
+func newTestConsoleReporter(out io.Writer) *ConsoleReporter {
+	return &ConsoleReporter{
+		out:   out,
+		label: "Test",
+		plain: true,
+	}
+}
+
+func (cr *ConsoleReporter) printf(format string, args ...interface{}) {
+	if cr.err == nil {
+		_, cr.err = fmt.Fprintf(cr.out, format, args...)
+	}
+}
+
+// SuiteStart prints nothing.
+func (cr *ConsoleReporter) SuiteStart(_ string, _ int) {}
+
+// ScenarioStart starts the line of a file.
+func (cr *ConsoleReporter) ScenarioStart(name string) {
+	cr.printf("%s: %s ... ", cr.label, name)
+}
+
+// ScenarioPass ends the line with "ok".
+func (cr *ConsoleReporter) ScenarioPass(_ string, _ time.Duration) {
+	if cr.plain {
+		cr.printf("  ok\n")
+		return
+	}
+	cr.printf("  %s\n", color.Ize(color.Green, "ok"))
+}
+
+// ScenarioFail ends the line with "FAIL:" and the error.
+func (cr *ConsoleReporter) ScenarioFail(_ string, err error, _ time.Duration) {
+	if cr.plain {
+		cr.printf("  FAIL!!!\n")
+		return
+	}
+	cr.printf("  %s %s\n", color.Ize(color.Red, "FAIL:"), err.Error())
+}
+
+// ScenarioSkip ends the line with "skip", and the reason.
+func (cr *ConsoleReporter) ScenarioSkip(_ string, reason string) {
+	status := "skip"
+	if !cr.plain {
+		status = color.Ize(color.Yellow, status)
+	}
+	cr.printf("  %s (%s)\n", status, reason)
+}
+
+// SuiteEnd prints the counts.
+func (cr *ConsoleReporter) SuiteEnd(summary *ReportSummary) error {
+	cr.printf("Done. Passed: %d. Failed: %d. Skipped: %d.\n", summary.Passed, summary.Failed, summary.Skipped)
+	return cr.err
+}
+
+func defaultReporter(reporter Reporter) Reporter {
+	if reporter == nil {
+		return NewConsoleReporter(os.Stdout)
+	}
+	return reporter
+}
+
+// reportRun follows the results of a run, to be sent to a reporter.
+// errSomeTestsFailed is yielded by runs with failed scenarios, after all of them were reported.
+var errSomeTestsFailed = errors.New("some tests failed")
+
+type reportRun struct {
+	reporter Reporter
+	summary  ReportSummary
+	start    time.Time
+}
+
+func startReportRun(reporter Reporter, suiteName string, total int) *reportRun {
+	reporter.SuiteStart(suiteName, total)
+	return &reportRun{
+		reporter: reporter,
+		start:    time.Now(),
+	}
+}
+
+func (rr *reportRun) result(name string, err error, duration time.Duration) {
+	if err == nil {
+		rr.summary.Passed++
+		rr.reporter.ScenarioPass(name, duration)
+	} else {
+		rr.summary.Failed++
+		rr.reporter.ScenarioFail(name, err, duration)
+	}
+}
+
+func (rr *reportRun) skip(name string, reason string) {
+	rr.summary.Skipped++
+	rr.reporter.ScenarioSkip(name, reason)
+}
+
+// end reports the counts, and yields an error if some files failed.
+func (rr *reportRun) end() error {
+	rr.summary.Duration = time.Since(rr.start)
+	err := rr.reporter.SuiteEnd(&rr.summary)
+	if err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	if rr.summary.Failed > 0 {
+		return errSomeTestsFailed
+	}
+	return nil
+}
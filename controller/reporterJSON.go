@@ -0,0 +1,81 @@
+package scencontroller
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// JSONReporter writes a JSON object per line, for each event, e.g. {"event":"pass","name":"a.scen.json","duration":0.002}.
+// Durations are in seconds.
+type JSONReporter struct {
+	encoder *json.Encoder
+	err     error
+}
+
+var _ Reporter = (*JSONReporter)(nil)
+
+// NewJSONReporter creates a JSON lines reporter, writing to out.
+func NewJSONReporter(out io.Writer) *JSONReporter {
+	return &JSONReporter{encoder: json.NewEncoder(out)}
+}
+
+type jsonReportEvent struct {
+	Event    string   `json:"event"`
+	Name     string   `json:"name,omitempty"`
+	Total    *int     `json:"total,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Duration *float64 `json:"duration,omitempty"`
+	Passed   *int     `json:"passed,omitempty"`
+	Failed   *int     `json:"failed,omitempty"`
+	Skipped  *int     `json:"skipped,omitempty"`
+}
+
+func (jr *JSONReporter) write(event *jsonReportEvent) {
+	if jr.err == nil {
+		jr.err = jr.encoder.Encode(event)
+	}
+}
+
+func seconds(duration time.Duration) *float64 {
+	s := duration.Seconds()
+	return &s
+}
+
+// SuiteStart writes a "suiteStart" event.
+func (jr *JSONReporter) SuiteStart(suiteName string, total int) {
+	jr.write(&jsonReportEvent{Event: "suiteStart", Name: suiteName, Total: &total})
+}
+
+// ScenarioStart writes a "start" event.
+func (jr *JSONReporter) ScenarioStart(name string) {
+	jr.write(&jsonReportEvent{Event: "start", Name: name})
+}
+
+// ScenarioPass writes a "pass" event.
+func (jr *JSONReporter) ScenarioPass(name string, duration time.Duration) {
+	jr.write(&jsonReportEvent{Event: "pass", Name: name, Duration: seconds(duration)})
+}
+
+// ScenarioFail writes a "fail" event, with the error.
+func (jr *JSONReporter) ScenarioFail(name string, err error, duration time.Duration) {
+	jr.write(&jsonReportEvent{Event: "fail", Name: name, Error: err.Error(), Duration: seconds(duration)})
+}
+
+// ScenarioSkip writes a "skip" event, with the reason.
+func (jr *JSONReporter) ScenarioSkip(name string, reason string) {
+	jr.write(&jsonReportEvent{Event: "skip", Name: name, Reason: reason})
+}
+
+// SuiteEnd writes a "suiteEnd" event, with the counts.
+func (jr *JSONReporter) SuiteEnd(summary *ReportSummary) error {
+	jr.write(&jsonReportEvent{
+		Event:    "suiteEnd",
+		Duration: seconds(summary.Duration),
+		Passed:   &summary.Passed,
+		Failed:   &summary.Failed,
+		Skipped:  &summary.Skipped,
+	})
+	return jr.err
+}
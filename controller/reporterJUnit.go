@@ -0,0 +1,119 @@
+package scencontroller
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JUnitReporter writes a JUnit XML report, once all the files ran.
+type JUnitReporter struct {
+	out   io.Writer
+	suite junitTestSuite
+}
+
+var _ Reporter = (*JUnitReporter)(nil)
+
+// NewJUnitReporter creates a JUnit XML reporter, writing to out.
+func NewJUnitReporter(out io.Writer) *JUnitReporter {
+	return &JUnitReporter{out: out}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name       `xml:"testsuites"`
+	Tests    int            `xml:"tests,attr"`
+	Failures int            `xml:"failures,attr"`
+	Skipped  int            `xml:"skipped,attr"`
+	Time     string         `xml:"time,attr"`
+	Suite    junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Details string `xml:",chardata"`
+}
+
+func junitTime(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+// SuiteStart names the test suite.
+func (jr *JUnitReporter) SuiteStart(suiteName string, _ int) {
+	jr.suite.Name = suiteName
+}
+
+// ScenarioStart does nothing, test cases are added with their result.
+func (jr *JUnitReporter) ScenarioStart(_ string) {}
+
+func (jr *JUnitReporter) addTestCase(name string, duration time.Duration) *junitTestCase {
+	testCase := &junitTestCase{
+		Name:      name,
+		ClassName: jr.suite.Name,
+		Time:      junitTime(duration),
+	}
+	jr.suite.TestCases = append(jr.suite.TestCases, testCase)
+	return testCase
+}
+
+// ScenarioPass adds a test case.
+func (jr *JUnitReporter) ScenarioPass(name string, duration time.Duration) {
+	jr.addTestCase(name, duration)
+}
+
+// ScenarioFail adds a test case with a failure.
+func (jr *JUnitReporter) ScenarioFail(name string, err error, duration time.Duration) {
+	jr.addTestCase(name, duration).Failure = &junitMessage{
+		Message: err.Error(),
+		Details: err.Error(),
+	}
+}
+
+// ScenarioSkip adds a skipped test case.
+func (jr *JUnitReporter) ScenarioSkip(name string, reason string) {
+	jr.addTestCase(name, 0).Skipped = &junitMessage{Message: reason}
+}
+
+// SuiteEnd writes the report.
+func (jr *JUnitReporter) SuiteEnd(summary *ReportSummary) error {
+	jr.suite.Tests = summary.Passed + summary.Failed + summary.Skipped
+	jr.suite.Failures = summary.Failed
+	jr.suite.Skipped = summary.Skipped
+	jr.suite.Time = junitTime(summary.Duration)
+	report := junitTestSuites{
+		Tests:    jr.suite.Tests,
+		Failures: jr.suite.Failures,
+		Skipped:  jr.suite.Skipped,
+		Time:     jr.suite.Time,
+		Suite:    jr.suite,
+	}
+	_, err := io.WriteString(jr.out, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(jr.out)
+	encoder.Indent("", "    ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(jr.out, "\n")
+	return err
+}
@@ -0,0 +1,68 @@
+package scencontroller
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TAPReporter writes a Test Anything Protocol (version 13) report, with the errors as YAML diagnostics.
+type TAPReporter struct {
+	out    io.Writer
+	number int
+	err    error
+}
+
+var _ Reporter = (*TAPReporter)(nil)
+
+// NewTAPReporter creates a TAP reporter, writing to out.
+func NewTAPReporter(out io.Writer) *TAPReporter {
+	return &TAPReporter{out: out}
+}
+
+func (tr *TAPReporter) printf(format string, args ...interface{}) {
+	if tr.err == nil {
+		_, tr.err = fmt.Fprintf(tr.out, format, args...)
+	}
+}
+
+// SuiteStart writes the version and the plan.
+func (tr *TAPReporter) SuiteStart(suiteName string, total int) {
+	tr.printf("TAP version 13\n")
+	tr.printf("1..%d\n", total)
+	tr.printf("# %s\n", suiteName)
+}
+
+// ScenarioStart numbers the file.
+func (tr *TAPReporter) ScenarioStart(_ string) {
+	tr.number++
+}
+
+// ScenarioPass writes an "ok" line.
+func (tr *TAPReporter) ScenarioPass(name string, duration time.Duration) {
+	tr.printf("ok %d - %s\n", tr.number, name)
+}
+
+// ScenarioFail writes a "not ok" line, followed by the error.
+func (tr *TAPReporter) ScenarioFail(name string, err error, duration time.Duration) {
+	tr.printf("not ok %d - %s\n", tr.number, name)
+	tr.printf("  ---\n")
+	tr.printf("  message: |-\n")
+	for _, line := range strings.Split(err.Error(), "\n") {
+		tr.printf("    %s\n", line)
+	}
+	tr.printf("  duration_ms: %d\n", duration.Milliseconds())
+	tr.printf("  ...\n")
+}
+
+// ScenarioSkip writes an "ok" line with a SKIP directive.
+func (tr *TAPReporter) ScenarioSkip(name string, reason string) {
+	tr.printf("ok %d - %s # SKIP %s\n", tr.number, name, reason)
+}
+
+// SuiteEnd writes the counts, as a comment.
+func (tr *TAPReporter) SuiteEnd(summary *ReportSummary) error {
+	tr.printf("# passed %d, failed %d, skipped %d\n", summary.Passed, summary.Failed, summary.Skipped)
+	return tr.err
+}
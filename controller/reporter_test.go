@@ -0,0 +1,101 @@
+package scencontroller
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"sync"
+	"testing"
+
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+	"github.com/stretchr/testify/require"
+)
+
+func runWithReporter(t *testing.T, format string) string {
+	dir := writeScenarioFiles(t, map[string]string{
+		"a.scen.json":        "a",
+		"b.scen.json":        "fail",
+		"c.scen.json":        "c",
+		"excluded.scen.json": "excluded",
+	})
+	var out bytes.Buffer
+	reporter, err := NewReporter(format, &out)
+	require.Nil(t, err)
+	runner := &nameCheckingRunner{mutex: &sync.Mutex{}, ran: make(map[string]int)}
+	controller := NewScenarioController(runner, fr.NewDefaultFileResolver())
+	controller.Reporter = reporter
+	err = controller.RunAllJSONScenariosInDirectory(dir, "", ".scen.json", []string{"excluded.scen.json"}, DefaultRunScenarioOptions())
+	require.EqualError(t, err, "some tests failed")
+	return out.String()
+}
+
+func TestConsoleReporter(t *testing.T) {
+	output := runWithReporter(t, "console")
+	require.Contains(t, output, "Scenario: a.scen.json ... ")
+	require.Contains(t, output, "failed on purpose")
+	require.Contains(t, output, `(excluded by pattern "excluded.scen.json")`)
+	require.True(t, strings.HasSuffix(output, "Done. Passed: 2. Failed: 1. Skipped: 1.\n"))
+}
+
+func TestJUnitReporter(t *testing.T) {
+	output := runWithReporter(t, "junit")
+	require.True(t, strings.HasPrefix(output, xml.Header))
+
+	var report junitTestSuites
+	require.Nil(t, xml.Unmarshal([]byte(output), &report))
+	require.Equal(t, 4, report.Tests)
+	require.Equal(t, 1, report.Failures)
+	require.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Suite.TestCases, 4)
+	require.Equal(t, "a.scen.json", report.Suite.TestCases[0].Name)
+	require.Nil(t, report.Suite.TestCases[0].Failure)
+	require.Equal(t, "failed on purpose", report.Suite.TestCases[1].Failure.Message)
+	require.Equal(t, `excluded by pattern "excluded.scen.json"`, report.Suite.TestCases[3].Skipped.Message)
+}
+
+func TestJSONReporter(t *testing.T) {
+	output := runWithReporter(t, "json")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var events []map[string]interface{}
+	for _, line := range lines {
+		var event map[string]interface{}
+		require.Nil(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	var eventNames []string
+	for _, event := range events {
+		eventNames = append(eventNames, event["event"].(string))
+	}
+	require.Equal(t, []string{
+		"suiteStart",
+		"start", "pass",
+		"start", "fail",
+		"start", "pass",
+		"start", "skip",
+		"suiteEnd",
+	}, eventNames)
+	require.Equal(t, 4.0, events[0]["total"])
+	require.Equal(t, "b.scen.json", events[4]["name"])
+	require.Equal(t, "failed on purpose", events[4]["error"])
+	require.Contains(t, events[4], "duration")
+	require.Equal(t, `excluded by pattern "excluded.scen.json"`, events[8]["reason"])
+	require.Equal(t, 2.0, events[9]["passed"])
+	require.Equal(t, 1.0, events[9]["failed"])
+	require.Equal(t, 1.0, events[9]["skipped"])
+}
+
+func TestTAPReporter(t *testing.T) {
+	output := runWithReporter(t, "tap")
+	require.True(t, strings.HasPrefix(output, "TAP version 13\n1..4\n"))
+	require.Contains(t, output, "\nok 1 - a.scen.json\n")
+	require.Contains(t, output, "\nnot ok 2 - b.scen.json\n  ---\n  message: |-\n    failed on purpose\n")
+	require.Contains(t, output, "\nok 3 - c.scen.json\n")
+	require.Contains(t, output, "\nok 4 - excluded.scen.json # SKIP excluded by pattern \"excluded.scen.json\"\n")
+	require.True(t, strings.HasSuffix(output, "# passed 2, failed 1, skipped 1\n"))
+}
+
+func TestUnknownReportFormat(t *testing.T) {
+	_, err := NewReporter("html", &bytes.Buffer{})
+	require.EqualError(t, err, "unknown report format: html, expected console, junit, json or tap")
+}
@@ -7,12 +7,14 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
-
-	"github.com/TwiN/go-color"
+	"time"
 )
 
 // RunAllJSONScenariosInDirectory walks directory, parses and prepares all json scenarios,
 // then calls ScenarioRunner for each of them.
+// The Filter, and the excluded file patterns, select the scenarios to run; the others are skipped, with the reason.
+// With options.Parallelism above 1, scenarios run in a pool of workers, each with an executor from the ExecutorFactory.
+// Results are sent to the Reporter, the console by default, in the same order either way.
 func (r *ScenarioController) RunAllJSONScenariosInDirectory(
 	generalTestPath string,
 	specificTestPath string,
@@ -20,37 +22,215 @@ func (r *ScenarioController) RunAllJSONScenariosInDirectory(
 	excludedFilePatterns []string,
 	options *RunScenarioOptions) error {
 
-	mainDirPath := path.Join(generalTestPath, specificTestPath)
-	var nrPassed, nrFailed, nrSkipped int
-
-	err := filepath.Walk(mainDirPath, func(testFilePath string, info os.FileInfo, err error) error {
-		if strings.HasSuffix(testFilePath, allowedSuffix) {
-			fmt.Printf("Scenario: %s ... ", shortenTestPath(testFilePath, generalTestPath))
-			if isExcluded(excludedFilePatterns, testFilePath, generalTestPath) {
-				nrSkipped++
-				fmt.Printf("  %s\n", color.Ize(color.Yellow, "skip"))
-			} else {
-				r.Executor.Reset()
-				r.RunsNewTest = true
-				testErr := r.RunSingleJSONScenario(testFilePath, options)
-				if testErr == nil {
-					nrPassed++
-					fmt.Printf("  %s\n", color.Ize(color.Green, "ok"))
-				} else {
-					nrFailed++
-					fmt.Printf("  %s %s\n", color.Ize(color.Red, "FAIL:"), testErr.Error())
-				}
+	selection, err := r.newScenarioSelection(generalTestPath, specificTestPath, allowedSuffix, excludedFilePatterns)
+	if err != nil {
+		return err
+	}
+	files, err := selection.collect()
+	if err != nil {
+		return err
+	}
+	return r.runScenarioFiles(selection, files, options)
+}
+
+// scenarioSelection finds the scenario files of a directory, and tells which ones to skip.
+type scenarioSelection struct {
+	generalTestPath      string
+	mainDirPath          string
+	allowedSuffix        string
+	excludedFilePatterns []string
+	filter               *compiledFilter
+}
+
+func (r *ScenarioController) newScenarioSelection(
+	generalTestPath string,
+	specificTestPath string,
+	allowedSuffix string,
+	excludedFilePatterns []string) (*scenarioSelection, error) {
+
+	err := checkGlobs(excludedFilePatterns)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := r.Filter.compile(generalTestPath)
+	if err != nil {
+		return nil, err
+	}
+	return &scenarioSelection{
+		generalTestPath:      generalTestPath,
+		mainDirPath:          path.Join(generalTestPath, specificTestPath),
+		allowedSuffix:        allowedSuffix,
+		excludedFilePatterns: excludedFilePatterns,
+		filter:               filter,
+	}, nil
+}
+
+// collect walks the directory, and yields its scenario files in order.
+func (sel *scenarioSelection) collect() ([]*scenarioFile, error) {
+	var files []*scenarioFile
+	err := filepath.Walk(sel.mainDirPath, func(testFilePath string, info os.FileInfo, err error) error {
+		if strings.HasSuffix(testFilePath, sel.allowedSuffix) {
+			skipReason := excludedReason(sel.excludedFilePatterns, testFilePath, sel.generalTestPath)
+			if len(skipReason) == 0 {
+				skipReason = sel.filter.skipFile(testFilePath)
 			}
+			files = append(files, &scenarioFile{
+				path:       testFilePath,
+				shortPath:  shortenTestPath(testFilePath, sel.generalTestPath),
+				skipReason: skipReason,
+			})
 		}
 		return nil
 	})
-	if err != nil {
-		return err
+	return files, err
+}
+
+// runScenarioFiles runs files, in parallel if configured, and reports the results.
+func (r *ScenarioController) runScenarioFiles(selection *scenarioSelection, files []*scenarioFile, options *RunScenarioOptions) error {
+	filter := selection.filter
+	if options.Parallelism > 1 {
+		workers, err := r.newWorkers(files, options.Parallelism)
+		if err != nil {
+			return err
+		}
+		run := startReportRun(defaultReporter(r.Reporter), selection.mainDirPath, len(files))
+		runScenariosInParallel(workers, files, filter, options, func(file *scenarioFile, result *scenarioResult) {
+			run.reporter.ScenarioStart(file.shortPath)
+			run.fileResult(file, result)
+		})
+		return run.end()
+	}
+
+	run := startReportRun(defaultReporter(r.Reporter), selection.mainDirPath, len(files))
+	for _, file := range files {
+		run.reporter.ScenarioStart(file.shortPath)
+		var result *scenarioResult
+		if len(file.skipReason) == 0 {
+			r.reset()
+			r.RunsNewTest = true
+			result = r.runFile(file, filter, options)
+		}
+		run.fileResult(file, result)
+	}
+	return run.end()
+}
+
+type scenarioFile struct {
+	path       string
+	shortPath  string
+	skipReason string
+}
+
+type scenarioResult struct {
+	err        error
+	duration   time.Duration
+	skipReason string
+}
+
+// runFile parses a scenario, and runs it unless the filter skips it.
+func (r *ScenarioController) runFile(file *scenarioFile, filter *compiledFilter, options *RunScenarioOptions) *scenarioResult {
+	start := time.Now()
+	scenario, err := ParseScenariosScenario(r.Parser, file.path)
+	if err == nil {
+		if skipReason := filter.skipScenario(scenario, file.path); len(skipReason) > 0 {
+			return &scenarioResult{skipReason: skipReason}
+		}
+		err = r.runParsedScenario(scenario, options)
 	}
-	fmt.Printf("Done. Passed: %d. Failed: %d. Skipped: %d.\n", nrPassed, nrFailed, nrSkipped)
-	if nrFailed > 0 {
-		return errors.New("some tests failed")
+	return &scenarioResult{
+		err:      err,
+		duration: time.Since(start),
+	}
+}
+
+// fileResult reports a file, skipped before running when the result is nil.
+func (rr *reportRun) fileResult(file *scenarioFile, result *scenarioResult) {
+	switch {
+	case len(file.skipReason) > 0:
+		rr.skip(file.shortPath, file.skipReason)
+	case len(result.skipReason) > 0:
+		rr.skip(file.shortPath, result.skipReason)
+	default:
+		rr.result(file.shortPath, result.err, result.duration)
 	}
+}
 
-	return nil
+// newWorkers creates the controllers of a parallel run, at most one per file to run.
+// Each has its own executor, from the ExecutorFactory or the StepRunnerFactory, and parser, with a cloned FileResolver.
+func (r *ScenarioController) newWorkers(files []*scenarioFile, parallelism int) ([]*ScenarioController, error) {
+	if r.StepRunner == nil && r.ExecutorFactory == nil {
+		return nil, errors.New("running scenarios in parallel requires an ExecutorFactory")
+	}
+	if r.StepRunner != nil && r.StepRunnerFactory == nil {
+		return nil, errors.New("running scenarios in parallel requires a StepRunnerFactory")
+	}
+	nrToRun := 0
+	for _, file := range files {
+		if len(file.skipReason) == 0 {
+			nrToRun++
+		}
+	}
+	if parallelism > nrToRun {
+		parallelism = nrToRun
+	}
+	workers := make([]*ScenarioController, parallelism)
+	for i := range workers {
+		workers[i] = &ScenarioController{
+			Parser: r.Parser.Clone(),
+			OnStep: r.OnStep,
+		}
+		var err error
+		if r.StepRunner != nil {
+			workers[i].StepRunner, err = r.StepRunnerFactory()
+		} else {
+			workers[i].Executor, err = r.ExecutorFactory()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not create executor: %w", err)
+		}
+	}
+	return workers, nil
+}
+
+// runScenariosInParallel runs the files that are not excluded, each on the first available worker.
+// The results are reported in the order of the files, as soon as all the previous ones are known.
+func runScenariosInParallel(
+	workers []*ScenarioController,
+	files []*scenarioFile,
+	filter *compiledFilter,
+	options *RunScenarioOptions,
+	onResult func(*scenarioFile, *scenarioResult)) {
+
+	results := make([]chan *scenarioResult, len(files))
+	for i, file := range files {
+		if len(file.skipReason) == 0 {
+			results[i] = make(chan *scenarioResult, 1)
+		}
+	}
+	jobs := make(chan int)
+	for _, worker := range workers {
+		go func(worker *ScenarioController) {
+			for i := range jobs {
+				worker.reset()
+				worker.RunsNewTest = true
+				results[i] <- worker.runFile(files[i], filter, options)
+			}
+		}(worker)
+	}
+	go func() {
+		for i := range files {
+			if results[i] != nil {
+				jobs <- i
+			}
+		}
+		close(jobs)
+	}()
+
+	for i, file := range files {
+		var result *scenarioResult
+		if results[i] != nil {
+			result = <-results[i]
+		}
+		onResult(file, result)
+	}
 }
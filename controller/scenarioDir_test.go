@@ -0,0 +1,113 @@
+package scencontroller
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+	"github.com/stretchr/testify/require"
+)
+
+// nameCheckingRunner fails the scenarios whose name is "fail".
+type nameCheckingRunner struct {
+	mutex *sync.Mutex
+	ran   map[string]int
+}
+
+func (nr *nameCheckingRunner) Reset() {}
+
+func (nr *nameCheckingRunner) RunScenario(scenario *mj.Scenario, fileResolver fr.FileResolver) error {
+	nr.mutex.Lock()
+	nr.ran[scenario.Name]++
+	nr.mutex.Unlock()
+	if !scenario.IsNewTest {
+		return errors.New("not a new test")
+	}
+	if scenario.Name == "fail" {
+		return errors.New("failed on purpose")
+	}
+	return nil
+}
+
+func writeScenarioFiles(t *testing.T, names map[string]string) string {
+	dir := t.TempDir()
+	for fileName, scenarioName := range names {
+		filePath := filepath.Join(dir, fileName)
+		require.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		contents := `{"name": "` + scenarioName + `", "steps": []}`
+		require.Nil(t, os.WriteFile(filePath, []byte(contents), 0644))
+	}
+	return dir
+}
+
+func captureStdout(t *testing.T, run func()) string {
+	reader, writer, err := os.Pipe()
+	require.Nil(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	output := make(chan string)
+	go func() {
+		contents, _ := io.ReadAll(reader)
+		output <- string(contents)
+	}()
+	run()
+	os.Stdout = stdout
+	require.Nil(t, writer.Close())
+	return <-output
+}
+
+func TestRunAllJSONScenariosInParallel(t *testing.T) {
+	names := map[string]string{
+		"a.scen.json":           "a",
+		"b.scen.json":           "fail",
+		"c/d.scen.json":         "d",
+		"c/excluded.scen.json":  "excluded",
+		"e.scen.json":           "e",
+		"f.scen.json":           "f",
+		"g/h/i.scen.json":       "i",
+		"g/h/failing.scen.json": "fail",
+		"other.json":            "other",
+	}
+	dir := writeScenarioFiles(t, names)
+
+	run := func(parallelism int) (string, map[string]int, error) {
+		runner := &nameCheckingRunner{mutex: &sync.Mutex{}, ran: make(map[string]int)}
+		controller := NewScenarioController(runner, fr.NewDefaultFileResolver())
+		controller.ExecutorFactory = func() (ScenarioRunner, error) {
+			return runner, nil
+		}
+		options := DefaultRunScenarioOptions()
+		options.Parallelism = parallelism
+		var err error
+		output := captureStdout(t, func() {
+			err = controller.RunAllJSONScenariosInDirectory(dir, "", ".scen.json", []string{"c/excluded.scen.json"}, options)
+		})
+		return output, runner.ran, err
+	}
+
+	sequentialOutput, sequentialRan, sequentialErr := run(0)
+	require.EqualError(t, sequentialErr, "some tests failed")
+	require.Contains(t, sequentialOutput, "Done. Passed: 5. Failed: 2. Skipped: 1.")
+	require.Equal(t, map[string]int{"a": 1, "d": 1, "e": 1, "f": 1, "i": 1, "fail": 2}, sequentialRan)
+
+	for _, parallelism := range []int{2, 4, 20} {
+		parallelOutput, parallelRan, parallelErr := run(parallelism)
+		require.Equal(t, sequentialErr, parallelErr)
+		require.Equal(t, sequentialOutput, parallelOutput)
+		require.Equal(t, sequentialRan, parallelRan)
+	}
+}
+
+func TestRunAllJSONScenariosInParallelWithoutFactory(t *testing.T) {
+	dir := writeScenarioFiles(t, map[string]string{"a.scen.json": "a"})
+	controller := NewScenarioController(&nameCheckingRunner{}, fr.NewDefaultFileResolver())
+	options := DefaultRunScenarioOptions()
+	options.Parallelism = 2
+	err := controller.RunAllJSONScenariosInDirectory(dir, "", ".scen.json", nil, options)
+	require.EqualError(t, err, "running scenarios in parallel requires an ExecutorFactory")
+}
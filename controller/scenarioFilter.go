@@ -0,0 +1,349 @@
+package scencontroller
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+)
+
+// ScenarioFilter selects the scenarios that RunAllJSONScenariosInDirectory runs. The others are skipped, with the reason.
+// File patterns are globs relative to the general test path, in which "**" matches any number of directories.
+// Scenarios without a name are matched by name using their path.
+type ScenarioFilter struct {
+	// Include, if not empty, only runs the files that match one of the patterns.
+	Include []string
+
+	// Exclude skips the files that match any of the patterns.
+	Exclude []string
+
+	// Name, if set, is a regular expression that the names of the scenarios must match.
+	Name string
+
+	// Tags, if not empty, only runs the scenarios that have at least one of the tags.
+	Tags []string
+
+	// ExcludeTags skips the scenarios that have any of the tags.
+	ExcludeTags []string
+
+	// Run, if set, is an expression that scenarios must satisfy, e.g. `tag:dct && !tag:slow || name:^transfer`.
+	// Terms are "tag:<tag>", "path:<pattern>", "name:<regex>", or a regex for the name, as in "go test -run".
+	// They combine with "!", "&&", "||" and parentheses.
+	Run string
+}
+
+// Validate checks the patterns, regular expressions and run expression of the filter.
+func (f *ScenarioFilter) Validate() error {
+	_, err := f.compile("")
+	return err
+}
+
+type compiledFilter struct {
+	filter          *ScenarioFilter
+	generalTestPath string
+	name            *regexp.Regexp
+	run             runExpression
+}
+
+func checkGlobs(patterns []string) error {
+	for _, pattern := range patterns {
+		err := checkGlob(pattern)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *ScenarioFilter) compile(generalTestPath string) (*compiledFilter, error) {
+	compiled := &compiledFilter{
+		filter:          f,
+		generalTestPath: generalTestPath,
+	}
+	if f == nil {
+		return compiled, nil
+	}
+	err := checkGlobs(f.Include)
+	if err != nil {
+		return nil, err
+	}
+	err = checkGlobs(f.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Name) > 0 {
+		compiled.name, err = regexp.Compile(f.Name)
+		if err != nil {
+			return nil, fmt.Errorf("bad scenario name pattern: %w", err)
+		}
+	}
+	if len(strings.TrimSpace(f.Run)) > 0 {
+		compiled.run, err = parseRunExpression(f.Run)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return compiled, nil
+}
+
+// matchFilePatterns yields the first pattern that the file matches.
+// Patterns are relative to the general test path, and must have been validated.
+func matchFilePatterns(patterns []string, testPath string, generalTestPath string) (string, bool) {
+	for _, pattern := range patterns {
+		if matchGlob(path.Join(generalTestPath, pattern), testPath) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// excludedReason yields why a file is skipped by exclusion patterns, or "" if it is not.
+func excludedReason(patterns []string, testPath string, generalTestPath string) string {
+	if pattern, excluded := matchFilePatterns(patterns, testPath, generalTestPath); excluded {
+		return fmt.Sprintf("excluded by pattern %q", pattern)
+	}
+	return ""
+}
+
+// skipFile yields why a file is skipped, based on its path only, or "" if it is not.
+func (cf *compiledFilter) skipFile(testPath string) string {
+	f := cf.filter
+	if f == nil {
+		return ""
+	}
+	if len(f.Include) > 0 {
+		if _, included := matchFilePatterns(f.Include, testPath, cf.generalTestPath); !included {
+			return fmt.Sprintf("not included by patterns %q", strings.Join(f.Include, ", "))
+		}
+	}
+	return excludedReason(f.Exclude, testPath, cf.generalTestPath)
+}
+
+// skipScenario yields why a parsed scenario is skipped, or "" if it is not.
+func (cf *compiledFilter) skipScenario(scenario *mj.Scenario, testPath string) string {
+	f := cf.filter
+	if f == nil {
+		return ""
+	}
+	info := &scenarioInfo{
+		scenario:        scenario,
+		testPath:        testPath,
+		generalTestPath: cf.generalTestPath,
+	}
+	if cf.name != nil && !cf.name.MatchString(info.name()) {
+		return fmt.Sprintf("name does not match %q", f.Name)
+	}
+	if len(f.Tags) > 0 && !info.hasAnyTag(f.Tags) {
+		return fmt.Sprintf("not tagged %s", strings.Join(f.Tags, " or "))
+	}
+	for _, tag := range f.ExcludeTags {
+		if info.hasAnyTag([]string{tag}) {
+			return fmt.Sprintf("tagged %s", tag)
+		}
+	}
+	if cf.run != nil && !cf.run.matches(info) {
+		return fmt.Sprintf("not selected by %q", f.Run)
+	}
+	return ""
+}
+
+type scenarioInfo struct {
+	scenario        *mj.Scenario
+	testPath        string
+	generalTestPath string
+}
+
+func (info *scenarioInfo) name() string {
+	if len(info.scenario.Name) > 0 {
+		return info.scenario.Name
+	}
+	return shortenTestPath(info.testPath, info.generalTestPath)
+}
+
+func (info *scenarioInfo) hasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		for _, scenarioTag := range info.scenario.Tags {
+			if tag == scenarioTag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runExpression is the syntax tree of a run expression.
+type runExpression interface {
+	matches(info *scenarioInfo) bool
+}
+
+type runNot struct{ operand runExpression }
+
+type runAnd struct{ left, right runExpression }
+
+type runOr struct{ left, right runExpression }
+
+type runTag struct{ tag string }
+
+type runPath struct{ pattern string }
+
+type runName struct{ pattern *regexp.Regexp }
+
+func (e *runNot) matches(info *scenarioInfo) bool { return !e.operand.matches(info) }
+
+func (e *runAnd) matches(info *scenarioInfo) bool {
+	return e.left.matches(info) && e.right.matches(info)
+}
+
+func (e *runOr) matches(info *scenarioInfo) bool {
+	return e.left.matches(info) || e.right.matches(info)
+}
+
+func (e *runTag) matches(info *scenarioInfo) bool { return info.hasAnyTag([]string{e.tag}) }
+
+func (e *runPath) matches(info *scenarioInfo) bool {
+	_, matched := matchFilePatterns([]string{e.pattern}, info.testPath, info.generalTestPath)
+	return matched
+}
+
+func (e *runName) matches(info *scenarioInfo) bool { return e.pattern.MatchString(info.name()) }
+
+// runExpressionParser parses run expressions:
+//
+//	or   := and ("||" and)*
+//	and  := unary ("&&" unary)*
+//	unary:= "!" unary | "(" or ")" | term
+//
+// Terms end at whitespace, at "&&" or "||", or at a ")" that they did not open, so that regexes can have groups.
+type runExpressionParser struct {
+	source string
+	pos    int
+}
+
+func parseRunExpression(source string) (runExpression, error) {
+	p := &runExpressionParser{source: source}
+	expression, err := p.parseOr()
+	if err == nil {
+		p.skipSpace()
+		if p.pos < len(p.source) {
+			err = fmt.Errorf("unexpected %q", p.source[p.pos:])
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad run expression %q: %w", source, err)
+	}
+	return expression, nil
+}
+
+func (p *runExpressionParser) skipSpace() {
+	for p.pos < len(p.source) && (p.source[p.pos] == ' ' || p.source[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *runExpressionParser) accept(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.source[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *runExpressionParser) parseOr() (runExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &runOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *runExpressionParser) parseAnd() (runExpression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &runAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *runExpressionParser) parseUnary() (runExpression, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &runNot{operand: operand}, nil
+	}
+	if p.accept("(") {
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ) at offset %d", p.pos)
+		}
+		return expression, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *runExpressionParser) parseTerm() (runExpression, error) {
+	p.skipSpace()
+	start := p.pos
+	depth := 0
+scan:
+	for p.pos < len(p.source) {
+		rest := p.source[p.pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t':
+			break scan
+		case strings.HasPrefix(rest, "&&") || strings.HasPrefix(rest, "||"):
+			break scan
+		case rest[0] == '(':
+			depth++
+		case rest[0] == ')':
+			if depth == 0 {
+				break scan
+			}
+			depth--
+		}
+		p.pos++
+	}
+	term := p.source[start:p.pos]
+	if len(term) == 0 {
+		return nil, fmt.Errorf("missing term at offset %d", start)
+	}
+
+	switch {
+	case strings.HasPrefix(term, "tag:"):
+		return &runTag{tag: strings.TrimPrefix(term, "tag:")}, nil
+	case strings.HasPrefix(term, "path:"):
+		pattern := strings.TrimPrefix(term, "path:")
+		err := checkGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return &runPath{pattern: pattern}, nil
+	default:
+		pattern, err := regexp.Compile(strings.TrimPrefix(term, "name:"))
+		if err != nil {
+			return nil, err
+		}
+		return &runName{pattern: pattern}, nil
+	}
+}
@@ -0,0 +1,190 @@
+package scencontroller
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	for _, testCase := range []struct {
+		pattern string
+		name    string
+		matches bool
+	}{
+		{"*.scen.json", "a.scen.json", true},
+		{"*.scen.json", "dir/a.scen.json", false},
+		{"**/*.scen.json", "a.scen.json", true},
+		{"**/*.scen.json", "dir/sub/a.scen.json", true},
+		{"dir/**", "dir/sub/a.scen.json", true},
+		{"dir/**", "other/a.scen.json", false},
+		{"dir/**/a.scen.json", "dir/a.scen.json", true},
+		{"dir/**/a.scen.json", "dir/x/y/a.scen.json", true},
+		{"dir/**/a.scen.json", "dir/x/y/b.scen.json", false},
+		{"**/sub/**/*.json", "dir/sub/x/a.json", true},
+		{"**/sub/**/*.json", "dir/other/x/a.json", false},
+		{"d?r/[ab].json", "dir/b.json", true},
+	} {
+		require.Nil(t, checkGlob(testCase.pattern))
+		require.Equal(t, testCase.matches, matchGlob(testCase.pattern, testCase.name), "%s %s", testCase.pattern, testCase.name)
+	}
+
+	require.EqualError(t, checkGlob("dir/[a"), `bad pattern "dir/[a": syntax error in pattern`)
+}
+
+func TestRunExpression(t *testing.T) {
+	info := &scenarioInfo{
+		scenario: &mj.Scenario{
+			Name: "transfer with fees",
+			Tags: []string{"dct", "slow"},
+		},
+		testPath:        "tests/dct/transfer.scen.json",
+		generalTestPath: "tests",
+	}
+	for expression, matches := range map[string]bool{
+		"transfer":                          true,
+		"^fees":                             false,
+		"name:(transfer|deploy).with":       true,
+		"tag:dct":                           true,
+		"tag:dct && !tag:slow":              false,
+		"tag:dct && !tag:slow || transfer":  true,
+		"tag:dct && (!tag:slow || deploy)":  false,
+		"!(tag:other)&&path:dct/**":         true,
+		"path:**/transfer.scen.json":        true,
+		"path:*.scen.json":                  false,
+		"  tag:slow  ||  tag:nothing  ":     true,
+		"!!tag:dct":                         true,
+		"tag:nothing || (tag:dct && fees$)": true,
+	} {
+		parsed, err := parseRunExpression(expression)
+		require.Nil(t, err, expression)
+		require.Equal(t, matches, parsed.matches(info), expression)
+	}
+
+	for expression, message := range map[string]string{
+		"tag:a &&":       `bad run expression "tag:a &&": missing term at offset 8`,
+		"(tag:a":         `bad run expression "(tag:a": missing ) at offset 6`,
+		"tag:a)":         `bad run expression "tag:a)": unexpected ")"`,
+		"name:[":         "bad run expression \"name:[\": error parsing regexp: missing closing ]: `[`",
+		"path:[a":        `bad run expression "path:[a": bad pattern "[a": syntax error in pattern`,
+		"tag:a || || b":  `bad run expression "tag:a || || b": missing term at offset 9`,
+		"tag:a && !":     `bad run expression "tag:a && !": missing term at offset 10`,
+		"tag:a tag:b":    `bad run expression "tag:a tag:b": unexpected "tag:b"`,
+		"():":            `bad run expression "():": missing term at offset 1`,
+		"tag:a ||| b":    `bad run expression "tag:a ||| b": unexpected "b"`,
+		"tag:a & tag:b":  `bad run expression "tag:a & tag:b": unexpected "& tag:b"`,
+		"tag:a && (b ||": `bad run expression "tag:a && (b ||": missing term at offset 14`,
+	} {
+		_, err := parseRunExpression(expression)
+		require.EqualError(t, err, message, expression)
+	}
+}
+
+func writeTaggedScenarios(t *testing.T, scenarios map[string]*mj.Scenario) string {
+	dir := t.TempDir()
+	for fileName, scenario := range scenarios {
+		filePath := filepath.Join(dir, fileName)
+		require.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		tags, err := json.Marshal(scenario.Tags)
+		require.Nil(t, err)
+		contents := `{"name": "` + scenario.Name + `", "tags": ` + string(tags) + `, "steps": []}`
+		require.Nil(t, os.WriteFile(filePath, []byte(contents), 0644))
+	}
+	return dir
+}
+
+func TestScenarioFilter(t *testing.T) {
+	dir := writeTaggedScenarios(t, map[string]*mj.Scenario{
+		"dct/transfer.scen.json":      {Name: "dct transfer", Tags: []string{"dct"}},
+		"dct/slow/mint.scen.json":     {Name: "dct mint", Tags: []string{"dct", "slow"}},
+		"moax/transfer.scen.json":     {Name: "moax transfer", Tags: []string{}},
+		"moax/sub/deploy.scen.json":   {Name: "deploy", Tags: []string{"slow"}},
+		"moax/sub/upgrade.scen.json":  {Name: "upgrade", Tags: []string{}},
+		"moax/sub/excluded.scen.json": {Name: "excluded", Tags: []string{}},
+	})
+
+	run := func(filter *ScenarioFilter, parallelism int) ([]string, error) {
+		runner := &nameCheckingRunner{mutex: &sync.Mutex{}, ran: make(map[string]int)}
+		controller := NewScenarioController(runner, fr.NewDefaultFileResolver())
+		controller.ExecutorFactory = func() (ScenarioRunner, error) {
+			return runner, nil
+		}
+		controller.Filter = filter
+		var out bytes.Buffer
+		controller.Reporter = NewTAPReporter(&out)
+		options := DefaultRunScenarioOptions()
+		options.Parallelism = parallelism
+		err := controller.RunAllJSONScenariosInDirectory(dir, "", ".scen.json", []string{"**/excluded.scen.json"}, options)
+		var lines []string
+		for _, line := range strings.Split(out.String(), "\n") {
+			if strings.HasPrefix(line, "ok ") || strings.HasPrefix(line, "not ok ") {
+				lines = append(lines, line)
+			}
+		}
+		return lines, err
+	}
+
+	for _, parallelism := range []int{0, 3} {
+		lines, err := run(&ScenarioFilter{
+			Include:     []string{"**/*transfer*", "moax/sub/**"},
+			Exclude:     []string{"moax/sub/u*.scen.json"},
+			ExcludeTags: []string{"slow"},
+		}, parallelism)
+		require.Nil(t, err)
+		require.Equal(t, []string{
+			"ok 1 - dct/slow/mint.scen.json # SKIP not included by patterns \"**/*transfer*, moax/sub/**\"",
+			"ok 2 - dct/transfer.scen.json",
+			"ok 3 - moax/sub/deploy.scen.json # SKIP tagged slow",
+			"ok 4 - moax/sub/excluded.scen.json # SKIP excluded by pattern \"**/excluded.scen.json\"",
+			"ok 5 - moax/sub/upgrade.scen.json # SKIP excluded by pattern \"moax/sub/u*.scen.json\"",
+			"ok 6 - moax/transfer.scen.json",
+		}, lines)
+
+		lines, err = run(&ScenarioFilter{
+			Name: "transfer$",
+			Tags: []string{"dct", "other"},
+		}, parallelism)
+		require.Nil(t, err)
+		require.Equal(t, []string{
+			"ok 1 - dct/slow/mint.scen.json # SKIP name does not match \"transfer$\"",
+			"ok 2 - dct/transfer.scen.json",
+			"ok 3 - moax/sub/deploy.scen.json # SKIP name does not match \"transfer$\"",
+			"ok 4 - moax/sub/excluded.scen.json # SKIP excluded by pattern \"**/excluded.scen.json\"",
+			"ok 5 - moax/sub/upgrade.scen.json # SKIP name does not match \"transfer$\"",
+			"ok 6 - moax/transfer.scen.json # SKIP not tagged dct or other",
+		}, lines)
+
+		lines, err = run(&ScenarioFilter{
+			Run: "tag:slow && !path:dct/** || ^upgrade",
+		}, parallelism)
+		require.Nil(t, err)
+		require.Equal(t, []string{
+			"ok 1 - dct/slow/mint.scen.json # SKIP not selected by \"tag:slow && !path:dct/** || ^upgrade\"",
+			"ok 2 - dct/transfer.scen.json # SKIP not selected by \"tag:slow && !path:dct/** || ^upgrade\"",
+			"ok 3 - moax/sub/deploy.scen.json",
+			"ok 4 - moax/sub/excluded.scen.json # SKIP excluded by pattern \"**/excluded.scen.json\"",
+			"ok 5 - moax/sub/upgrade.scen.json",
+			"ok 6 - moax/transfer.scen.json # SKIP not selected by \"tag:slow && !path:dct/** || ^upgrade\"",
+		}, lines)
+	}
+
+	// bad patterns are errors, not panics
+	_, err := run(&ScenarioFilter{Include: []string{"[a"}}, 0)
+	require.EqualError(t, err, `bad pattern "[a": syntax error in pattern`)
+	_, err = run(&ScenarioFilter{Name: "("}, 0)
+	require.EqualError(t, err, "bad scenario name pattern: error parsing regexp: missing closing ): `(`")
+	require.NotNil(t, (&ScenarioFilter{Run: "tag:a &&"}).Validate())
+	require.Nil(t, (&ScenarioFilter{Run: "tag:a"}).Validate())
+
+	controller := NewScenarioController(&nameCheckingRunner{}, fr.NewDefaultFileResolver())
+	err = controller.RunAllJSONScenariosInDirectory(dir, "", ".scen.json", []string{"[a"}, DefaultRunScenarioOptions())
+	require.EqualError(t, err, `bad pattern "[a": syntax error in pattern`)
+}
@@ -1,16 +1,19 @@
 package scencontroller
 
 import (
+	"bufio"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
 	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
 	mjwrite "github.com/bhagyaraj1208117/andes-scenario-go/json/write"
 	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
 )
 
 // ParseScenariosScenario reads and parses a Scenarios scenario from a JSON file.
+// The file is streamed, and must be strict JSON, see mjparse.Parser.ParseScenarioFile.
 func ParseScenariosScenario(parser mjparse.Parser, scenFilePath string) (*mj.Scenario, error) {
 	var err error
 	scenFilePath, err = filepath.Abs(scenFilePath)
@@ -31,13 +34,9 @@ func ParseScenariosScenario(parser mjparse.Parser, scenFilePath string) (*mj.Sce
 		_ = jsonFile.Close()
 	}()
 
-	byteValue, err := ioutil.ReadAll(jsonFile)
-	if err != nil {
-		return nil, err
-	}
-
 	parser.ExprInterpreter.FileResolver.SetContext(scenFilePath)
-	return parser.ParseScenarioFile(byteValue)
+	parser.SourcePath = scenFilePath
+	return parser.ParseScenarioReader(bufio.NewReader(jsonFile))
 }
 
 // ParseScenariosScenarioDefaultParser reads and parses a Scenarios scenario from a JSON file.
@@ -47,6 +46,22 @@ func ParseScenariosScenarioDefaultParser(scenFilePath string) (*mj.Scenario, err
 	return ParseScenariosScenario(parser, scenFilePath)
 }
 
+// ParseExternalSteps reads and parses the steps file referenced by an externalSteps step.
+// The steps file inherits the constants in scope at the step.
+func ParseExternalSteps(parser mjparse.Parser, step *mj.ExternalStepsStep) (*mj.Scenario, error) {
+	scenario, _, err := parseExternalSteps(parser, step)
+	return scenario, err
+}
+
+// parseExternalSteps also yields the file resolver of the steps file.
+func parseExternalSteps(parser mjparse.Parser, step *mj.ExternalStepsStep) (*mj.Scenario, fr.FileResolver, error) {
+	parser.ExprInterpreter.FileResolver = parser.ExprInterpreter.FileResolver.Clone()
+	parser.ExprInterpreter.Constants = step.Constants
+	path := parser.ExprInterpreter.FileResolver.ResolveAbsolutePath(step.Path)
+	scenario, err := ParseScenariosScenario(parser, path)
+	return scenario, parser.ExprInterpreter.FileResolver, err
+}
+
 // WriteScenariosScenario exports a Scenarios scenario to a file, using the default formatting.
 func WriteScenariosScenario(scenario *mj.Scenario, toPath string) error {
 	jsonString := mjwrite.ScenarioToJSONString(scenario)
@@ -9,6 +9,13 @@ type RunScenarioOptions struct {
 	ForceTraceGas bool
 	UseWasmer1    bool
 	UseWasmer2    bool
+
+	// Parallelism is the number of scenarios of a directory that run at the same time, each with its own executor.
+	// With 0 or 1, they run one at a time, through the Executor of the controller.
+	Parallelism int
+
+	// MaxSteps, if positive, stops scenarios run with a StepRunner after that many steps, external ones included.
+	MaxSteps int
 }
 
 func applyScenarioOptions(scenario *mj.Scenario, options *RunScenarioOptions) {
@@ -32,6 +39,10 @@ func (r *ScenarioController) RunSingleJSONScenario(contextPath string, options *
 		return parseErr
 	}
 
+	return r.runParsedScenario(scenario, options)
+}
+
+func (r *ScenarioController) runParsedScenario(scenario *mj.Scenario, options *RunScenarioOptions) error {
 	if r.RunsNewTest {
 		scenario.IsNewTest = true
 		r.RunsNewTest = false
@@ -39,5 +50,8 @@ func (r *ScenarioController) RunSingleJSONScenario(contextPath string, options *
 
 	applyScenarioOptions(scenario, options)
 
+	if r.StepRunner != nil {
+		return r.runScenarioSteps(scenario, r.Parser.ExprInterpreter.FileResolver, options)
+	}
 	return r.Executor.RunScenario(scenario, r.Parser.ExprInterpreter.FileResolver)
 }
@@ -13,15 +13,53 @@ type ScenarioRunner interface {
 
 	// RunScenario executes the scenario and checks if it passed. Failure is signaled by returning an error.
 	// The FileResolver helps with resolving external steps.
-	// TODO: group into a "execution context" param.
+	// See StepRunner for running scenarios one step at a time.
 	RunScenario(*mj.Scenario, fr.FileResolver) error
 }
 
+// ScenarioRunnerFactory creates a new executor, for one of the workers of a parallel run.
+type ScenarioRunnerFactory func() (ScenarioRunner, error)
+
 // ScenarioController is a component that can run json scenarios, using a provided executor.
 type ScenarioController struct {
 	Executor    ScenarioRunner
 	RunsNewTest bool
 	Parser      mjparse.Parser
+
+	// ExecutorFactory creates the executors of the workers, when scenarios run in parallel.
+	ExecutorFactory ScenarioRunnerFactory
+
+	// StepRunner, if set, runs the scenarios one step at a time, instead of the Executor.
+	StepRunner StepRunner
+
+	// StepRunnerFactory creates the step runners of the workers, when scenarios run in parallel with a StepRunner.
+	StepRunnerFactory StepRunnerFactory
+
+	// OnStep is called after every step run by the StepRunner.
+	// When scenarios run in parallel, it is called from the worker goroutines.
+	OnStep StepObserver
+
+	// Filter selects the scenarios that RunAllJSONScenariosInDirectory runs, all of them by default.
+	Filter *ScenarioFilter
+
+	// Reporter receives the results of RunAllJSONScenariosInDirectory, they are printed to the console by default.
+	Reporter Reporter
+}
+
+// NewStepScenarioController creates a ScenarioController that runs scenarios one step at a time.
+func NewStepScenarioController(stepRunner StepRunner, fileResolver fr.FileResolver) *ScenarioController {
+	return &ScenarioController{
+		StepRunner: stepRunner,
+		Parser:     mjparse.NewParser(fileResolver),
+	}
+}
+
+func (r *ScenarioController) reset() {
+	if r.StepRunner != nil {
+		r.StepRunner.Reset()
+		return
+	}
+	r.Executor.Reset()
 }
 
 // NewScenarioController creates new ScenarioController instance.
@@ -0,0 +1,190 @@
+package scencontroller
+
+import (
+	"errors"
+	"fmt"
+
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+)
+
+// StepStatus is the outcome of a step.
+type StepStatus int
+
+const (
+	// StepPassed means that the step ran, and that its checks passed.
+	StepPassed StepStatus = iota
+
+	// StepFailed means that the step could not run, or that its checks failed.
+	StepFailed
+
+	// StepSkipped means that the runner did not run the step.
+	StepSkipped
+)
+
+func (status StepStatus) String() string {
+	switch status {
+	case StepPassed:
+		return "passed"
+	case StepFailed:
+		return "failed"
+	case StepSkipped:
+		return "skipped"
+	default:
+		return fmt.Sprintf("StepStatus(%d)", int(status))
+	}
+}
+
+// StepLog is a log produced by a transaction.
+type StepLog struct {
+	Address    []byte
+	Identifier []byte
+	Topics     [][]byte
+	Data       [][]byte
+}
+
+// StepResult is the outcome of a step, along with what the runner observed while running it.
+type StepResult struct {
+	Status StepStatus
+
+	// Err tells why the step failed.
+	Err error
+
+	GasUsed uint64
+	Logs    []*StepLog
+	Outputs [][]byte
+}
+
+// ExecutionContext is the state of a scenario run, passed to the StepRunner along with every step.
+type ExecutionContext struct {
+	// Scenario is the scenario being run. Its settings also apply to the steps of external steps files.
+	Scenario *mj.Scenario
+
+	// FileResolver resolves paths relative to the file of the step.
+	FileResolver fr.FileResolver
+
+	// StepNumber counts the steps run before this one, including those of external steps files.
+	StepNumber int
+
+	// StepPath locates the step, e.g. "steps[2]", or "steps[2] > steps[0]" for the steps of an externalSteps step.
+	StepPath string
+}
+
+// StepRunner describes a component that can run a scenario one step at a time.
+// The controller drives the steps, so that it can report, trace and stop the run at any step.
+type StepRunner interface {
+	// Reset clears state/world.
+	Reset()
+
+	// StartScenario is called before the first step of every scenario, e.g. to apply its gas schedule.
+	StartScenario(ctx *ExecutionContext) error
+
+	// RunStep executes a step and checks its expected results.
+	// It never receives externalSteps steps: the controller runs the steps of the external file instead.
+	RunStep(ctx *ExecutionContext, step mj.Step) *StepResult
+}
+
+// StepObserver is called after every step run by a StepRunner, e.g. for tracing.
+// Returning false stops the scenario after the step, like a breakpoint; unless the step failed, the scenario then passes.
+type StepObserver func(ctx *ExecutionContext, step mj.Step, result *StepResult) bool
+
+// StepRunnerFactory creates a new step runner, for one of the workers of a parallel run.
+type StepRunnerFactory func() (StepRunner, error)
+
+// ScenarioRunnerAdapter makes a ScenarioRunner usable as a StepRunner.
+// Every step runs as a scenario of its own, with the settings of the scenario being run.
+// Gas, logs and outputs are not available from ScenarioRunners, and are left empty.
+type ScenarioRunnerAdapter struct {
+	Runner ScenarioRunner
+}
+
+var _ StepRunner = (*ScenarioRunnerAdapter)(nil)
+
+// NewScenarioRunnerAdapter creates a StepRunner that runs steps with a ScenarioRunner.
+func NewScenarioRunnerAdapter(runner ScenarioRunner) *ScenarioRunnerAdapter {
+	return &ScenarioRunnerAdapter{Runner: runner}
+}
+
+// Reset resets the ScenarioRunner.
+func (sra *ScenarioRunnerAdapter) Reset() {
+	sra.Runner.Reset()
+}
+
+// StartScenario does nothing, scenario settings are passed along with each step.
+func (sra *ScenarioRunnerAdapter) StartScenario(_ *ExecutionContext) error {
+	return nil
+}
+
+// RunStep runs a scenario made of the single step.
+func (sra *ScenarioRunnerAdapter) RunStep(ctx *ExecutionContext, step mj.Step) *StepResult {
+	scenario := &mj.Scenario{
+		Name:        ctx.Scenario.Name,
+		CheckGas:    ctx.Scenario.CheckGas,
+		TraceGas:    ctx.Scenario.TraceGas,
+		IsNewTest:   ctx.Scenario.IsNewTest && ctx.StepNumber == 0,
+		GasSchedule: ctx.Scenario.GasSchedule,
+		Constants:   ctx.Scenario.Constants,
+		Steps:       []mj.Step{step},
+	}
+	err := sra.Runner.RunScenario(scenario, ctx.FileResolver)
+	if err != nil {
+		return &StepResult{Status: StepFailed, Err: err}
+	}
+	return &StepResult{Status: StepPassed}
+}
+
+// runScenarioSteps runs a scenario with the StepRunner, one step at a time.
+func (r *ScenarioController) runScenarioSteps(scenario *mj.Scenario, fileResolver fr.FileResolver, options *RunScenarioOptions) error {
+	ctx := &ExecutionContext{
+		Scenario:     scenario,
+		FileResolver: fileResolver,
+	}
+	err := r.StepRunner.StartScenario(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.runStepList(ctx, scenario.Steps, "", options)
+	return err
+}
+
+// runStepList yields false if the run stopped before the end of the steps.
+func (r *ScenarioController) runStepList(ctx *ExecutionContext, steps []mj.Step, pathPrefix string, options *RunScenarioOptions) (bool, error) {
+	for i, step := range steps {
+		stepPath := fmt.Sprintf("%ssteps[%d]", pathPrefix, i)
+		if externalStepsStep, isExternal := step.(*mj.ExternalStepsStep); isExternal {
+			parser := r.Parser
+			parser.ExprInterpreter.FileResolver = ctx.FileResolver
+			externalSteps, externalResolver, err := parseExternalSteps(parser, externalStepsStep)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", stepPath, err)
+			}
+			fileResolver := ctx.FileResolver
+			ctx.FileResolver = externalResolver
+			completed, err := r.runStepList(ctx, externalSteps.Steps, stepPath+" > ", options)
+			ctx.FileResolver = fileResolver
+			if err != nil || !completed {
+				return false, err
+			}
+			continue
+		}
+
+		if options.MaxSteps > 0 && ctx.StepNumber >= options.MaxSteps {
+			return false, nil
+		}
+		ctx.StepPath = stepPath
+		result := r.StepRunner.RunStep(ctx, step)
+		proceed := r.OnStep == nil || r.OnStep(ctx, step, result)
+		ctx.StepNumber++
+		if result.Status == StepFailed {
+			stepErr := result.Err
+			if stepErr == nil {
+				stepErr = errors.New("step failed")
+			}
+			return false, fmt.Errorf("%s: %w", stepPath, stepErr)
+		}
+		if !proceed {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,175 @@
+package scencontroller
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+	"github.com/stretchr/testify/require"
+)
+
+// commentStepRunner records the comments of setState steps, and fails on "fail".
+type commentStepRunner struct {
+	started  int
+	comments []string
+	paths    []string
+}
+
+func (sr *commentStepRunner) Reset() {}
+
+func (sr *commentStepRunner) StartScenario(_ *ExecutionContext) error {
+	sr.started++
+	return nil
+}
+
+func (sr *commentStepRunner) RunStep(ctx *ExecutionContext, step mj.Step) *StepResult {
+	comment := step.(*mj.SetStateStep).Comment
+	sr.comments = append(sr.comments, comment)
+	sr.paths = append(sr.paths, ctx.StepPath)
+	if comment == "fail" {
+		return &StepResult{Status: StepFailed, Err: errors.New("failed on purpose")}
+	}
+	return &StepResult{Status: StepPassed, GasUsed: uint64(ctx.StepNumber)}
+}
+
+const stepsScenario = `{
+	"steps": [
+		{"step": "setState", "comment": "first"},
+		{"step": "externalSteps", "path": "steps/other.steps.json"},
+		{"step": "setState", "comment": "last"}
+	]
+}`
+
+const externalSteps = `{
+	"steps": [
+		{"step": "setState", "comment": "external 1"},
+		{"step": "setState", "comment": "external 2"}
+	]
+}`
+
+func writeStepsScenario(t *testing.T, external string) string {
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "steps"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "steps", "other.steps.json"), []byte(external), 0644))
+	scenarioPath := filepath.Join(dir, "test.scen.json")
+	require.Nil(t, os.WriteFile(scenarioPath, []byte(stepsScenario), 0644))
+	return scenarioPath
+}
+
+func TestStepRunner(t *testing.T) {
+	scenarioPath := writeStepsScenario(t, externalSteps)
+	runner := &commentStepRunner{}
+	controller := NewStepScenarioController(runner, fr.NewDefaultFileResolver())
+	var gasUsed []uint64
+	controller.OnStep = func(ctx *ExecutionContext, step mj.Step, result *StepResult) bool {
+		gasUsed = append(gasUsed, result.GasUsed)
+		return true
+	}
+
+	err := controller.RunSingleJSONScenario(scenarioPath, DefaultRunScenarioOptions())
+	require.Nil(t, err)
+	require.Equal(t, 1, runner.started)
+	require.Equal(t, []string{"first", "external 1", "external 2", "last"}, runner.comments)
+	require.Equal(t, []string{"steps[0]", "steps[1] > steps[0]", "steps[1] > steps[1]", "steps[2]"}, runner.paths)
+	require.Equal(t, []uint64{0, 1, 2, 3}, gasUsed)
+}
+
+func TestStepRunnerMaxSteps(t *testing.T) {
+	scenarioPath := writeStepsScenario(t, externalSteps)
+	runner := &commentStepRunner{}
+	controller := NewStepScenarioController(runner, fr.NewDefaultFileResolver())
+	options := DefaultRunScenarioOptions()
+	options.MaxSteps = 2
+	err := controller.RunSingleJSONScenario(scenarioPath, options)
+	require.Nil(t, err)
+	require.Equal(t, []string{"first", "external 1"}, runner.comments)
+}
+
+func TestStepRunnerBreakpoint(t *testing.T) {
+	scenarioPath := writeStepsScenario(t, externalSteps)
+	runner := &commentStepRunner{}
+	controller := NewStepScenarioController(runner, fr.NewDefaultFileResolver())
+	controller.OnStep = func(ctx *ExecutionContext, step mj.Step, result *StepResult) bool {
+		return ctx.StepPath != "steps[1] > steps[1]"
+	}
+	err := controller.RunSingleJSONScenario(scenarioPath, DefaultRunScenarioOptions())
+	require.Nil(t, err)
+	require.Equal(t, []string{"first", "external 1", "external 2"}, runner.comments)
+}
+
+func TestStepRunnerFailure(t *testing.T) {
+	scenarioPath := writeStepsScenario(t, `{
+		"steps": [
+			{"step": "setState", "comment": "fail"},
+			{"step": "setState", "comment": "never"}
+		]
+	}`)
+	runner := &commentStepRunner{}
+	controller := NewStepScenarioController(runner, fr.NewDefaultFileResolver())
+	err := controller.RunSingleJSONScenario(scenarioPath, DefaultRunScenarioOptions())
+	require.EqualError(t, err, "steps[1] > steps[0]: failed on purpose")
+	require.Equal(t, []string{"first", "fail"}, runner.comments)
+}
+
+// singleStepRunner is a ScenarioRunner that records the number of steps of the scenarios it runs.
+type singleStepRunner struct {
+	stepCounts []int
+	newTests   []bool
+}
+
+func (sr *singleStepRunner) Reset() {}
+
+func (sr *singleStepRunner) RunScenario(scenario *mj.Scenario, fileResolver fr.FileResolver) error {
+	sr.stepCounts = append(sr.stepCounts, len(scenario.Steps))
+	sr.newTests = append(sr.newTests, scenario.IsNewTest)
+	if scenario.Steps[0].(*mj.SetStateStep).Comment == "last" {
+		return errors.New("failed on purpose")
+	}
+	return nil
+}
+
+func TestScenarioRunnerAdapter(t *testing.T) {
+	scenarioPath := writeStepsScenario(t, externalSteps)
+	runner := &singleStepRunner{}
+	controller := NewStepScenarioController(NewScenarioRunnerAdapter(runner), fr.NewDefaultFileResolver())
+	controller.RunsNewTest = true
+	err := controller.RunSingleJSONScenario(scenarioPath, DefaultRunScenarioOptions())
+	require.EqualError(t, err, "steps[2]: failed on purpose")
+	require.Equal(t, []int{1, 1, 1, 1}, runner.stepCounts)
+	require.Equal(t, []bool{true, false, false, false}, runner.newTests)
+}
+
+func TestStepRunnerInParallel(t *testing.T) {
+	dir := t.TempDir()
+	for name, comment := range map[string]string{"a.scen.json": "a", "b.scen.json": "fail", "c.scen.json": "c"} {
+		contents := `{"steps": [{"step": "setState", "comment": "` + comment + `"}]}`
+		require.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+	controller := NewStepScenarioController(&commentStepRunner{}, fr.NewDefaultFileResolver())
+	options := DefaultRunScenarioOptions()
+	options.Parallelism = 2
+	err := controller.RunAllJSONScenariosInDirectory(dir, "", ".scen.json", nil, options)
+	require.EqualError(t, err, "running scenarios in parallel requires a StepRunnerFactory")
+
+	var mutex sync.Mutex
+	var runners []*commentStepRunner
+	controller.StepRunnerFactory = func() (StepRunner, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		runner := &commentStepRunner{}
+		runners = append(runners, runner)
+		return runner, nil
+	}
+	var out bytes.Buffer
+	controller.Reporter = NewTAPReporter(&out)
+	err = controller.RunAllJSONScenariosInDirectory(dir, "", ".scen.json", nil, options)
+	require.EqualError(t, err, "some tests failed")
+	require.Contains(t, out.String(), "not ok 2 - b.scen.json")
+	require.Len(t, runners, 2)
+	require.Equal(t, 3, runners[0].started+runners[1].started)
+}
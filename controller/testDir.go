@@ -1,67 +1,55 @@
 package scencontroller
 
 import (
-	"errors"
-	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
-func isExcluded(excludedFilePatterns []string, testPath string, generalTestPath string) bool {
-	for _, et := range excludedFilePatterns {
-		excludedFullPath := path.Join(generalTestPath, et)
-		match, err := filepath.Match(excludedFullPath, testPath)
-		if err != nil {
-			panic(err)
-		}
-		if match {
-			return true
-		}
-	}
-	return false
-}
-
 // RunAllJSONTestsInDirectory walks directory, parses and prepares all json tests,
-// then calls testExecutor for each of them.
+// then calls testExecutor for each of them. Results are sent to the Reporter, the console by default.
 func (r *TestRunner) RunAllJSONTestsInDirectory(
 	generalTestPath string,
 	specificTestPath string,
 	allowedSuffix string,
 	excludedFilePatterns []string) error {
 
-	mainDirPath := path.Join(generalTestPath, specificTestPath)
-	var nrPassed, nrFailed, nrSkipped int
+	err := checkGlobs(excludedFilePatterns)
+	if err != nil {
+		return err
+	}
 
-	err := filepath.Walk(mainDirPath, func(testFilePath string, info os.FileInfo, err error) error {
+	mainDirPath := path.Join(generalTestPath, specificTestPath)
+	var testFilePaths []string
+	err = filepath.Walk(mainDirPath, func(testFilePath string, info os.FileInfo, err error) error {
 		if strings.HasSuffix(testFilePath, allowedSuffix) {
-			fmt.Printf("Test: %s ... ", shortenTestPath(testFilePath, generalTestPath))
-			if isExcluded(excludedFilePatterns, testFilePath, generalTestPath) {
-				nrSkipped++
-				fmt.Print("  skip\n")
-			} else {
-				testErr := r.RunSingleJSONTest(testFilePath)
-				if testErr == nil {
-					nrPassed++
-					fmt.Print("  ok\n")
-				} else {
-					nrFailed++
-					fmt.Print("  FAIL!!!\n")
-				}
-			}
+			testFilePaths = append(testFilePaths, testFilePath)
 		}
 		return nil
 	})
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Done. Passed: %d. Failed: %d. Skipped: %d.\n", nrPassed, nrFailed, nrSkipped)
-	if nrFailed > 0 {
-		return errors.New("some tests failed")
-	}
 
-	return nil
+	reporter := r.Reporter
+	if reporter == nil {
+		reporter = newTestConsoleReporter(os.Stdout)
+	}
+	run := startReportRun(reporter, mainDirPath, len(testFilePaths))
+	for _, testFilePath := range testFilePaths {
+		shortPath := shortenTestPath(testFilePath, generalTestPath)
+		reporter.ScenarioStart(shortPath)
+		if skipReason := excludedReason(excludedFilePatterns, testFilePath, generalTestPath); len(skipReason) > 0 {
+			run.skip(shortPath, skipReason)
+			continue
+		}
+		start := time.Now()
+		testErr := r.RunSingleJSONTest(testFilePath)
+		run.result(shortPath, testErr, time.Since(start))
+	}
+	return run.end()
 }
 
 func shortenTestPath(path string, generalTestPath string) string {
@@ -33,6 +33,7 @@ func (r *TestRunner) RunSingleJSONTest(contextPath string) error {
 	}
 
 	r.Parser.ExprInterpreter.FileResolver.SetContext(contextPath)
+	r.Parser.SourcePath = contextPath
 	top, parseErr := r.Parser.ParseTestFile(byteValue)
 	if parseErr != nil {
 		return parseErr
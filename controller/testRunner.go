@@ -16,6 +16,9 @@ type TestExecutor interface {
 type TestRunner struct {
 	Executor TestExecutor
 	Parser   mjparse.Parser
+
+	// Reporter receives the results of RunAllJSONTestsInDirectory, they are printed to the console by default.
+	Reporter Reporter
 }
 
 // NewTestRunner creates new TestRunner instance.
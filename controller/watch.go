@@ -0,0 +1,353 @@
+package scencontroller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+)
+
+// DefaultWatchPollInterval is the time between checks of the watched files, unless configured otherwise.
+const DefaultWatchPollInterval = 500 * time.Millisecond
+
+// WatchOptions configures WatchJSONScenariosInDirectory.
+type WatchOptions struct {
+	// PollInterval is the time between checks of the watched files, DefaultWatchPollInterval if not set.
+	PollInterval time.Duration
+
+	// Stop ends the watch when closed. Without it, the watch only ends on error.
+	Stop <-chan struct{}
+
+	// Out receives the list of changed files before every run, os.Stdout by default.
+	Out io.Writer
+}
+
+// WatchJSONScenariosInDirectory runs the scenarios of a directory, like RunAllJSONScenariosInDirectory,
+// then polls the scenario files and every file that they read, and runs the scenarios affected by each change.
+// Inputs are the "file:" and "mxsc:" values, ABI files, and the files of externalSteps steps, recursively.
+// New scenario files are run as soon as they appear. Failed scenarios do not end the watch.
+func (r *ScenarioController) WatchJSONScenariosInDirectory(
+	generalTestPath string,
+	specificTestPath string,
+	allowedSuffix string,
+	excludedFilePatterns []string,
+	options *RunScenarioOptions,
+	watchOptions *WatchOptions) error {
+
+	if watchOptions == nil {
+		watchOptions = &WatchOptions{}
+	}
+	watcher, err := r.newScenarioWatcher(generalTestPath, specificTestPath, allowedSuffix, excludedFilePatterns, options, watchOptions.Out)
+	if err != nil {
+		return err
+	}
+	err = watcher.runAll()
+	if err != nil {
+		return err
+	}
+
+	pollInterval := watchOptions.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultWatchPollInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-watchOptions.Stop:
+			return nil
+		case <-ticker.C:
+		}
+		err = watcher.runChanged()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// fileStamp is what polling compares to detect changes.
+type fileStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func statFile(filePath string) fileStamp {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+}
+
+// scenarioWatcher keeps the inputs of every scenario, and the reverse index, from each input to the scenarios reading it.
+// All paths are absolute.
+type scenarioWatcher struct {
+	controller         *ScenarioController
+	selection          *scenarioSelection
+	options            *RunScenarioOptions
+	out                io.Writer
+	absGeneralTestPath string
+	scenarios          map[string]bool
+	dependencies       map[string][]string
+	dependents         map[string]map[string]bool
+	stamps             map[string]fileStamp
+}
+
+func (r *ScenarioController) newScenarioWatcher(
+	generalTestPath string,
+	specificTestPath string,
+	allowedSuffix string,
+	excludedFilePatterns []string,
+	options *RunScenarioOptions,
+	out io.Writer) (*scenarioWatcher, error) {
+
+	selection, err := r.newScenarioSelection(generalTestPath, specificTestPath, allowedSuffix, excludedFilePatterns)
+	if err != nil {
+		return nil, err
+	}
+	absGeneralTestPath, err := filepath.Abs(generalTestPath)
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+	return &scenarioWatcher{
+		controller:         r,
+		selection:          selection,
+		options:            options,
+		out:                out,
+		absGeneralTestPath: absGeneralTestPath,
+		scenarios:          make(map[string]bool),
+		dependencies:       make(map[string][]string),
+		dependents:         make(map[string]map[string]bool),
+		stamps:             make(map[string]fileStamp),
+	}, nil
+}
+
+// runAll runs all scenarios, as the first run of the watch.
+func (w *scenarioWatcher) runAll() error {
+	files, err := w.selection.collect()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		scenarioPath, err := filepath.Abs(file.path)
+		if err != nil {
+			return err
+		}
+		w.scenarios[scenarioPath] = true
+	}
+	return w.run(files)
+}
+
+// runChanged runs the scenarios affected by the changes since the last poll, if any.
+func (w *scenarioWatcher) runChanged() error {
+	changed, affected, err := w.poll()
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	for _, filePath := range changed {
+		_, err = fmt.Fprintf(w.out, "Changed: %s\n", shortenTestPath(filePath, w.absGeneralTestPath))
+		if err != nil {
+			return err
+		}
+	}
+	return w.run(affected)
+}
+
+// run indexes the inputs of the files, then runs them. Failed scenarios are not an error.
+func (w *scenarioWatcher) run(files []*scenarioFile) error {
+	for _, file := range files {
+		if len(file.skipReason) > 0 {
+			continue
+		}
+		scenarioPath, err := filepath.Abs(file.path)
+		if err != nil {
+			return err
+		}
+		w.index(scenarioPath, w.controller.scenarioDependencies(scenarioPath))
+	}
+	err := w.controller.runScenarioFiles(w.selection, files, w.options)
+	if errors.Is(err, errSomeTestsFailed) {
+		return nil
+	}
+	return err
+}
+
+// poll yields the inputs and scenario files that changed since the last poll, and the scenarios to run again, in order.
+func (w *scenarioWatcher) poll() ([]string, []*scenarioFile, error) {
+	files, err := w.selection.collect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	affectedPaths := make(map[string]bool)
+	var changed []string
+	for filePath, stamp := range w.stamps {
+		newStamp := statFile(filePath)
+		if newStamp == stamp {
+			continue
+		}
+		w.stamps[filePath] = newStamp
+		changed = append(changed, filePath)
+		for scenarioPath := range w.dependents[filePath] {
+			affectedPaths[scenarioPath] = true
+		}
+	}
+
+	present := make(map[string]bool)
+	for _, file := range files {
+		scenarioPath, err := filepath.Abs(file.path)
+		if err != nil {
+			return nil, nil, err
+		}
+		present[scenarioPath] = true
+		if !w.scenarios[scenarioPath] {
+			w.scenarios[scenarioPath] = true
+			if _, watched := w.stamps[scenarioPath]; !watched {
+				changed = append(changed, scenarioPath)
+			}
+			affectedPaths[scenarioPath] = true
+		}
+	}
+	for scenarioPath := range w.scenarios {
+		if !present[scenarioPath] {
+			delete(w.scenarios, scenarioPath)
+			w.index(scenarioPath, nil)
+		}
+	}
+	sort.Strings(changed)
+
+	var affected []*scenarioFile
+	for _, file := range files {
+		scenarioPath, _ := filepath.Abs(file.path)
+		if affectedPaths[scenarioPath] && len(file.skipReason) == 0 {
+			affected = append(affected, file)
+		}
+	}
+	return changed, affected, nil
+}
+
+// index replaces the inputs of a scenario, and stamps the inputs that were not watched yet.
+func (w *scenarioWatcher) index(scenarioPath string, inputs []string) {
+	for _, input := range w.dependencies[scenarioPath] {
+		delete(w.dependents[input], scenarioPath)
+		if len(w.dependents[input]) == 0 {
+			delete(w.dependents, input)
+			delete(w.stamps, input)
+		}
+	}
+	if len(inputs) == 0 {
+		delete(w.dependencies, scenarioPath)
+		return
+	}
+	w.dependencies[scenarioPath] = inputs
+	for _, input := range inputs {
+		if w.dependents[input] == nil {
+			w.dependents[input] = make(map[string]bool)
+			w.stamps[input] = statFile(input)
+		}
+		w.dependents[input][scenarioPath] = true
+	}
+}
+
+// scenarioDependencies yields the scenario file, and all the files read when parsing it and its external steps, sorted.
+// Files that could not be read are included, so that the scenario runs again when they appear.
+func (r *ScenarioController) scenarioDependencies(scenarioPath string) []string {
+	recorder := &dependencyRecorder{paths: make(map[string]bool)}
+	parser := r.Parser.Clone()
+	parser.ExprInterpreter.FileResolver = &recordingFileResolver{
+		FileResolver: parser.ExprInterpreter.FileResolver,
+		recorder:     recorder,
+	}
+	recorder.record(scenarioPath)
+	scenario, err := ParseScenariosScenario(parser, scenarioPath)
+	if err == nil {
+		recorder.recordExternalSteps(parser, scenario.Steps)
+	}
+
+	paths := make([]string, 0, len(recorder.paths))
+	for filePath := range recorder.paths {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+type dependencyRecorder struct {
+	paths map[string]bool
+}
+
+// record yields false if the file was already recorded.
+func (dr *dependencyRecorder) record(filePath string) bool {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		absPath = filePath
+	}
+	if dr.paths[absPath] {
+		return false
+	}
+	dr.paths[absPath] = true
+	return true
+}
+
+// recordExternalSteps parses the external steps files, as runStepList does, so that their inputs get recorded.
+// Each file is only parsed once, which also stops cycles.
+func (dr *dependencyRecorder) recordExternalSteps(parser mjparse.Parser, steps []mj.Step) {
+	for _, step := range steps {
+		externalStepsStep, isExternal := step.(*mj.ExternalStepsStep)
+		if !isExternal {
+			continue
+		}
+		if !dr.record(parser.ExprInterpreter.FileResolver.ResolveAbsolutePath(externalStepsStep.Path)) {
+			continue
+		}
+		externalSteps, externalResolver, err := parseExternalSteps(parser, externalStepsStep)
+		if err != nil {
+			continue
+		}
+		externalParser := parser
+		externalParser.ExprInterpreter.FileResolver = externalResolver
+		dr.recordExternalSteps(externalParser, externalSteps.Steps)
+	}
+}
+
+// recordingFileResolver records the files that the wrapped resolver reads, clones included.
+type recordingFileResolver struct {
+	fr.FileResolver
+	recorder *dependencyRecorder
+}
+
+var _ fr.FileResolver = (*recordingFileResolver)(nil)
+
+// Clone clones the wrapped resolver, recording to the same recorder.
+func (rfr *recordingFileResolver) Clone() fr.FileResolver {
+	return &recordingFileResolver{
+		FileResolver: rfr.FileResolver.Clone(),
+		recorder:     rfr.recorder,
+	}
+}
+
+// ResolveFileValue records the file, then reads it with the wrapped resolver.
+func (rfr *recordingFileResolver) ResolveFileValue(value string) ([]byte, error) {
+	if len(value) > 0 {
+		rfr.recorder.record(rfr.FileResolver.ResolveAbsolutePath(value))
+	}
+	return rfr.FileResolver.ResolveFileValue(value)
+}
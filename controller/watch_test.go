@@ -0,0 +1,133 @@
+package scencontroller
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+	"github.com/stretchr/testify/require"
+)
+
+func writeWatchedFile(t *testing.T, dir string, fileName string, contents string) {
+	filePath := filepath.Join(dir, fileName)
+	require.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	require.Nil(t, os.WriteFile(filePath, []byte(contents), 0644))
+}
+
+func touchWatchedFile(t *testing.T, dir string, fileName string) {
+	later := time.Now().Add(time.Hour)
+	require.Nil(t, os.Chtimes(filepath.Join(dir, fileName), later, later))
+}
+
+func TestWatchRunsAffectedScenarios(t *testing.T) {
+	dir := t.TempDir()
+	writeWatchedFile(t, dir, "a.scen.json", `{
+		"name": "a",
+		"steps": [{"step": "setState", "accounts": {"address:owner": {"code": "file:data/a.wasm"}}}]
+	}`)
+	writeWatchedFile(t, dir, "b.scen.json", `{
+		"name": "b",
+		"steps": [{"step": "externalSteps", "path": "shared/init.steps.json"}]
+	}`)
+	writeWatchedFile(t, dir, "c.scen.json", `{"name": "c", "steps": []}`)
+	writeWatchedFile(t, dir, "shared/init.steps.json", `{
+		"name": "init",
+		"steps": [{"step": "setState", "accounts": {"address:owner": {"code": "file:../data/shared.wasm"}}}]
+	}`)
+	writeWatchedFile(t, dir, "data/a.wasm", "a")
+	writeWatchedFile(t, dir, "data/shared.wasm", "shared")
+
+	runner := &nameCheckingRunner{mutex: &sync.Mutex{}, ran: make(map[string]int)}
+	controller := NewScenarioController(runner, fr.NewDefaultFileResolver())
+	var report bytes.Buffer
+	controller.Reporter = NewTAPReporter(&report)
+	var out bytes.Buffer
+	watcher, err := controller.newScenarioWatcher(dir, "", ".scen.json", nil, DefaultRunScenarioOptions(), &out)
+	require.Nil(t, err)
+
+	require.Nil(t, watcher.runAll())
+	require.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1}, runner.ran)
+	absDir, err := filepath.Abs(dir)
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		filepath.Join(absDir, "b.scen.json"),
+		filepath.Join(absDir, "data/shared.wasm"),
+		filepath.Join(absDir, "shared/init.steps.json"),
+	}, watcher.dependencies[filepath.Join(absDir, "b.scen.json")])
+
+	pollAndRun := func() string {
+		out.Reset()
+		runner.ran = make(map[string]int)
+		require.Nil(t, watcher.runChanged())
+		return out.String()
+	}
+
+	require.Equal(t, "", pollAndRun())
+	require.Empty(t, runner.ran)
+
+	writeWatchedFile(t, dir, "data/shared.wasm", "changed")
+	require.Equal(t, "Changed: data/shared.wasm\n", pollAndRun())
+	require.Equal(t, map[string]int{"b": 1}, runner.ran)
+
+	touchWatchedFile(t, dir, "data/a.wasm")
+	touchWatchedFile(t, dir, "c.scen.json")
+	require.Equal(t, "Changed: c.scen.json\nChanged: data/a.wasm\n", pollAndRun())
+	require.Equal(t, map[string]int{"a": 1, "c": 1}, runner.ran)
+
+	writeWatchedFile(t, dir, "d.scen.json", `{"name": "fail", "steps": [{"step": "externalSteps", "path": "shared/init.steps.json"}]}`)
+	require.Equal(t, "Changed: d.scen.json\n", pollAndRun())
+	require.Equal(t, map[string]int{"fail": 1}, runner.ran)
+
+	touchWatchedFile(t, dir, "shared/init.steps.json")
+	require.Equal(t, "Changed: shared/init.steps.json\n", pollAndRun())
+	require.Equal(t, map[string]int{"b": 1, "fail": 1}, runner.ran)
+
+	require.Nil(t, os.Remove(filepath.Join(dir, "b.scen.json")))
+	require.Equal(t, "Changed: b.scen.json\n", pollAndRun())
+	require.Empty(t, runner.ran)
+	require.NotContains(t, watcher.dependencies, filepath.Join(absDir, "b.scen.json"))
+	require.Equal(t, map[string]bool{filepath.Join(absDir, "d.scen.json"): true},
+		watcher.dependents[filepath.Join(absDir, "data/shared.wasm")])
+}
+
+func TestWatchMissingInput(t *testing.T) {
+	dir := t.TempDir()
+	writeWatchedFile(t, dir, "a.scen.json", `{
+		"name": "a",
+		"steps": [{"step": "setState", "accounts": {"address:owner": {"code": "file:missing.wasm"}}}]
+	}`)
+
+	runner := &nameCheckingRunner{mutex: &sync.Mutex{}, ran: make(map[string]int)}
+	controller := NewScenarioController(runner, fr.NewDefaultFileResolver())
+	controller.Reporter = NewTAPReporter(&bytes.Buffer{})
+	var out bytes.Buffer
+	watcher, err := controller.newScenarioWatcher(dir, "", ".scen.json", nil, DefaultRunScenarioOptions(), &out)
+	require.Nil(t, err)
+
+	require.Nil(t, watcher.runAll())
+	require.Empty(t, runner.ran)
+
+	writeWatchedFile(t, dir, "missing.wasm", "code")
+	require.Nil(t, watcher.runChanged())
+	require.Equal(t, "Changed: missing.wasm\n", out.String())
+	require.Equal(t, map[string]int{"a": 1}, runner.ran)
+}
+
+func TestWatchStops(t *testing.T) {
+	dir := writeScenarioFiles(t, map[string]string{"a.scen.json": "a"})
+	runner := &nameCheckingRunner{mutex: &sync.Mutex{}, ran: make(map[string]int)}
+	controller := NewScenarioController(runner, fr.NewDefaultFileResolver())
+	controller.Reporter = NewTAPReporter(&bytes.Buffer{})
+	stop := make(chan struct{})
+	close(stop)
+	err := controller.WatchJSONScenariosInDirectory(dir, "", ".scen.json", nil, DefaultRunScenarioOptions(), &WatchOptions{
+		PollInterval: time.Millisecond,
+		Stop:         stop,
+	})
+	require.Nil(t, err)
+	require.Equal(t, map[string]int{"a": 1}, runner.ran)
+}
@@ -66,3 +66,9 @@ func isNonceKey(key []byte) bool {
 func getTokenNameFromKey(key []byte) []byte {
 	return key[len(dctTokenKeyPrefix):]
 }
+
+// IsDCTKey returns true if the given storage key belongs to a DCT token, role or nonce.
+// Such keys are protected, DCT data should be set through the DCT account fields instead.
+func IsDCTKey(key []byte) bool {
+	return isTokenKey(key) || isRoleKey(key) || isNonceKey(key)
+}
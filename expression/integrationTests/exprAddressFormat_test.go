@@ -0,0 +1,76 @@
+package scenjsontest
+
+import (
+	"encoding/hex"
+	"testing"
+
+	mei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	mer "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
+	"github.com/stretchr/testify/require"
+)
+
+const testAddressHex = "7958e3f8915ef660c15553be8a307d3e552606e0aee842d120342a4f759e6832"
+
+func TestBech32Converter(t *testing.T) {
+	converter, err := mei.NewBech32Converter("erd", 32)
+	require.Nil(t, err)
+	require.Equal(t, "erd", converter.HRP())
+	ei := interpreter()
+	ei.AddressConverter = converter
+
+	requireInterpretedHex(t, ei, "bech32:erd109vw87y3tmmxps242wlg5vra8e2jvphq4m5y95fqxs4y7av7dqeqrjj9h4", testAddressHex)
+	_, err = ei.InterpretString("bech32:moa109vw87y3tmmxps242wlg5vra8e2jvphq4m5y95fqxs4y7av7dqeqw252t9")
+	require.EqualError(t, err,
+		"bech32 address has HRP moa, expected erd: moa109vw87y3tmmxps242wlg5vra8e2jvphq4m5y95fqxs4y7av7dqeqw252t9 (at offset 0)")
+
+	// the default stays "moa"
+	requireInterpretedHex(t, interpreter(), "bech32:moa109vw87y3tmmxps242wlg5vra8e2jvphq4m5y95fqxs4y7av7dqeqw252t9", testAddressHex)
+
+	shortConverter, err := mei.NewBech32Converter("test", 20)
+	require.Nil(t, err)
+	ei.AddressConverter = shortConverter
+	requireInterpretedHex(t, ei, "bech32:test109vw87y3tmmxps242wlg5vra8e2jvphqvya4a7", testAddressHex[:40])
+
+	_, err = mei.NewBech32Converter("1nvalid", 32)
+	require.NotNil(t, err)
+}
+
+func TestAddressFormatPolicy(t *testing.T) {
+	ei := interpreter()
+	converter, err := mei.NewBech32Converter("erd", 32)
+	require.Nil(t, err)
+	er := mer.ExprReconstructor{
+		AddressFormats:   []mer.AddressFormat{mer.SCAddressFormat, mer.Bech32AddressFormat},
+		AddressConverter: converter,
+	}
+
+	// the VM type of the value is kept
+	sc, err := ei.InterpretString("sc:adder")
+	require.Nil(t, err)
+	require.Equal(t, "sc:adder", er.Reconstruct(sc, mer.AddressHint))
+
+	owner, err := ei.InterpretString("address:owner")
+	require.Nil(t, err)
+	require.Equal(t, "bech32:erd1damkuetjta047h6lta047h6lta047h6lta047h6lta047h6lta0saenzkj", er.Reconstruct(owner, mer.AddressHint))
+
+	address, err := hex.DecodeString(testAddressHex)
+	require.Nil(t, err)
+	require.Equal(t, "bech32:erd109vw87y3tmmxps242wlg5vra8e2jvphq4m5y95fqxs4y7av7dqeqrjj9h4", er.Reconstruct(address, mer.AddressHint))
+
+	// no format applies to 3 byte values
+	require.Equal(t, "0x616263 (str:abc)", er.Reconstruct([]byte("abc"), mer.AddressHint))
+
+	er.AddressFormats = []mer.AddressFormat{mer.AccountAddressFormat, mer.HexAddressFormat}
+	require.Equal(t, "address:owner", er.Reconstruct(owner, mer.AddressHint))
+	require.Equal(t, "0x"+testAddressHex, er.Reconstruct(address, mer.AddressHint))
+	require.Equal(t, "0x616263", er.Reconstruct([]byte("abc"), mer.AddressHint))
+
+	// round trips
+	for _, value := range [][]byte{sc, owner, address} {
+		for _, format := range []mer.AddressFormat{mer.SCAddressFormat, mer.AccountAddressFormat, mer.Bech32AddressFormat, mer.HexAddressFormat} {
+			er.AddressFormats = []mer.AddressFormat{format, mer.HexAddressFormat}
+			ei.AddressConverter = converter
+			requireInterpretedHex(t, ei, er.Reconstruct(value, mer.AddressHint), hex.EncodeToString(value))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package scenjsontest
+
+import (
+	"math/big"
+	"testing"
+
+	mei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	mer "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
+	"github.com/stretchr/testify/require"
+)
+
+func requireArithmetic(t *testing.T, expression string, expected string) {
+	result, err := mei.EvaluateArithmetic(expression)
+	require.Nil(t, err, expression)
+	require.Equal(t, expected, result.String(), expression)
+}
+
+func TestEvaluateArithmetic(t *testing.T) {
+	requireArithmetic(t, "1+2*3", "7")
+	requireArithmetic(t, "(1+2)*3", "9")
+	requireArithmetic(t, "1,000,000 - 1_000", "999000")
+	requireArithmetic(t, "0x10 + 0b11", "19")
+	requireArithmetic(t, "7 / 2", "3")
+	requireArithmetic(t, "-7 / 2", "-3")
+	requireArithmetic(t, "7 % 4", "3")
+	requireArithmetic(t, "2**3**2", "512")
+	requireArithmetic(t, "-2**2", "-4")
+	requireArithmetic(t, "10**18", "1000000000000000000")
+	requireArithmetic(t, "min(3, 1,000, 2)", "2")
+	requireArithmetic(t, "max(3, 1,000, 2) + 1", "1001")
+	requireArithmetic(t, "moax:1.5", "1500000000000000000")
+	requireArithmetic(t, "moax:2 - moax:0.000000000000000001", "1999999999999999999")
+	requireArithmetic(t, "moax:.5", "500000000000000000")
+
+	for _, invalid := range []string{
+		"1 +", "1 / 0", "5 % 0", "2 ** -1", "2 ** 100000", "(1 + 2", "min(1,5)", "moax:1.0000000000000000001", "moax:", "1 x",
+	} {
+		_, err := mei.EvaluateArithmetic(invalid)
+		require.NotNil(t, err, invalid)
+	}
+}
+
+func TestArithmeticInExpressions(t *testing.T) {
+	ei := interpreter()
+	for expression, expected := range map[string][]byte{
+		"1,000 - 1":         big.NewInt(999).Bytes(),
+		"moax:1":            big.NewInt(1_000_000_000_000_000_000).Bytes(),
+		"u16:2**8":          {0x01, 0x00},
+		"u8:1|(1+2)*3|u8:2": {0x01, 0x09, 0x02},
+		"u8:1|(2*(1+2))":    {0x01, 0x06},
+		"i16:1 - 2":         {0xff, 0xff},
+		"5 - 6":             {0xff},
+		"+(1+1)":            {0x02},
+		"biguint:max(1, 2)": {0x00, 0x00, 0x00, 0x01, 0x02},
+		"nested:(u8:1|moax:0.000000000000000255)": {0x00, 0x00, 0x00, 0x02, 0x01, 0xff},
+	} {
+		result, err := ei.InterpretString(expression)
+		require.Nil(t, err, expression)
+		require.Equal(t, expected, result, expression)
+	}
+
+	_, err := ei.InterpretString("u8:200+100")
+	require.NotNil(t, err)
+	_, err = ei.InterpretString("u32:1-2")
+	require.NotNil(t, err)
+	_, err = ei.InterpretString("biguint:1-2")
+	require.NotNil(t, err)
+}
+
+func TestReconstructDenominated(t *testing.T) {
+	er := reconstructor()
+	ei := interpreter()
+	for _, expression := range []string{"moax:1.5", "moax:0", "moax:123", "moax:0.000000000000000001"} {
+		value, err := ei.InterpretString(expression)
+		require.Nil(t, err)
+		reconstructed := er.Reconstruct(value, mer.MoaxHint)
+		require.Equal(t, expression, reconstructed)
+		reinterpreted, err := ei.InterpretString(reconstructed)
+		require.Nil(t, err)
+		require.Equal(t, value, reinterpreted)
+	}
+	require.Equal(t, "1500", er.ReconstructDenominated([]byte{0x05, 0xdc}, "unknown"))
+}
@@ -0,0 +1,90 @@
+package scenjsontest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstants(t *testing.T) {
+	ei := interpreter()
+	ei.Constants = map[string]string{
+		"owner":  "address:owner",
+		"amount": "1,000",
+		"total":  "${amount} * 2",
+		"token":  "str:TOKEN-123456",
+		"pair":   "var:owner|var:token",
+	}
+	requireInterpretedHex(t, ei, "var:amount", "03e8")
+	requireInterpretedHex(t, ei, "${amount}", "03e8")
+	requireInterpretedHex(t, ei, "var:total", "07d0")
+	requireInterpretedHex(t, ei, "u32:${total}", "000007d0")
+	requireInterpretedHex(t, ei, "u8:1|var:amount|u8:2", "0103e802")
+	requireInterpretedHex(t, ei, "nested:var:token", "0000000c544f4b454e2d313233343536")
+
+	owner, err := ei.InterpretString("address:owner")
+	require.Nil(t, err)
+	pair, err := ei.InterpretString("var:pair")
+	require.Nil(t, err)
+	require.Equal(t, append(owner, "TOKEN-123456"...), pair)
+	substituted, err := ei.InterpretString("${owner}|${token}")
+	require.Nil(t, err)
+	require.Equal(t, pair, substituted)
+}
+
+func TestConstantErrors(t *testing.T) {
+	ei := interpreter()
+	ei.Constants = map[string]string{
+		"a":   "var:b",
+		"b":   "${a}",
+		"bad": "u8:256",
+	}
+	_, err := ei.InterpretString("var:missing")
+	require.EqualError(t, err, "unknown constant: missing (at offset 0)")
+	_, err = ei.InterpretString("${missing}")
+	require.EqualError(t, err, "unknown constant: missing (at offset 0)")
+	_, err = ei.InterpretString("u8:1|u32:${missing}")
+	require.EqualError(t, err, "unknown constant: missing (at offset 5)")
+	_, err = ei.InterpretString("${a}")
+	require.EqualError(t, err, "error in constant a: error in constant b: constant defined in terms of itself: a -> b -> a (at offset 0)")
+	_, err = ei.InterpretString("u8:1|var:a")
+	require.EqualError(t, err,
+		"error in constant a: error in constant b: constant defined in terms of itself: a -> b -> a (at offset 5)")
+	_, err = ei.InterpretString("u8:1|var:bad")
+	require.ErrorContains(t, err, "error in constant bad: representation of 256 does not fit in 1 bytes")
+}
+
+func TestConstantReferences(t *testing.T) {
+	ei := interpreter()
+
+	// without constants in scope, "${" has no meaning
+	requireInterpretedHex(t, ei, "str:${x}", "247b787d")
+	noConstants, err := ei.InterpretString("address:${owner}")
+	require.Nil(t, err)
+
+	ei.Constants = map[string]string{
+		"owner": "address:owner",
+		"two":   "u8:1|u8:2",
+		"one":   "1",
+	}
+	// strings and file paths are never expanded
+	requireInterpretedHex(t, ei, "str:${x}", "247b787d")
+	requireInterpretedHex(t, ei, "str:a|${one}|str:b", "610162")
+
+	// a reference is a part of its own, whatever the constant holds
+	requireInterpretedHex(t, ei, "${two}", "0102")
+	requireInterpretedHex(t, ei, "u8:3|${two}|u8:4", "03010204")
+	requireInterpretedHex(t, ei, "nested:${two}", "000000020102")
+	requireInterpretedHex(t, ei, "keccak256:(${two})", "22ae6da6b482f9b1b19b0b897c3fd43884180a1c5ee361e1107a1bc635649dda")
+	_, err = ei.InterpretString("u32:${two}")
+	require.NotNil(t, err)
+
+	// in other arguments, it stands for the expression of the constant
+	requireInterpretedHex(t, ei, "u16:${one}", "0001")
+	requireInterpretedHex(t, ei, "${one} + 1|u8:5", "0205")
+
+	// "$${" escapes "${"
+	escaped, err := ei.InterpretString("address:$${owner}")
+	require.Nil(t, err)
+	require.Equal(t, noConstants, escaped)
+}
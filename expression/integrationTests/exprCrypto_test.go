@@ -0,0 +1,84 @@
+package scenjsontest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	mei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/stretchr/testify/require"
+)
+
+const testSeed = "0x9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+
+func requireInterpretedHex(t *testing.T, ei mei.ExprInterpreter, expression string, expectedHex string) {
+	result, err := ei.InterpretString(expression)
+	require.Nil(t, err)
+	require.Equal(t, expectedHex, hex.EncodeToString(result))
+}
+
+func TestHashes(t *testing.T) {
+	ei := interpreter()
+	requireInterpretedHex(t, ei, "sha256:str:abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+	requireInterpretedHex(t, ei, "blake2b:str:abc", "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319")
+	requireInterpretedHex(t, ei, "ripemd160:str:abc", "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc")
+	requireInterpretedHex(t, ei, "u8:1|sha256:(str:abc)|u8:2",
+		"01ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad02")
+	requireInterpretedHex(t, ei, "sha256:``a|``bc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+}
+
+func TestEd25519(t *testing.T) {
+	// RFC 8032, test 1
+	ei := interpreter()
+	requireInterpretedHex(t, ei, "pubkey:"+testSeed, "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	requireInterpretedHex(t, ei, "sign-ed25519:"+testSeed+":",
+		"e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+	requireInterpretedHex(t, ei, "sign-ed25519:("+testSeed+"):",
+		"e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+
+	_, err := ei.InterpretString("pubkey:str:short")
+	require.EqualError(t, err, "error computing pubkey: seed must be 32 bytes long, got 5 (at offset 0)")
+	_, err = ei.InterpretString("sign-ed25519:" + testSeed)
+	require.NotNil(t, err)
+}
+
+func TestSecp256k1(t *testing.T) {
+	ei := interpreter()
+	seed := "0x0000000000000000000000000000000000000000000000000000000000000001"
+	requireInterpretedHex(t, ei, "pubkey-secp256k1:"+seed, "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+
+	signature, err := ei.InterpretString("sign-secp256k1:(keccak256:str:seed):str:message\\|with\\|bars")
+	require.Nil(t, err)
+	again, err := ei.InterpretString("sign-secp256k1:(keccak256:str:seed):str:message\\|with\\|bars")
+	require.Nil(t, err)
+	require.Equal(t, signature, again)
+
+	publicKeyBytes, err := ei.InterpretString("pubkey-secp256k1:keccak256:str:seed")
+	require.Nil(t, err)
+	publicKey, err := secp256k1.ParsePubKey(publicKeyBytes)
+	require.Nil(t, err)
+	parsed, err := ecdsa.ParseDERSignature(signature)
+	require.Nil(t, err)
+	hash := sha256.Sum256([]byte("message|with|bars"))
+	require.True(t, parsed.Verify(hash[:], publicKey))
+
+	_, err = ei.InterpretString("pubkey-secp256k1:0x00")
+	require.NotNil(t, err)
+}
+
+type fixedHasher struct {
+	mei.DefaultHasher
+}
+
+func (fh *fixedHasher) Sha256(_ []byte) ([]byte, error) {
+	return []byte{0x42}, nil
+}
+
+func TestCustomHasher(t *testing.T) {
+	ei := interpreter()
+	ei.Hasher = &fixedHasher{}
+	requireInterpretedHex(t, ei, "sha256:str:abc", "42")
+	requireInterpretedHex(t, ei, "ripemd160:str:abc", "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc")
+}
@@ -0,0 +1,60 @@
+package scenjsontest
+
+import (
+	"encoding/hex"
+	"testing"
+
+	mei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	"github.com/stretchr/testify/require"
+)
+
+// deployer and addresses of contracts deployed on chain, as computed by the MultiversX SDKs
+const derivedCreator = "93ee6143cdc10ce79f15b2a6c2ad38e9b6021c72a1779051f47154fd54cfbd5e"
+const derivedAddressNonce0 = "00000000000000000500bb652200ed1f994200ab6699462cab4b1af7b11ebd5e"
+const derivedAddressNonce1 = "000000000000000005006e4f90488e27342f9a46e1809452c85ee7186566bd5e"
+
+func TestSCDerived(t *testing.T) {
+	ei := interpreter()
+	ei.VMType = &[2]byte{0x05, 0x00}
+
+	for expression, expected := range map[string]string{
+		"sc-derived:0x" + derivedCreator + ":0":      derivedAddressNonce0,
+		"sc-derived:0x" + derivedCreator + ":1":      derivedAddressNonce1,
+		"sc-derived:(0x" + derivedCreator + "):0x01": derivedAddressNonce1,
+		"sc-derived:(0x" + derivedCreator + "):2-1":  derivedAddressNonce1,
+	} {
+		result, err := ei.InterpretString(expression)
+		require.Nil(t, err, expression)
+		require.Equal(t, expected, hex.EncodeToString(result), expression)
+	}
+
+	result, err := ei.InterpretString("u8:1|sc-derived:0x" + derivedCreator + ":1|u8:2")
+	require.Nil(t, err)
+	require.Equal(t, "01"+derivedAddressNonce1+"02", hex.EncodeToString(result))
+
+	creator, _ := hex.DecodeString(derivedCreator)
+	derived, err := mei.DeriveSCAddress(creator, 1, []byte{0x05, 0x00})
+	require.Nil(t, err)
+	require.Equal(t, derivedAddressNonce1, hex.EncodeToString(derived))
+
+	// creators given by name
+	owner, err := ei.InterpretString("address:owner#12")
+	require.Nil(t, err)
+	expected, err := mei.DeriveSCAddress(owner, 5, []byte{0x05, 0x00})
+	require.Nil(t, err)
+	for _, expression := range []string{
+		"sc-derived:address:owner#12:5",
+		"sc-derived:(address:owner#12):5",
+	} {
+		result, err := ei.InterpretString(expression)
+		require.Nil(t, err, expression)
+		require.Equal(t, expected, result, expression)
+	}
+
+	_, err = ei.InterpretString("sc-derived:address:owner")
+	require.NotNil(t, err)
+	_, err = ei.InterpretString("sc-derived:str:abc:1")
+	require.ErrorContains(t, err, "creator address must be 32 bytes long, got 3")
+	_, err = ei.InterpretString("sc-derived:address:owner:0x010000000000000000")
+	require.ErrorContains(t, err, "nonce does not fit in 8 bytes")
+}
@@ -0,0 +1,143 @@
+package scenjsontest
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	mei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	mer "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
+	"github.com/stretchr/testify/require"
+)
+
+const testTokenSuffix = "-abcdef"
+
+func interpreterWithFunctions(t *testing.T) mei.ExprInterpreter {
+	ei := interpreter()
+	require.Nil(t, ei.RegisterFunction(&mei.Function{
+		Name: "dctid",
+		Args: []mei.FunctionArgKind{mei.StringArg},
+		Call: func(args [][]byte) ([]byte, error) {
+			if len(args[0]) == 0 {
+				return nil, errors.New("empty ticker")
+			}
+			return append(args[0], testTokenSuffix...), nil
+		},
+	}))
+	require.Nil(t, ei.RegisterFunction(&mei.Function{
+		Name: "repeat",
+		Args: []mei.FunctionArgKind{mei.NumberArg, mei.ExpressionArg},
+		Call: func(args [][]byte) ([]byte, error) {
+			count := big.NewInt(0).SetBytes(args[0]).Int64()
+			return bytes.Repeat(args[1], int(count)), nil
+		},
+	}))
+	return ei
+}
+
+func TestRegisteredFunctions(t *testing.T) {
+	ei := interpreterWithFunctions(t)
+
+	result, err := ei.InterpretString("dctid:TICKER")
+	require.Nil(t, err)
+	require.Equal(t, []byte("TICKER-abcdef"), result)
+
+	result, err = ei.InterpretString("u8:1|dctid:ABC|u8:2")
+	require.Nil(t, err)
+	require.Equal(t, []byte("\x01ABC-abcdef\x02"), result)
+
+	result, err = ei.InterpretString(`repeat:3:str:ab\|c`)
+	require.Nil(t, err)
+	require.Equal(t, []byte("ab|cab|cab|c"), result)
+
+	result, err = ei.InterpretString("repeat:0x02:(u8:7)|u8:0")
+	require.Nil(t, err)
+	require.Equal(t, []byte{7, 7, 0}, result)
+
+	require.Equal(t, []string{"dctid:", "repeat:"}, ei.FunctionPrefixes())
+}
+
+func TestRegisteredFunctionErrors(t *testing.T) {
+	ei := interpreterWithFunctions(t)
+
+	_, err := ei.InterpretString("u8:1|dctid:")
+	require.EqualError(t, err, "dctid: empty ticker (at offset 5)")
+
+	_, err = ei.InterpretString("repeat:2")
+	require.EqualError(t, err, "repeat expects 2 arguments (at offset 8)")
+
+	_, err = ei.InterpretString("repeat:x:str:a")
+	require.NotNil(t, err)
+
+	// unknown to the package-level parser
+	_, err = mei.ParseExpression("dctid:TICKER")
+	require.Nil(t, err)
+	other := interpreter()
+	_, err = other.InterpretString("dctid:TICKER")
+	require.NotNil(t, err)
+
+	call := func(args [][]byte) ([]byte, error) { return nil, nil }
+	require.EqualError(t,
+		ei.RegisterFunction(&mei.Function{Name: "str", Args: []mei.FunctionArgKind{mei.StringArg}, Call: call}),
+		"function str is built in")
+	require.EqualError(t,
+		ei.RegisterFunction(&mei.Function{Name: "dctid", Args: []mei.FunctionArgKind{mei.StringArg}, Call: call}),
+		"function dctid is already registered")
+	require.EqualError(t,
+		ei.RegisterFunction(&mei.Function{Name: "f", Args: []mei.FunctionArgKind{mei.ExpressionArg, mei.StringArg}, Call: call}),
+		"function f: only the last argument can be an expression")
+	require.NotNil(t, ei.RegisterFunction(&mei.Function{Name: "a:b", Args: []mei.FunctionArgKind{mei.StringArg}, Call: call}))
+}
+
+func TestRegisteredFunctionsClone(t *testing.T) {
+	ei := interpreterWithFunctions(t)
+	clone := ei.Clone()
+	require.Nil(t, clone.RegisterFunction(&mei.Function{
+		Name: "twice",
+		Args: []mei.FunctionArgKind{mei.ExpressionArg},
+		Call: func(args [][]byte) ([]byte, error) {
+			return append(args[0], args[0]...), nil
+		},
+	}))
+	requireInterpretedHex(t, clone, "twice:u8:1", "0101")
+	requireInterpretedHex(t, clone, "dctid:TICK", "5449434b2d616263646566")
+	require.Equal(t, []string{"dctid:", "repeat:"}, ei.FunctionPrefixes())
+	_, err := ei.InterpretString("twice:u8:1")
+	require.NotNil(t, err)
+
+	// the original can register the same name independently
+	require.Nil(t, ei.RegisterFunction(&mei.Function{
+		Name: "twice",
+		Args: []mei.FunctionArgKind{mei.StringArg},
+		Call: func(args [][]byte) ([]byte, error) {
+			return args[0], nil
+		},
+	}))
+	requireInterpretedHex(t, clone, "twice:u8:1", "0101")
+}
+
+func TestRegisteredFunctionRoundTrip(t *testing.T) {
+	ei := interpreterWithFunctions(t)
+	er := reconstructor()
+	er.RegisterFunction("dctid", func(value []byte, hint mer.ExprReconstructorHint) ([]string, bool) {
+		str := string(value)
+		if hint != mer.StrHint || !strings.HasSuffix(str, testTokenSuffix) {
+			return nil, false
+		}
+		return []string{strings.TrimSuffix(str, testTokenSuffix)}, true
+	})
+
+	value, err := ei.InterpretString("dctid:TICKER")
+	require.Nil(t, err)
+	reconstructed := er.Reconstruct(value, mer.StrHint)
+	require.Equal(t, "dctid:TICKER", reconstructed)
+	reinterpreted, err := ei.InterpretString(reconstructed)
+	require.Nil(t, err)
+	require.Equal(t, value, reinterpreted)
+
+	// other hints fall back to the built-in formats
+	require.Equal(t, "str:other", er.Reconstruct([]byte("other"), mer.StrHint))
+	require.Equal(t, "0x5449434b45522d616263646566 (str:TICKER-abcdef)", er.Reconstruct(value, mer.NoHint))
+}
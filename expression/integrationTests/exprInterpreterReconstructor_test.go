@@ -452,6 +452,25 @@ func TestBigFloat(t *testing.T) {
 
 }
 
+func TestBigFloatExponent(t *testing.T) {
+	ei := interpreter()
+	// the sign of the exponent is not an arithmetic operator
+	requireInterpretedHex(t, ei, "1.5e-3", "010a00000035fffffff7c49ba5e353f7d000")
+	requireInterpretedHex(t, ei, "1.5E-3", "010a00000035fffffff7c49ba5e353f7d000")
+	requireInterpretedHex(t, ei, "u8:1|1.5e-3|u8:2", "01010a00000035fffffff7c49ba5e353f7d00002")
+	requireInterpretedHex(t, ei, "bigfloat:1.5e-3", "00000012010a00000035fffffff7c49ba5e353f7d000")
+
+	plusExponent, err := ei.InterpretString("1.5e+3")
+	require.Nil(t, err)
+	thousands, err := ei.InterpretString("1500.0")
+	require.Nil(t, err)
+	require.Equal(t, thousands, plusExponent)
+
+	// still arithmetic
+	requireInterpretedHex(t, ei, "0x1e-3", "1b")
+	requireInterpretedHex(t, ei, "2-1", "01")
+}
+
 func TestBigUint(t *testing.T) {
 	ei := interpreter()
 	result, err := ei.InterpretString("biguint:0")
@@ -665,3 +684,157 @@ func TestInterpretSubTree2(t *testing.T) {
 	expected = append(expected, []byte("field2elem3b")...)
 	require.Equal(t, expected, result)
 }
+
+func TestStrConcatenation(t *testing.T) {
+	ei := interpreter()
+
+	// as before the grammar, strings end at "|", with all prefixes
+	for _, prefix := range []string{"str:", "``", "''"} {
+		result, err := ei.InterpretString(prefix + "abc|u32:5")
+		require.Nil(t, err)
+		require.Equal(t, []byte("abc\x00\x00\x00\x05"), result)
+
+		result, err = ei.InterpretString("nested:" + prefix + "abc|biguint:5")
+		require.Nil(t, err)
+		require.Equal(t, []byte("\x00\x00\x00\x03abc\x00\x00\x00\x01\x05"), result)
+
+		result, err = ei.InterpretString("u8:1|" + prefix + "a|u8:2")
+		require.Nil(t, err)
+		require.Equal(t, []byte("\x01a\x02"), result)
+
+		result, err = ei.InterpretString(prefix + "a|" + prefix + "b")
+		require.Nil(t, err)
+		require.Equal(t, []byte("ab"), result)
+	}
+
+	// backslashes that do not form escape sequences are kept
+	result, err := ei.InterpretString(`str:C:\path\file`)
+	require.Nil(t, err)
+	require.Equal(t, []byte(`C:\path\file`), result)
+
+	result, err = ei.InterpretString(`str:a\q\xzz\`)
+	require.Nil(t, err)
+	require.Equal(t, []byte(`a\q\xzz\`), result)
+}
+
+func TestStrEscapes(t *testing.T) {
+	ei := interpreter()
+	er := reconstructor()
+
+	result, err := ei.InterpretString(`str:a\|b`)
+	require.Nil(t, err)
+	require.Equal(t, []byte("a|b"), result)
+
+	result, err = ei.InterpretString("(str:a)|u8:2")
+	require.Nil(t, err)
+	require.Equal(t, []byte("a\x02"), result)
+
+	result, err = ei.InterpretString(`str:a\\b\(\)\|\n\t\x00\x7f`)
+	require.Nil(t, err)
+	require.Equal(t, []byte("a\\b()|\n\t\x00\x7f"), result)
+	require.Equal(t, `str:a\\b()\|\n\t\x00\x7f`, er.Reconstruct(result, mer.StrHint))
+
+	result, err = ei.InterpretString(er.Reconstruct(result, mer.StrHint))
+	require.Nil(t, err)
+	require.Equal(t, []byte("a\\b()|\n\t\x00\x7f"), result)
+
+	// parentheses are only special inside groups, when not balanced
+	result, err = ei.InterpretString("str:f(x)")
+	require.Nil(t, err)
+	require.Equal(t, []byte("f(x)"), result)
+
+	result, err = ei.InterpretString(`(str:f(x))|u8:1`)
+	require.Nil(t, err)
+	require.Equal(t, []byte("f(x)\x01"), result)
+
+	result, err = ei.InterpretString(`(str:x\))`)
+	require.Nil(t, err)
+	require.Equal(t, []byte("x)"), result)
+
+	reconstructed := er.Reconstruct([]byte(")("), mer.StrHint)
+	require.Equal(t, `str:\)\(`, reconstructed)
+	result, err = ei.InterpretString("(" + reconstructed + ")")
+	require.Nil(t, err)
+	require.Equal(t, []byte(")("), result)
+}
+
+// TestStrBackslashes pins how backslashes decode since escapes were introduced,
+// see "Breaking changes" in the README.
+func TestStrBackslashes(t *testing.T) {
+	ei := interpreter()
+	for _, tc := range []struct {
+		expression string
+		expected   string
+	}{
+		// used to be 615c6e62, the backslash was kept as is
+		{`str:a\nb`, "610a62"},
+		// doubling the backslash keeps the old bytes
+		{`str:a\\nb`, "615c6e62"},
+		// used to be 615c5c01, both backslashes were kept
+		{`str:a\\|u8:1`, "615c01"},
+		{`str:a\rb\tc`, "610d620963"},
+		{`str:\x41\x4`, "415c7834"},
+		// backslashes that do not start an escape are kept
+		{`str:a\b`, "615c62"},
+		{`str:a\`, "615c"},
+		{`''a\nb`, "610a62"},
+	} {
+		result, err := ei.InterpretString(tc.expression)
+		require.Nil(t, err, tc.expression)
+		require.Equal(t, tc.expected, hex.EncodeToString(result), tc.expression)
+	}
+}
+
+func TestGrouping(t *testing.T) {
+	ei := interpreter()
+
+	result, err := ei.InterpretString("keccak256:(str:a)|u32:1")
+	require.Nil(t, err)
+	expected, _ := mei.Keccak256([]byte("a"))
+	require.Equal(t, append(expected, 0x00, 0x00, 0x00, 0x01), result)
+
+	result, err = ei.InterpretString("keccak256:((str:a)|u32:1)")
+	require.Nil(t, err)
+	expected, _ = mei.Keccak256([]byte("a\x00\x00\x00\x01"))
+	require.Equal(t, expected, result)
+
+	result, err = ei.InterpretString("nested:(u8:1|u8:2)|u8:3")
+	require.Nil(t, err)
+	require.Equal(t, []byte{0x00, 0x00, 0x00, 0x02, 0x01, 0x02, 0x03}, result)
+
+	result, err = ei.InterpretString("nested:nested:u8:1")
+	require.Nil(t, err)
+	require.Equal(t, []byte{0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x01, 0x01}, result)
+
+	result, err = ei.InterpretString("(((0x01)))|()|(|0x02|)")
+	require.Nil(t, err)
+	require.Equal(t, []byte{0x01, 0x02}, result)
+}
+
+func TestExpressionErrors(t *testing.T) {
+	ei := interpreter()
+
+	_, err := ei.InterpretString("u8:1|(u8:2")
+	require.NotNil(t, err)
+	var exprErr *mei.ExpressionError
+	require.ErrorAs(t, err, &exprErr)
+	require.Equal(t, 5, exprErr.Offset)
+	require.Contains(t, err.Error(), "unclosed")
+
+	_, err = ei.InterpretString("(u8:2)x")
+	require.ErrorAs(t, err, &exprErr)
+	require.Equal(t, 6, exprErr.Offset)
+
+	_, err = ei.InterpretString("u8:1|(str:a")
+	require.ErrorAs(t, err, &exprErr)
+	require.Equal(t, 5, exprErr.Offset)
+
+	_, err = ei.InterpretString("u8:1|u8:256")
+	require.ErrorAs(t, err, &exprErr)
+	require.Equal(t, 5, exprErr.Offset)
+	require.Contains(t, err.Error(), "does not fit in 1 bytes")
+
+	_, err = ei.InterpretString("keccak256:(0x01|abc)")
+	require.ErrorAs(t, err, &exprErr)
+	require.Equal(t, 16, exprErr.Offset)
+}
@@ -0,0 +1,141 @@
+package scenjsontest
+
+import (
+	"encoding/hex"
+	"testing"
+
+	mei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+	"github.com/stretchr/testify/require"
+)
+
+// requireRoundTrip encodes a typed value, reconstructs it, and checks that the result yields the same bytes.
+func requireRoundTrip(t *testing.T, abi *mei.ABI, typeName string, value string, expected string) {
+	ei := interpreter()
+	encoded, err := ei.InterpretTyped(parseJSON(t, value), typeName, abi, false)
+	require.Nil(t, err)
+
+	er := reconstructor()
+	reconstructed := er.ReconstructTyped(encoded, typeName, abi)
+	require.Equal(t, oj.JSONString(parseJSON(t, expected)), oj.JSONString(reconstructed))
+
+	reencoded, err := ei.InterpretSubTree(reconstructed)
+	require.Nil(t, err)
+	require.Equal(t, hex.EncodeToString(encoded), hex.EncodeToString(reencoded))
+}
+
+func TestReconstructTypedLeaves(t *testing.T) {
+	requireRoundTrip(t, nil, "u32", `"5"`, `"5"`)
+	requireRoundTrip(t, nil, "u32", `"0"`, `"0"`)
+	requireRoundTrip(t, nil, "i16", `"-2"`, `"-2"`)
+	requireRoundTrip(t, nil, "i16", `"128"`, `"+128"`)
+	requireRoundTrip(t, nil, "BigUint", `"1000"`, `"1000"`)
+	requireRoundTrip(t, nil, "BigInt", `"-1000"`, `"-1000"`)
+	requireRoundTrip(t, nil, "bool", `true`, `"true"`)
+	requireRoundTrip(t, nil, "TokenIdentifier", `"str:ABC-123456"`, `"str:ABC-123456"`)
+	requireRoundTrip(t, nil, "bytes", `"0x00ff"`, `"0x00ff"`)
+	requireRoundTrip(t, nil, "Address", `"address:a"`, `"address:a"`)
+	requireRoundTrip(t, nil, "tuple<u8,i8,bytes,bool>", `["1", "-1", "str:a", false]`,
+		`["u8:1", "i8:-1", "nested:str:a", "u8:0"]`)
+}
+
+func TestReconstructTypedComposite(t *testing.T) {
+	abi := parseTestABI(t)
+	requireRoundTrip(t, abi, "Auction",
+		`{"token": "str:T", "nonce": "3", "minBid": "1", "deadline": {"Some": "2"}}`,
+		`[
+			{"token": "nested:str:T"},
+			{"nonce": "u64:3"},
+			{"minBid": "biguint:1"},
+			{"deadline": {"Some": ["u8:1", "u64:2"]}}
+		]`)
+	requireRoundTrip(t, abi, "Status", `"Pending"`, `{"Pending": "0"}`)
+	requireRoundTrip(t, abi, "Status", `"Running"`, `{"Running": "1"}`)
+	requireRoundTrip(t, abi, "Status", `{"Ended": {"winner": "address:a", "bids": "3"}}`,
+		`{"Ended": ["u8:2", [{"winner": "address:a"}, {"bids": "u32:3"}]]}`)
+	requireRoundTrip(t, abi, "List<Status>", `["Running", "Pending"]`,
+		`[{"Running": "u8:1"}, {"Pending": "u8:0"}]`)
+	requireRoundTrip(t, abi, "Option<List<u16>>", `{"Some": ["7"]}`,
+		`{"Some": ["u8:1", ["u32:1", ["u16:7"]]]}`)
+	requireRoundTrip(t, abi, "Option<u8>", `"None"`, `{"None": ""}`)
+}
+
+func TestReconstructTypedMismatch(t *testing.T) {
+	er := reconstructor()
+	// too long for a u8
+	require.Equal(t, `"0x0102"`, oj.JSONString(er.ReconstructTyped([]byte{1, 2}, "u8", nil)))
+	// not canonical: leading zero
+	require.Equal(t, `"0x0001"`, oj.JSONString(er.ReconstructTyped([]byte{0, 1}, "u32", nil)))
+	// unknown type
+	require.Equal(t, `"0x01"`, oj.JSONString(er.ReconstructTyped([]byte{1}, "Unknown", nil)))
+}
+
+func TestReconstructOutputsAndEvents(t *testing.T) {
+	abi := parseTestABI(t)
+	abi.Endpoints[0].Outputs = []*mei.ABIParam{
+		{Name: "status", Type: "Status"},
+		{Name: "bids", Type: "variadic<u32>"},
+	}
+	abi.Events = []*mei.ABIEvent{{
+		Identifier: "bid",
+		Inputs: []*mei.ABIParam{
+			{Name: "bidder", Type: "Address", Indexed: true},
+			{Name: "amount", Type: "BigUint"},
+		},
+	}}
+
+	er := reconstructor()
+	outputs := er.ReconstructOutputs([][]byte{{1}, {5}, {6}}, abi.Endpoint("bid").Outputs, abi)
+	require.Equal(t, oj.JSONString(parseJSON(t, `{"Running": "1"}`)), oj.JSONString(outputs[0]))
+	require.Equal(t, `"5"`, oj.JSONString(outputs[1]))
+	require.Equal(t, `"6"`, oj.JSONString(outputs[2]))
+
+	ei := interpreter()
+	bidder, err := ei.InterpretString("address:bidder")
+	require.Nil(t, err)
+	topics, data := er.ReconstructEvent([][]byte{[]byte("bid"), bidder}, [][]byte{{0x03, 0xe8}}, abi)
+	require.Equal(t, `["str:bid", "address:bidder"]`, compactList(topics))
+	require.Equal(t, `["1000"]`, compactList(data))
+
+	topics, _ = er.ReconstructEvent([][]byte{[]byte("other"), {1}}, nil, abi)
+	require.Equal(t, `["0x6f74686572", "0x01"]`, compactList(topics))
+}
+
+func TestReconstructStorage(t *testing.T) {
+	abi := parseTestABI(t)
+	abi.Storage = []*mei.ABIStorageMapper{
+		{Name: "auction", Keys: []*mei.ABIParam{{Name: "id", Type: "u64"}}, Type: "Auction"},
+		{Name: "lastBidder", Keys: []*mei.ABIParam{{Name: "token", Type: "TokenIdentifier"}}, Type: "Address"},
+	}
+
+	ei := interpreter()
+	key, err := ei.InterpretString("``auction|u64:7")
+	require.Nil(t, err)
+	er := reconstructor()
+	keyExpression, value := er.ReconstructStorage(key, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, abi)
+	require.Equal(t, "str:auction|u64:7", keyExpression)
+	require.IsType(t, &oj.OJsonList{}, value)
+
+	key, err = ei.InterpretString("``lastBidder|nested:str:TKN")
+	require.Nil(t, err)
+	keyExpression, _ = er.ReconstructStorage(key, nil, abi)
+	require.Equal(t, "str:lastBidder|nested:str:TKN", keyExpression)
+	reencoded, err := ei.InterpretString(keyExpression)
+	require.Nil(t, err)
+	require.Equal(t, key, reencoded)
+
+	keyExpression, value = er.ReconstructStorage([]byte("unknown"), []byte{1}, abi)
+	require.Equal(t, "str:unknown", keyExpression)
+	require.Equal(t, `"0x01"`, oj.JSONString(value))
+}
+
+func compactList(items []oj.OJsonObject) string {
+	var result string
+	for i, item := range items {
+		if i > 0 {
+			result += ", "
+		}
+		result += oj.JSONString(item)
+	}
+	return "[" + result + "]"
+}
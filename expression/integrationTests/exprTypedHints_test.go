@@ -0,0 +1,81 @@
+package scenjsontest
+
+import (
+	"encoding/hex"
+	"testing"
+
+	mer "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
+	"github.com/stretchr/testify/require"
+)
+
+func requireReconstructsTyped(t *testing.T, expression string, hint mer.ExprReconstructorHint, expected string) {
+	ei := interpreter()
+	value, err := ei.InterpretString(expression)
+	require.Nil(t, err)
+	er := reconstructor()
+	reconstructed := er.Reconstruct(value, hint)
+	require.Equal(t, expected, reconstructed)
+	requireInterpretedHex(t, ei, reconstructed, hex.EncodeToString(value))
+}
+
+func TestReconstructFixedWidth(t *testing.T) {
+	requireReconstructsTyped(t, "u8:5", mer.U8Hint, "u8:5")
+	requireReconstructsTyped(t, "u16:0", mer.U16Hint, "u16:0")
+	requireReconstructsTyped(t, "u32:70000", mer.U32Hint, "u32:70000")
+	requireReconstructsTyped(t, "u64:5", mer.U64Hint, "u64:5")
+	requireReconstructsTyped(t, "i8:-1", mer.I8Hint, "i8:-1")
+	requireReconstructsTyped(t, "i16:-300", mer.I16Hint, "i16:-300")
+	requireReconstructsTyped(t, "i32:-5", mer.I32Hint, "i32:-5")
+	requireReconstructsTyped(t, "i64:5", mer.I64Hint, "i64:5")
+
+	// the width must match
+	er := reconstructor()
+	require.Equal(t, "0x0005 (5)", er.Reconstruct([]byte{0, 5}, mer.U32Hint))
+}
+
+func TestReconstructSignedNumber(t *testing.T) {
+	requireReconstructsTyped(t, "-5", mer.SignedNumberHint, "-5")
+	requireReconstructsTyped(t, "+255", mer.SignedNumberHint, "+255")
+	requireReconstructsTyped(t, "0", mer.SignedNumberHint, "0")
+
+	// not the shortest form
+	er := reconstructor()
+	require.Equal(t, "0x0005 (5)", er.Reconstruct([]byte{0, 5}, mer.SignedNumberHint))
+}
+
+func TestReconstructNestedAndBool(t *testing.T) {
+	requireReconstructsTyped(t, "biguint:1000", mer.BigUintHint, "biguint:1000")
+	requireReconstructsTyped(t, "biguint:0", mer.BigUintHint, "biguint:0")
+	requireReconstructsTyped(t, "nested:str:abc", mer.NestedStrHint, "nested:str:abc")
+	requireReconstructsTyped(t, "nested:0x0102", mer.NestedHint, "nested:0x0102")
+	requireReconstructsTyped(t, "true", mer.BoolHint, "true")
+	requireReconstructsTyped(t, "false", mer.BoolHint, "false")
+
+	er := reconstructor()
+	require.Equal(t, "0x02 (2)", er.Reconstruct([]byte{2}, mer.BoolHint))
+	// the length prefix does not match
+	require.Equal(t, "0x0000000501 (1281)", er.Reconstruct([]byte{0, 0, 0, 5, 1}, mer.BigUintHint))
+}
+
+func TestReconstructConcat(t *testing.T) {
+	ei := interpreter()
+	er := reconstructor()
+	value, err := ei.InterpretString("u32:5|i8:-1|biguint:7|nested:(str:abc)|true")
+	require.Nil(t, err)
+	reconstructed := er.ReconstructConcat(value, mer.U32Hint, mer.I8Hint, mer.BigUintHint, mer.NestedStrHint, mer.BoolHint)
+	require.Equal(t, "u32:5|i8:-1|biguint:7|nested:str:abc|true", reconstructed)
+	requireInterpretedHex(t, ei, reconstructed, hex.EncodeToString(value))
+
+	value, err = ei.InterpretString("u16:1|str:rest")
+	require.Nil(t, err)
+	require.Equal(t, "u16:1|str:rest", er.ReconstructConcat(value, mer.U16Hint, mer.StrHint))
+	require.Equal(t, "u16:1|0x72657374", er.ReconstructConcat(value, mer.U16Hint, mer.NoHint))
+
+	// a single hint is a plain reconstruction
+	require.Equal(t, "u64:5", er.ReconstructConcat([]byte{0, 0, 0, 0, 0, 0, 0, 5}, mer.U64Hint))
+
+	// values that do not split according to the hints
+	require.Equal(t, "0x000102 (258)", er.ReconstructConcat([]byte{0, 1, 2}, mer.U32Hint, mer.U8Hint))
+	require.Equal(t, "0x000102 (258)", er.ReconstructConcat([]byte{0, 1, 2}, mer.U8Hint, mer.U8Hint))
+	require.Equal(t, "0x000102 (258)", er.ReconstructConcat([]byte{0, 1, 2}, mer.NumberHint, mer.U8Hint))
+}
@@ -0,0 +1,185 @@
+package scenjsontest
+
+import (
+	"encoding/hex"
+	"testing"
+
+	mei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+	"github.com/stretchr/testify/require"
+)
+
+const testABI = `{
+	"name": "Auction",
+	"constructor": {
+		"inputs": [{"name": "fee", "type": "BigUint"}],
+		"outputs": []
+	},
+	"endpoints": [
+		{
+			"name": "bid",
+			"inputs": [
+				{"name": "auction", "type": "Auction"},
+				{"name": "status", "type": "Status"},
+				{"name": "memo", "type": "optional<bytes>"}
+			],
+			"outputs": []
+		},
+		{
+			"name": "setPrices",
+			"inputs": [
+				{"name": "token", "type": "TokenIdentifier"},
+				{"name": "prices", "type": "variadic<multi<u32,BigUint>>"}
+			],
+			"outputs": []
+		}
+	],
+	"types": {
+		"Auction": {
+			"type": "struct",
+			"fields": [
+				{"name": "token", "type": "TokenIdentifier"},
+				{"name": "nonce", "type": "u64"},
+				{"name": "minBid", "type": "BigUint"},
+				{"name": "deadline", "type": "Option<u64>"}
+			]
+		},
+		"Status": {
+			"type": "enum",
+			"variants": [
+				{"name": "Pending", "discriminant": 0},
+				{"name": "Running", "discriminant": 1},
+				{"name": "Ended", "discriminant": 2, "fields": [{"name": "winner", "type": "Address"}, {"name": "bids", "type": "u32"}]}
+			]
+		}
+	}
+}`
+
+func parseTestABI(t *testing.T) *mei.ABI {
+	abi, err := mei.ParseABI([]byte(testABI))
+	require.Nil(t, err)
+	return abi
+}
+
+func parseJSON(t *testing.T, str string) oj.OJsonObject {
+	obj, err := oj.ParseOrderedJSON([]byte(str))
+	require.Nil(t, err)
+	return obj
+}
+
+func requireTyped(t *testing.T, abi *mei.ABI, typeName string, nested bool, value string, expectedHex string) {
+	ei := interpreter()
+	result, err := ei.InterpretTyped(parseJSON(t, value), typeName, abi, nested)
+	require.Nil(t, err)
+	require.Equal(t, expectedHex, hex.EncodeToString(result))
+}
+
+func TestTypedNumbers(t *testing.T) {
+	requireTyped(t, nil, "u32", false, `"5"`, "05")
+	requireTyped(t, nil, "u32", true, `"5"`, "00000005")
+	requireTyped(t, nil, "u16", false, `"0"`, "")
+	requireTyped(t, nil, "i16", false, `"-1"`, "ff")
+	requireTyped(t, nil, "i16", true, `"-1"`, "ffff")
+	requireTyped(t, nil, "BigUint", false, `"256"`, "0100")
+	requireTyped(t, nil, "BigUint", true, `"256"`, "000000020100")
+	requireTyped(t, nil, "BigInt", true, `"-1"`, "00000001ff")
+	requireTyped(t, nil, "bool", true, `false`, "00")
+	requireTyped(t, nil, "bool", false, `"true"`, "01")
+
+	ei := interpreter()
+	_, err := ei.InterpretTyped(parseJSON(t, `"256"`), "u8", nil, false)
+	require.NotNil(t, err)
+	_, err = ei.InterpretTyped(parseJSON(t, `"-1"`), "u64", nil, false)
+	require.NotNil(t, err)
+}
+
+func TestTypedBuffers(t *testing.T) {
+	requireTyped(t, nil, "TokenIdentifier", false, `"str:ABC-123456"`, "4142432d313233343536")
+	requireTyped(t, nil, "ManagedBuffer", true, `"str:ab"`, "000000026162")
+	requireTyped(t, nil, "Address", true, `"address:a"`, hex.EncodeToString([]byte("a_______________________________")))
+
+	ei := interpreter()
+	_, err := ei.InterpretTyped(parseJSON(t, `"str:short"`), "Address", nil, false)
+	require.NotNil(t, err)
+}
+
+func TestTypedCollections(t *testing.T) {
+	requireTyped(t, nil, "List<u16>", false, `["1", "2"]`, "00010002")
+	requireTyped(t, nil, "ManagedVec<u16>", true, `["1", "2"]`, "0000000200010002")
+	requireTyped(t, nil, "List<u16>", false, `"0x0001"`, "0001")
+	requireTyped(t, nil, "tuple<u8,bytes>", false, `["1", "str:a"]`, "010000000161")
+	requireTyped(t, nil, "Option<u32>", false, `"None"`, "")
+	requireTyped(t, nil, "Option<u32>", true, `"None"`, "00")
+	requireTyped(t, nil, "Option<u32>", false, `{"Some": "7"}`, "0100000007")
+	requireTyped(t, nil, "Option<List<u8>>", true, `{"Some": ["1"]}`, "010000000101")
+}
+
+func TestTypedStruct(t *testing.T) {
+	abi := parseTestABI(t)
+	// fields are encoded in the declared order, whatever the key order
+	requireTyped(t, abi, "Auction", false,
+		`{"minBid": "1", "token": "str:T", "deadline": {"Some": "2"}, "nonce": "3"}`,
+		"00000001"+"54"+"0000000000000003"+"0000000101"+"01"+"0000000000000002")
+	requireTyped(t, abi, "Auction", false,
+		`["str:T", "3", "1", "None"]`,
+		"00000001"+"54"+"0000000000000003"+"0000000101"+"00")
+
+	ei := interpreter()
+	_, err := ei.InterpretTyped(parseJSON(t, `{"token": "str:T"}`), "Auction", abi, false)
+	require.EqualError(t, err, "missing field of Auction: nonce")
+	_, err = ei.InterpretTyped(parseJSON(t, `{"token": "str:T", "price": "1"}`), "Auction", abi, false)
+	require.EqualError(t, err, "unknown field of Auction: price")
+}
+
+func TestTypedEnum(t *testing.T) {
+	abi := parseTestABI(t)
+	requireTyped(t, abi, "Status", false, `"Pending"`, "")
+	requireTyped(t, abi, "Status", true, `"Pending"`, "00")
+	requireTyped(t, abi, "Status", false, `"Running"`, "01")
+	requireTyped(t, abi, "Status", false,
+		`{"Ended": {"winner": "address:a", "bids": "3"}}`,
+		"02"+hex.EncodeToString([]byte("a_______________________________"))+"00000003")
+	requireTyped(t, abi, "List<Status>", false, `["Running", "Pending"]`, "0100")
+
+	ei := interpreter()
+	_, err := ei.InterpretTyped(parseJSON(t, `"Cancelled"`), "Status", abi, false)
+	require.EqualError(t, err, "unknown variant of Status: Cancelled")
+	_, err = ei.InterpretTyped(parseJSON(t, `"Ended"`), "Status", abi, false)
+	require.NotNil(t, err)
+}
+
+func TestTypedArguments(t *testing.T) {
+	abi := parseTestABI(t)
+	ei := interpreter()
+
+	args := parseJSON(t, `["str:TKN", "1", "1000", "2", "2000"]`).(*oj.OJsonList).AsList()
+	result, err := ei.InterpretArguments(args, abi.Endpoint("setPrices").Inputs, abi)
+	require.Nil(t, err)
+	require.Equal(t, [][]byte{[]byte("TKN"), {1}, {0x03, 0xe8}, {2}, {0x07, 0xd0}}, result)
+
+	// the optional memo is left out
+	args = parseJSON(t, `[["str:T", "3", "1", "None"], "Running"]`).(*oj.OJsonList).AsList()
+	result, err = ei.InterpretArguments(args, abi.Endpoint("bid").Inputs, abi)
+	require.Nil(t, err)
+	require.Len(t, result, 2)
+	require.Equal(t, []byte{1}, result[1])
+
+	args = parseJSON(t, `[["str:T", "3", "1", "None"]]`).(*oj.OJsonList).AsList()
+	_, err = ei.InterpretArguments(args, abi.Endpoint("bid").Inputs, abi)
+	require.EqualError(t, err, "missing argument status of type Status")
+
+	args = parseJSON(t, `[["str:T", "3", "1", "None"], "Running", "str:memo", "1"]`).(*oj.OJsonList).AsList()
+	_, err = ei.InterpretArguments(args, abi.Endpoint("bid").Inputs, abi)
+	require.EqualError(t, err, "too many arguments: expected 3, got 4")
+}
+
+func TestParseTypeName(t *testing.T) {
+	tn, err := mei.ParseTypeName("variadic<multi<Option<List<u32>>, tuple<u8,BigUint>>>")
+	require.Nil(t, err)
+	require.Equal(t, "variadic<multi<Option<List<u32>>,tuple<u8,BigUint>>>", tn.String())
+
+	_, err = mei.ParseTypeName("List<u32")
+	require.NotNil(t, err)
+	_, err = mei.ParseTypeName("List<u32>>")
+	require.NotNil(t, err)
+}
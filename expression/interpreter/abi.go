@@ -0,0 +1,171 @@
+package scenexpressioninterpreter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ABI describes the endpoints and types of a smart contract, as found in the .abi.json files produced by the contract build.
+type ABI struct {
+	Name        string              `json:"name"`
+	Constructor *ABIEndpoint        `json:"constructor"`
+	Upgrade     *ABIEndpoint        `json:"upgradeConstructor"`
+	Endpoints   []*ABIEndpoint      `json:"endpoints"`
+	Events      []*ABIEvent         `json:"events"`
+	Types       map[string]*ABIType `json:"types"`
+	Storage     []*ABIStorageMapper `json:"storage"`
+}
+
+// ABIEndpoint is a contract endpoint, with its inputs and outputs.
+type ABIEndpoint struct {
+	Name    string      `json:"name"`
+	Inputs  []*ABIParam `json:"inputs"`
+	Outputs []*ABIParam `json:"outputs"`
+}
+
+// ABIEvent is an event that the contract can log.
+type ABIEvent struct {
+	Identifier string      `json:"identifier"`
+	Inputs     []*ABIParam `json:"inputs"`
+}
+
+// ABIStorageMapper describes the storage entries of a mapper, to decode them.
+// The contract build does not produce it, it can be added to the ABI by hand.
+type ABIStorageMapper struct {
+	Name string      `json:"name"`
+	Keys []*ABIParam `json:"keys"`
+	Type string      `json:"type"`
+}
+
+// ABIParam is an endpoint input or output, a struct field, or an event argument.
+type ABIParam struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Indexed bool   `json:"indexed,omitempty"`
+}
+
+// ABIType is a custom type declared by the contract, either a struct or an enum.
+type ABIType struct {
+	Type     string        `json:"type"`
+	Fields   []*ABIParam   `json:"fields"`
+	Variants []*ABIVariant `json:"variants"`
+}
+
+// ABIVariant is an enum variant. Variants without fields are encoded as their discriminant only.
+type ABIVariant struct {
+	Name         string      `json:"name"`
+	Discriminant int         `json:"discriminant"`
+	Fields       []*ABIParam `json:"fields"`
+}
+
+// ParseABI reads an .abi.json file.
+func ParseABI(data []byte) (*ABI, error) {
+	abi := &ABI{}
+	if err := json.Unmarshal(data, abi); err != nil {
+		return nil, fmt.Errorf("invalid ABI: %w", err)
+	}
+	for name, abiType := range abi.Types {
+		if abiType.Type != "struct" && abiType.Type != "enum" {
+			return nil, fmt.Errorf("invalid ABI: type %s is neither struct nor enum", name)
+		}
+	}
+	return abi, nil
+}
+
+// LoadABI reads an .abi.json file, relative to the current context of the file resolver.
+func (ei *ExprInterpreter) LoadABI(path string) (*ABI, error) {
+	if ei.FileResolver == nil {
+		return nil, errors.New("parser FileResolver not provided")
+	}
+	data, err := ei.FileResolver.ResolveFileValue(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseABI(data)
+}
+
+// Endpoint yields the endpoint with the given name, or nil if there is none.
+func (abi *ABI) Endpoint(name string) *ABIEndpoint {
+	for _, endpoint := range abi.Endpoints {
+		if endpoint.Name == name {
+			return endpoint
+		}
+	}
+	return nil
+}
+
+// Event yields the event with the given identifier, or nil if there is none.
+func (abi *ABI) Event(identifier string) *ABIEvent {
+	for _, event := range abi.Events {
+		if event.Identifier == identifier {
+			return event
+		}
+	}
+	return nil
+}
+
+// TypeName is a parsed type name, e.g. "Option<List<u32>>" or "tuple<u8,BigUint>".
+type TypeName struct {
+	Name string
+	Args []*TypeName
+}
+
+// ParseTypeName splits a type name into its generic arguments.
+func ParseTypeName(typeName string) (*TypeName, error) {
+	result, rest, err := parseTypeName(strings.TrimSpace(typeName))
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("invalid type name %s: unexpected %s", typeName, rest)
+	}
+	return result, nil
+}
+
+func parseTypeName(str string) (*TypeName, string, error) {
+	end := strings.IndexAny(str, "<,>")
+	if end < 0 {
+		end = len(str)
+	}
+	name := strings.TrimSpace(str[:end])
+	if len(name) == 0 {
+		return nil, "", fmt.Errorf("missing type name in %s", str)
+	}
+	result := &TypeName{Name: name}
+	rest := str[end:]
+	if !strings.HasPrefix(rest, "<") {
+		return result, rest, nil
+	}
+
+	rest = rest[1:]
+	for {
+		arg, argRest, err := parseTypeName(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, "", err
+		}
+		result.Args = append(result.Args, arg)
+		rest = strings.TrimSpace(argRest)
+		switch {
+		case strings.HasPrefix(rest, ","):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, ">"):
+			return result, strings.TrimSpace(rest[1:]), nil
+		default:
+			return nil, "", fmt.Errorf("unclosed type arguments of %s", name)
+		}
+	}
+}
+
+// String yields the type name in the ABI format.
+func (tn *TypeName) String() string {
+	if len(tn.Args) == 0 {
+		return tn.Name
+	}
+	var args []string
+	for _, arg := range tn.Args {
+		args = append(args, arg.String())
+	}
+	return tn.Name + "<" + strings.Join(args, ",") + ">"
+}
@@ -0,0 +1,501 @@
+package scenexpressioninterpreter
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	twos "github.com/bhagyaraj1208117/andes-components-big-int/twos-complement"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+)
+
+const addressLength = 32
+
+var intTypeWidths = map[string]int{
+	"u8": 1, "u16": 2, "u32": 4, "u64": 8, "usize": 4,
+	"i8": 1, "i16": 2, "i32": 4, "i64": 8, "isize": 4,
+}
+
+var bytesTypes = []string{"ManagedBuffer", "BoxedBytes", "bytes", "String", "utf-8 string", "TokenIdentifier"}
+
+var addressTypes = []string{"Address", "ManagedAddress", "H256"}
+
+var listTypes = []string{"List", "Vec", "ManagedVec"}
+
+var plainNumberRegexp = regexp.MustCompile(`^[+-]?(0[xX][0-9a-fA-F_,]*|0[bB][01_,]*|[0-9][0-9_,]*)$`)
+
+// InterpretTyped encodes a JSON value according to an ABI type.
+// Endpoint arguments use the top-level encoding, values inside other values the nested encoding.
+//
+// The values are written as:
+// - numbers, bools, addresses, token identifiers and buffers: value expressions, e.g. "5", "str:TOKEN-123456";
+// - structs: maps with all fields, encoded in the order of the declaration, whatever the key order;
+// - enums: the variant name, or a map with the variant name as only key and the fields as value, in a map or list;
+// - Option<T>: "None", or {"Some": value};
+// - List<T>, Vec<T>, ManagedVec<T>, tuple<...>: lists.
+// Composite values can also be given as a single expression, which is taken as already encoded.
+func (ei *ExprInterpreter) InterpretTyped(obj oj.OJsonObject, typeName string, abi *ABI, nested bool) ([]byte, error) {
+	tn, err := ParseTypeName(typeName)
+	if err != nil {
+		return []byte{}, err
+	}
+	enc := &typedEncoder{ei: ei, abi: abi}
+	return enc.encode(obj, tn, nested)
+}
+
+// InterpretArguments encodes endpoint arguments according to the endpoint inputs.
+// Inputs of type optional<T> can be left out at the end, variadic<T> takes all remaining arguments,
+// and multi<A,B,...> takes one argument for each of its types.
+func (ei *ExprInterpreter) InterpretArguments(args []oj.OJsonObject, inputs []*ABIParam, abi *ABI) ([][]byte, error) {
+	enc := &typedEncoder{ei: ei, abi: abi}
+	var result [][]byte
+	argIndex := 0
+	encodeNext := func(tn *TypeName, name string) error {
+		value, err := enc.encode(args[argIndex], tn, false)
+		if err != nil {
+			return fmt.Errorf("invalid argument %s: %w", name, err)
+		}
+		result = append(result, value)
+		argIndex++
+		return nil
+	}
+
+	for inputIndex, input := range inputs {
+		tn, err := ParseTypeName(input.Type)
+		if err != nil {
+			return nil, err
+		}
+		switch tn.Name {
+		case "optional":
+			if err := checkTypeArgs(tn, 1); err != nil {
+				return nil, err
+			}
+			for _, itemType := range tn.Args[0].MultiTypes() {
+				if argIndex >= len(args) {
+					break
+				}
+				if err := encodeNext(itemType, input.Name); err != nil {
+					return nil, err
+				}
+			}
+		case "variadic":
+			if err := checkTypeArgs(tn, 1); err != nil {
+				return nil, err
+			}
+			if inputIndex != len(inputs)-1 {
+				return nil, fmt.Errorf("variadic input %s must be the last one", input.Name)
+			}
+			itemTypes := tn.Args[0].MultiTypes()
+			for i := 0; argIndex < len(args); i++ {
+				if err := encodeNext(itemTypes[i%len(itemTypes)], input.Name); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			for _, itemType := range tn.MultiTypes() {
+				if argIndex >= len(args) {
+					return nil, fmt.Errorf("missing argument %s of type %s", input.Name, input.Type)
+				}
+				if err := encodeNext(itemType, input.Name); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if argIndex < len(args) {
+		return nil, fmt.Errorf("too many arguments: expected %d, got %d", argIndex, len(args))
+	}
+	return result, nil
+}
+
+// MultiTypes yields the types of multi<A,B,...>, which are passed as separate arguments, or the type itself otherwise.
+func (tn *TypeName) MultiTypes() []*TypeName {
+	if tn.Name != "multi" {
+		return []*TypeName{tn}
+	}
+	var result []*TypeName
+	for _, arg := range tn.Args {
+		result = append(result, arg.MultiTypes()...)
+	}
+	return result
+}
+
+// IntTypeWidth yields the width of fixed width integer types, e.g. 4 for u32, and whether they are signed.
+func IntTypeWidth(typeName string) (width int, signed bool, isInt bool) {
+	width, isInt = intTypeWidths[typeName]
+	return width, isInt && typeName[0] == 'i', isInt
+}
+
+// IsBytesType checks if the type is encoded as a byte array, e.g. ManagedBuffer or TokenIdentifier.
+func IsBytesType(typeName string) bool {
+	return containsString(bytesTypes, typeName)
+}
+
+// IsAddressType checks if the type is a 32 byte address.
+func IsAddressType(typeName string) bool {
+	return containsString(addressTypes, typeName)
+}
+
+// IsListType checks if the type is a list, e.g. List<T> or ManagedVec<T>.
+func IsListType(typeName string) bool {
+	return containsString(listTypes, typeName)
+}
+
+func checkTypeArgs(tn *TypeName, count int) error {
+	if len(tn.Args) != count {
+		return fmt.Errorf("type %s expects %d type arguments", tn, count)
+	}
+	return nil
+}
+
+type typedEncoder struct {
+	ei  *ExprInterpreter
+	abi *ABI
+}
+
+func (enc *typedEncoder) encode(obj oj.OJsonObject, tn *TypeName, nested bool) ([]byte, error) {
+	if width, signed, isInt := IntTypeWidth(tn.Name); isInt {
+		return enc.encodeInt(obj, width, signed, nested)
+	}
+
+	switch {
+	case tn.Name == "bool":
+		return enc.encodeBool(obj, nested)
+	case tn.Name == "BigUint":
+		n, err := enc.interpretNumber(obj, false)
+		if err != nil {
+			return []byte{}, err
+		}
+		if n.Sign() < 0 {
+			return []byte{}, fmt.Errorf("negative value not allowed for BigUint: %s", n)
+		}
+		return lengthPrefixedIfNested(n.Bytes(), nested), nil
+	case tn.Name == "BigInt":
+		n, err := enc.interpretNumber(obj, true)
+		if err != nil {
+			return []byte{}, err
+		}
+		return lengthPrefixedIfNested(twos.ToBytes(n), nested), nil
+	case IsBytesType(tn.Name):
+		value, err := enc.interpretExpression(obj)
+		if err != nil {
+			return []byte{}, err
+		}
+		return lengthPrefixedIfNested(value, nested), nil
+	case IsAddressType(tn.Name):
+		value, err := enc.interpretExpression(obj)
+		if err != nil {
+			return []byte{}, err
+		}
+		if len(value) != addressLength {
+			return []byte{}, fmt.Errorf("%s must be %d bytes long, got %d", tn.Name, addressLength, len(value))
+		}
+		return value, nil
+	case tn.Name == "Option":
+		if err := checkTypeArgs(tn, 1); err != nil {
+			return []byte{}, err
+		}
+		return enc.encodeOption(obj, tn.Args[0], nested)
+	}
+
+	if str, isStr := obj.(*oj.OJsonString); isStr && !enc.isEnum(tn.Name) {
+		// composite values given as a single expression are already encoded
+		return enc.ei.InterpretString(str.Value)
+	}
+
+	switch {
+	case IsListType(tn.Name):
+		if err := checkTypeArgs(tn, 1); err != nil {
+			return []byte{}, err
+		}
+		return enc.encodeList(obj, tn.Args[0], nested)
+	case tn.Name == "tuple":
+		return enc.encodeTuple(obj, tn.Args)
+	}
+
+	var customType *ABIType
+	if enc.abi != nil {
+		customType = enc.abi.Types[tn.Name]
+	}
+	if customType == nil {
+		return []byte{}, fmt.Errorf("unknown type: %s", tn.Name)
+	}
+	if customType.Type == "struct" {
+		return enc.encodeFields(obj, customType.Fields, tn.Name)
+	}
+	return enc.encodeEnum(obj, customType, tn.Name, nested)
+}
+
+func (enc *typedEncoder) isEnum(typeName string) bool {
+	if enc.abi == nil {
+		return false
+	}
+	customType, found := enc.abi.Types[typeName]
+	return found && customType.Type == "enum"
+}
+
+func lengthPrefixedIfNested(value []byte, nested bool) []byte {
+	if nested {
+		return nestedEncode(value)
+	}
+	return value
+}
+
+func (enc *typedEncoder) interpretExpression(obj oj.OJsonObject) ([]byte, error) {
+	str, isStr := obj.(*oj.OJsonString)
+	if !isStr {
+		return []byte{}, errors.New("value expression string expected")
+	}
+	return enc.ei.InterpretString(str.Value)
+}
+
+// interpretNumber accepts plain numbers, as well as any expression, whose bytes are then taken as a number.
+func (enc *typedEncoder) interpretNumber(obj oj.OJsonObject, signed bool) (*big.Int, error) {
+	str, isStr := obj.(*oj.OJsonString)
+	if !isStr {
+		return nil, errors.New("number expected")
+	}
+	if plainNumberRegexp.MatchString(str.Value) {
+		cleaned := strings.NewReplacer("_", "", ",", "").Replace(str.Value)
+		if strings.HasSuffix(cleaned, "x") || strings.HasSuffix(cleaned, "X") {
+			// empty hex
+			return big.NewInt(0), nil
+		}
+		n, ok := big.NewInt(0).SetString(cleaned, 0)
+		if !ok {
+			return nil, fmt.Errorf("could not parse number: %s", str.Value)
+		}
+		return n, nil
+	}
+
+	value, err := enc.ei.InterpretString(str.Value)
+	if err != nil {
+		return nil, err
+	}
+	if signed {
+		return twos.FromBytes(value), nil
+	}
+	return big.NewInt(0).SetBytes(value), nil
+}
+
+func (enc *typedEncoder) encodeInt(obj oj.OJsonObject, width int, signed bool, nested bool) ([]byte, error) {
+	n, err := enc.interpretNumber(obj, signed)
+	if err != nil {
+		return []byte{}, err
+	}
+	if !signed {
+		if n.Sign() < 0 {
+			return []byte{}, fmt.Errorf("negative value not allowed for unsigned type: %s", n)
+		}
+		if n.BitLen() > width*8 {
+			return []byte{}, fmt.Errorf("value %s does not fit in %d bytes", n, width)
+		}
+		if nested {
+			return twos.CopyAlignRight(n.Bytes(), width), nil
+		}
+		return n.Bytes(), nil
+	}
+
+	fixedWidth, err := twos.ToBytesOfLength(n, width)
+	if err != nil {
+		return []byte{}, err
+	}
+	if nested {
+		return fixedWidth, nil
+	}
+	return twos.ToBytes(n), nil
+}
+
+func (enc *typedEncoder) encodeBool(obj oj.OJsonObject, nested bool) ([]byte, error) {
+	var value bool
+	switch b := obj.(type) {
+	case *oj.OJsonBool:
+		value = b.Value
+	case *oj.OJsonString:
+		bytes, err := enc.ei.InterpretString(b.Value)
+		if err != nil {
+			return []byte{}, err
+		}
+		switch {
+		case len(bytes) == 0 || (len(bytes) == 1 && bytes[0] == 0):
+			value = false
+		case len(bytes) == 1 && bytes[0] == 1:
+			value = true
+		default:
+			return []byte{}, fmt.Errorf("invalid bool: %s", b.Value)
+		}
+	default:
+		return []byte{}, errors.New("bool expected")
+	}
+
+	switch {
+	case value:
+		return []byte{1}, nil
+	case nested:
+		return []byte{0}, nil
+	default:
+		return []byte{}, nil
+	}
+}
+
+func (enc *typedEncoder) encodeOption(obj oj.OJsonObject, itemType *TypeName, nested bool) ([]byte, error) {
+	variant, payload, err := variantOf(obj)
+	if err != nil {
+		return []byte{}, err
+	}
+	switch {
+	case variant == "None" && payload == nil:
+		if nested {
+			return []byte{0}, nil
+		}
+		return []byte{}, nil
+	case variant == "Some" && payload != nil:
+		value, err := enc.encode(payload, itemType, true)
+		if err != nil {
+			return []byte{}, fmt.Errorf("invalid Some value: %w", err)
+		}
+		return append([]byte{1}, value...), nil
+	default:
+		return []byte{}, errors.New(`option must be "None" or {"Some": value}`)
+	}
+}
+
+// variantOf splits enum values, "Variant" or {"Variant": payload}. The payload is nil for the first form.
+func variantOf(obj oj.OJsonObject) (string, oj.OJsonObject, error) {
+	switch value := obj.(type) {
+	case *oj.OJsonString:
+		return value.Value, nil, nil
+	case *oj.OJsonMap:
+		if value.Size() != 1 {
+			return "", nil, errors.New("enum value must have exactly one key, the variant name")
+		}
+		return value.OrderedKV[0].Key, value.OrderedKV[0].Value, nil
+	default:
+		return "", nil, errors.New("enum value must be a variant name, or a map with the variant name as key")
+	}
+}
+
+func (enc *typedEncoder) encodeList(obj oj.OJsonObject, itemType *TypeName, nested bool) ([]byte, error) {
+	list, isList := obj.(*oj.OJsonList)
+	if !isList {
+		return []byte{}, errors.New("list expected")
+	}
+	var result []byte
+	if nested {
+		result = twos.CopyAlignRight(big.NewInt(int64(len(list.AsList()))).Bytes(), 4)
+	}
+	for i, item := range list.AsList() {
+		value, err := enc.encode(item, itemType, true)
+		if err != nil {
+			return []byte{}, fmt.Errorf("invalid list item %d: %w", i, err)
+		}
+		result = append(result, value...)
+	}
+	return result, nil
+}
+
+func (enc *typedEncoder) encodeTuple(obj oj.OJsonObject, itemTypes []*TypeName) ([]byte, error) {
+	list, isList := obj.(*oj.OJsonList)
+	if !isList {
+		return []byte{}, errors.New("list expected for tuple")
+	}
+	if len(list.AsList()) != len(itemTypes) {
+		return []byte{}, fmt.Errorf("tuple expects %d items, got %d", len(itemTypes), len(list.AsList()))
+	}
+	result := make([]byte, 0)
+	for i, item := range list.AsList() {
+		value, err := enc.encode(item, itemTypes[i], true)
+		if err != nil {
+			return []byte{}, fmt.Errorf("invalid tuple item %d: %w", i, err)
+		}
+		result = append(result, value...)
+	}
+	return result, nil
+}
+
+// encodeFields concatenates the nested encodings of struct or enum variant fields, in declaration order.
+// Fields are given in a map by name, or in a list by position.
+func (enc *typedEncoder) encodeFields(obj oj.OJsonObject, fields []*ABIParam, typeName string) ([]byte, error) {
+	values := make([]oj.OJsonObject, len(fields))
+	switch fieldValues := obj.(type) {
+	case *oj.OJsonMap:
+		for _, kvp := range fieldValues.OrderedKV {
+			index := fieldIndex(fields, kvp.Key)
+			if index < 0 {
+				return []byte{}, fmt.Errorf("unknown field of %s: %s", typeName, kvp.Key)
+			}
+			values[index] = kvp.Value
+		}
+	case *oj.OJsonList:
+		if len(fieldValues.AsList()) != len(fields) {
+			return []byte{}, fmt.Errorf("%s expects %d fields, got %d", typeName, len(fields), len(fieldValues.AsList()))
+		}
+		copy(values, fieldValues.AsList())
+	default:
+		return []byte{}, fmt.Errorf("map or list of fields expected for %s", typeName)
+	}
+
+	result := make([]byte, 0)
+	for i, field := range fields {
+		if values[i] == nil {
+			return []byte{}, fmt.Errorf("missing field of %s: %s", typeName, field.Name)
+		}
+		fieldType, err := ParseTypeName(field.Type)
+		if err != nil {
+			return []byte{}, err
+		}
+		value, err := enc.encode(values[i], fieldType, true)
+		if err != nil {
+			return []byte{}, fmt.Errorf("invalid field %s.%s: %w", typeName, field.Name, err)
+		}
+		result = append(result, value...)
+	}
+	return result, nil
+}
+
+func fieldIndex(fields []*ABIParam, name string) int {
+	for i, field := range fields {
+		if field.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (enc *typedEncoder) encodeEnum(obj oj.OJsonObject, enumType *ABIType, typeName string, nested bool) ([]byte, error) {
+	variantName, payload, err := variantOf(obj)
+	if err != nil {
+		return []byte{}, err
+	}
+	var variant *ABIVariant
+	for _, v := range enumType.Variants {
+		if v.Name == variantName {
+			variant = v
+		}
+	}
+	if variant == nil {
+		return []byte{}, fmt.Errorf("unknown variant of %s: %s", typeName, variantName)
+	}
+
+	if len(variant.Fields) == 0 {
+		if payload != nil {
+			return []byte{}, fmt.Errorf("variant %s::%s has no fields", typeName, variantName)
+		}
+		if nested {
+			return []byte{byte(variant.Discriminant)}, nil
+		}
+		return big.NewInt(int64(variant.Discriminant)).Bytes(), nil
+	}
+
+	if payload == nil {
+		return []byte{}, fmt.Errorf("missing fields of variant %s::%s", typeName, variantName)
+	}
+	fields, err := enc.encodeFields(payload, variant.Fields, typeName+"::"+variantName)
+	if err != nil {
+		return []byte{}, err
+	}
+	return append([]byte{byte(variant.Discriminant)}, fields...), nil
+}
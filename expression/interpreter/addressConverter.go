@@ -0,0 +1,82 @@
+package scenexpressioninterpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bhagyaraj1208117/andes-core-go/core"
+	pc "github.com/bhagyaraj1208117/andes-core-go/core/pubkeyConverter"
+)
+
+// DefaultAddressLength is the length of addresses, in bytes, when not configured otherwise.
+const DefaultAddressLength = 32
+
+// AddressConverter converts addresses to and from a human-readable form, written after "bech32:".
+// It is satisfied by the pubkey converters of andes-core-go.
+type AddressConverter interface {
+	Len() int
+	Decode(humanReadable string) ([]byte, error)
+	Encode(pkBytes []byte) (string, error)
+}
+
+// Bech32Converter is the AddressConverter for bech32 addresses of a given human-readable part (HRP) and length.
+type Bech32Converter struct {
+	hrp       string
+	converter AddressConverter
+}
+
+var _ AddressConverter = (*Bech32Converter)(nil)
+
+// NewBech32Converter creates a bech32 converter for addresses such as "<hrp>1...", of addressLen bytes.
+func NewBech32Converter(hrp string, addressLen int) (*Bech32Converter, error) {
+	converter, err := pc.NewBech32PubkeyConverter(addressLen, hrp)
+	if err != nil {
+		return nil, err
+	}
+	return &Bech32Converter{
+		hrp:       hrp,
+		converter: converter,
+	}, nil
+}
+
+// HRP yields the human-readable part of the addresses.
+func (bc *Bech32Converter) HRP() string {
+	return bc.hrp
+}
+
+// Len yields the length of the addresses, in bytes.
+func (bc *Bech32Converter) Len() int {
+	return bc.converter.Len()
+}
+
+// Decode yields the bytes of a bech32 address, which must have the configured HRP and length.
+func (bc *Bech32Converter) Decode(humanReadable string) ([]byte, error) {
+	separatorIndex := strings.LastIndex(humanReadable, "1")
+	if separatorIndex < 0 {
+		return []byte{}, fmt.Errorf("bech32 address has no separator: %s", humanReadable)
+	}
+	hrp := strings.ToLower(humanReadable[:separatorIndex])
+	if hrp != bc.hrp {
+		return []byte{}, fmt.Errorf("bech32 address has HRP %s, expected %s: %s", hrp, bc.hrp, humanReadable)
+	}
+	return bc.converter.Decode(humanReadable)
+}
+
+// Encode yields the bech32 form of an address of the configured length.
+func (bc *Bech32Converter) Encode(pkBytes []byte) (string, error) {
+	return bc.converter.Encode(pkBytes)
+}
+
+// DefaultAddressConverter yields the bech32 converter for the default HRP and address length.
+func DefaultAddressConverter() AddressConverter {
+	converter, _ := NewBech32Converter(core.DefaultAddressPrefix, DefaultAddressLength)
+	return converter
+}
+
+// GetAddressConverter yields the AddressConverter, the default one if none was provided.
+func (ei *ExprInterpreter) GetAddressConverter() AddressConverter {
+	if ei.AddressConverter == nil {
+		return DefaultAddressConverter()
+	}
+	return ei.AddressConverter
+}
@@ -0,0 +1,331 @@
+package scenexpressioninterpreter
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Denomination is a unit of an amount, written "name:amount", e.g. "moax:1.5".
+type Denomination struct {
+	Name     string
+	Decimals int
+}
+
+// Prefix yields the prefix of denominated amounts, e.g. "moax:".
+func (d *Denomination) Prefix() string {
+	return d.Name + ":"
+}
+
+// Denominations yields the units that amounts can be written in.
+func Denominations() []*Denomination {
+	return []*Denomination{
+		{Name: "moax", Decimals: 18},
+	}
+}
+
+func denominationPrefixes() []string {
+	var prefixes []string
+	for _, denomination := range Denominations() {
+		prefixes = append(prefixes, denomination.Prefix())
+	}
+	return prefixes
+}
+
+const maxExponent = 4096
+
+// floatRegexp matches decimal floats, whose exponent sign is not an operator, e.g. "1.5e-3".
+var floatRegexp = regexp.MustCompile(`^[-+]?[0-9_,]*\.[0-9_,]*(?:[eE][-+]?[0-9]+)?$`)
+
+// isArithmetic checks if a number is written as a computation, rather than a single number.
+// A leading sign alone does not make a computation, nor does the sign of the exponent of a float.
+func isArithmetic(str string) bool {
+	if floatRegexp.MatchString(str) {
+		return false
+	}
+	if len(str) > 0 && (str[0] == '-' || str[0] == '+') {
+		str = str[1:]
+	}
+	if strings.ContainsAny(str, "+-*/%() ") {
+		return true
+	}
+	for _, prefix := range denominationPrefixes() {
+		if strings.Contains(str, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// EvaluateArithmetic computes an arithmetic expression over integers of arbitrary size.
+//
+// The grammar is, by increasing precedence:
+//
+//	sum     := product (("+" | "-") product)*
+//	product := unary (("*" | "/" | "%") unary)*
+//	unary   := ("+" | "-") unary | power
+//	power   := atom ("**" unary)?
+//	atom    := number | denomination ":" decimal | ("min" | "max") "(" sum (", " sum)* ")" | "(" sum ")"
+//
+// Numbers are decimal, hex (0x) or binary (0b), their digits can be grouped with "_" or ",".
+// In the arguments of min and max, "," only separates arguments when followed by a space.
+// Division rounds towards zero. Spaces are allowed between tokens.
+func EvaluateArithmetic(str string) (*big.Int, error) {
+	p := &arithmeticParser{source: str}
+	result, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.source) {
+		return nil, fmt.Errorf("unexpected %q in %s", p.source[p.pos], str)
+	}
+	return result, nil
+}
+
+type arithmeticParser struct {
+	source string
+	pos    int
+}
+
+func (p *arithmeticParser) skipSpaces() {
+	for p.pos < len(p.source) && p.source[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// accept consumes the token if it comes next.
+func (p *arithmeticParser) accept(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.source[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *arithmeticParser) parseSum() (*big.Int, error) {
+	result, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("+"):
+			operand, err := p.parseProduct()
+			if err != nil {
+				return nil, err
+			}
+			result.Add(result, operand)
+		case p.accept("-"):
+			operand, err := p.parseProduct()
+			if err != nil {
+				return nil, err
+			}
+			result.Sub(result, operand)
+		default:
+			return result, nil
+		}
+	}
+}
+
+func (p *arithmeticParser) parseProduct() (*big.Int, error) {
+	result, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if strings.HasPrefix(p.source[p.pos:], "**") {
+			return result, nil
+		}
+		var operator byte
+		switch {
+		case p.accept("*"):
+			operator = '*'
+		case p.accept("/"):
+			operator = '/'
+		case p.accept("%"):
+			operator = '%'
+		default:
+			return result, nil
+		}
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		switch operator {
+		case '*':
+			result.Mul(result, operand)
+		case '/':
+			if operand.Sign() == 0 {
+				return nil, errors.New("division by zero")
+			}
+			result.Quo(result, operand)
+		default:
+			if operand.Sign() == 0 {
+				return nil, errors.New("division by zero")
+			}
+			result.Rem(result, operand)
+		}
+	}
+}
+
+func (p *arithmeticParser) parseUnary() (*big.Int, error) {
+	p.skipSpaces()
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return operand.Neg(operand), nil
+	}
+	if p.accept("+") {
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *arithmeticParser) parsePower() (*big.Int, error) {
+	base, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("**") {
+		return base, nil
+	}
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if exponent.Sign() < 0 {
+		return nil, fmt.Errorf("negative exponent: %s", exponent)
+	}
+	if exponent.Cmp(big.NewInt(maxExponent)) > 0 {
+		return nil, fmt.Errorf("exponent too large: %s", exponent)
+	}
+	return base.Exp(base, exponent, nil), nil
+}
+
+func (p *arithmeticParser) parseAtom() (*big.Int, error) {
+	p.skipSpaces()
+	if p.accept("(") {
+		result, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing \")\" in %s", p.source)
+		}
+		return result, nil
+	}
+	for _, function := range []string{"min", "max"} {
+		if p.accept(function + "(") {
+			return p.parseMinMax(function)
+		}
+	}
+	for _, denomination := range Denominations() {
+		if p.accept(denomination.Prefix()) {
+			return p.parseDenominated(denomination)
+		}
+	}
+	return p.parseNumber()
+}
+
+func (p *arithmeticParser) parseMinMax(function string) (*big.Int, error) {
+	var args []*big.Int
+	for {
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.accept(")") {
+			break
+		}
+		if !p.accept(",") {
+			return nil, fmt.Errorf("missing \")\" after the arguments of %s", function)
+		}
+	}
+	if len(args) < 2 {
+		return nil, fmt.Errorf("%s expects at least 2 arguments, separated by \", \"", function)
+	}
+
+	result := args[0]
+	for _, arg := range args[1:] {
+		if (function == "min") == (arg.Cmp(result) < 0) {
+			result = arg
+		}
+	}
+	return result, nil
+}
+
+// scanDigits reads a number, without interpreting it. Commas followed by a space end the number.
+func (p *arithmeticParser) scanDigits(digits string) string {
+	start := p.pos
+	for p.pos < len(p.source) {
+		c := p.source[p.pos]
+		isGroupSeparator := c == '_' || (c == ',' && p.pos+1 < len(p.source) && p.source[p.pos+1] != ' ')
+		if !strings.ContainsRune(digits, rune(c)) && !isGroupSeparator {
+			break
+		}
+		p.pos++
+	}
+	return strings.NewReplacer("_", "", ",", "").Replace(p.source[start:p.pos])
+}
+
+func (p *arithmeticParser) parseNumber() (*big.Int, error) {
+	p.skipSpaces()
+	start := p.pos
+	base := 10
+	digits := "0123456789"
+	switch {
+	case p.accept("0x") || p.accept("0X"):
+		base = 16
+		digits = "0123456789abcdefABCDEF"
+	case p.accept("0b") || p.accept("0B"):
+		base = 2
+		digits = "01"
+	}
+	str := p.scanDigits(digits)
+	if len(str) == 0 {
+		if base != 10 {
+			// empty hex or binary
+			return big.NewInt(0), nil
+		}
+		if start >= len(p.source) {
+			return nil, fmt.Errorf("missing number at the end of %s", p.source)
+		}
+		return nil, fmt.Errorf("unexpected %q in %s", p.source[start], p.source)
+	}
+	result, ok := big.NewInt(0).SetString(str, base)
+	if !ok {
+		return nil, fmt.Errorf("could not parse number: %s", p.source[start:p.pos])
+	}
+	return result, nil
+}
+
+// parseDenominated reads a decimal amount, e.g. "1.5" in "moax:1.5", and yields it in the smallest unit.
+func (p *arithmeticParser) parseDenominated(denomination *Denomination) (*big.Int, error) {
+	integerPart := p.scanDigits("0123456789")
+	fractionalPart := ""
+	if p.pos < len(p.source) && p.source[p.pos] == '.' {
+		p.pos++
+		fractionalPart = p.scanDigits("0123456789")
+	}
+	if len(integerPart) == 0 && len(fractionalPart) == 0 {
+		return nil, fmt.Errorf("missing amount after %s", denomination.Prefix())
+	}
+	if len(fractionalPart) > denomination.Decimals {
+		return nil, fmt.Errorf("%s amounts have at most %d decimals", denomination.Name, denomination.Decimals)
+	}
+
+	fractionalPart += strings.Repeat("0", denomination.Decimals-len(fractionalPart))
+	result, ok := big.NewInt(0).SetString(integerPart+fractionalPart, 10)
+	if !ok {
+		return nil, fmt.Errorf("could not parse amount: %s%s", denomination.Prefix(), integerPart)
+	}
+	return result, nil
+}
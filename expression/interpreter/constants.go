@@ -0,0 +1,106 @@
+package scenexpressioninterpreter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const varPrefix = "var:"
+
+var constantNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CheckConstantName checks that a constant can be referenced as "${name}" and "var:name".
+func CheckConstantName(name string) error {
+	if !constantNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid constant name: %q", name)
+	}
+	return nil
+}
+
+// constantValue yields the expression of a constant, checking that it is not defined in terms of itself.
+// resolving holds the constants being resolved, from the outermost.
+func (ei *ExprInterpreter) constantValue(name string, resolving []string) (string, error) {
+	expression, found := ei.Constants[name]
+	if !found {
+		return "", fmt.Errorf("unknown constant: %s", name)
+	}
+	for i, other := range resolving {
+		if other == name {
+			cycle := append(append([]string{}, resolving[i:]...), name)
+			return "", fmt.Errorf("constant defined in terms of itself: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	return expression, nil
+}
+
+// constantReference yields the name of the "${name}" reference at the given position, and its end,
+// or an empty name if there is none.
+func constantReference(str string, pos int) (string, int) {
+	if !strings.HasPrefix(str[pos:], "${") {
+		return "", pos
+	}
+	length := strings.IndexByte(str[pos+2:], '}')
+	if length < 0 || CheckConstantName(str[pos+2:pos+2+length]) != nil {
+		return "", pos
+	}
+	return str[pos+2 : pos+2+length], pos + 3 + length
+}
+
+// expandArgument substitutes the "${name}" references in the argument of a literal with the expressions
+// of the constants, recursively, and "$${" with "${". The argument stays a single argument, whatever the constants hold.
+// Without constants in scope, arguments are left as they are.
+func (ei *ExprInterpreter) expandArgument(arg string, resolving []string) (string, error) {
+	if len(ei.Constants) == 0 || !strings.Contains(arg, "${") {
+		return arg, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(arg); {
+		if strings.HasPrefix(arg[i:], "$${") {
+			sb.WriteString("${")
+			i += 3
+			continue
+		}
+		name, end := constantReference(arg, i)
+		if len(name) == 0 {
+			sb.WriteByte(arg[i])
+			i++
+			continue
+		}
+		expression, err := ei.constantValue(name, resolving)
+		if err != nil {
+			return "", err
+		}
+		expression, err = ei.expandArgument(expression, append(resolving, name))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(expression)
+		i = end
+	}
+	return sb.String(), nil
+}
+
+// interpretConstant yields the value of a "var:name" reference.
+func (ei *ExprInterpreter) interpretConstant(name string) ([]byte, error) {
+	expression, err := ei.constantValue(name, ei.resolving)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	ei.resolving = append(ei.resolving, name)
+	defer func() {
+		ei.resolving = ei.resolving[:len(ei.resolving)-1]
+	}()
+	value, err := ei.InterpretString(expression)
+	if err != nil {
+		// offsets in the constant are meaningless at the reference
+		var exprErr *ExpressionError
+		if errors.As(err, &exprErr) {
+			err = exprErr.Err
+		}
+		return []byte{}, fmt.Errorf("error in constant %s: %s", name, err.Error())
+	}
+	return value, nil
+}
@@ -0,0 +1,153 @@
+package scenexpressioninterpreter
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Hasher computes the hashes available in value expressions.
+type Hasher interface {
+	Keccak256(data []byte) ([]byte, error)
+	Sha256(data []byte) ([]byte, error)
+	Blake2b(data []byte) ([]byte, error)
+	Ripemd160(data []byte) ([]byte, error)
+}
+
+// SignatureScheme identifies a signature algorithm.
+type SignatureScheme string
+
+const (
+	// Ed25519 signatures, the scheme of account keys.
+	Ed25519 SignatureScheme = "ed25519"
+
+	// Secp256k1 ECDSA signatures, in DER format.
+	Secp256k1 SignatureScheme = "secp256k1"
+)
+
+// Signer derives key pairs from seeds and signs messages.
+// Signatures must be deterministic, so that scenarios can check them.
+type Signer interface {
+	PublicKey(scheme SignatureScheme, seed []byte) ([]byte, error)
+	Sign(scheme SignatureScheme, seed []byte, message []byte) ([]byte, error)
+}
+
+// DefaultHasher is the Hasher used when none is provided.
+type DefaultHasher struct{}
+
+var _ Hasher = (*DefaultHasher)(nil)
+
+// Keccak256 yields the legacy Keccak-256 hash, as used by Ethereum.
+func (dh *DefaultHasher) Keccak256(data []byte) ([]byte, error) {
+	return Keccak256(data)
+}
+
+// Sha256 yields the SHA-256 hash.
+func (dh *DefaultHasher) Sha256(data []byte) ([]byte, error) {
+	hash := sha256.Sum256(data)
+	return hash[:], nil
+}
+
+// Blake2b yields the 32 byte BLAKE2b hash.
+func (dh *DefaultHasher) Blake2b(data []byte) ([]byte, error) {
+	hash := blake2b.Sum256(data)
+	return hash[:], nil
+}
+
+// Ripemd160 yields the RIPEMD-160 hash.
+func (dh *DefaultHasher) Ripemd160(data []byte) ([]byte, error) {
+	hash := ripemd160.New()
+	hash.Write(data)
+	return hash.Sum(nil), nil
+}
+
+// DefaultSigner is the Signer used when none is provided.
+// Seeds are 32 bytes long: the ed25519 seed, or the secp256k1 private key.
+// Secp256k1 signatures are computed on the SHA-256 hash of the message, with deterministic nonces (RFC 6979).
+type DefaultSigner struct{}
+
+var _ Signer = (*DefaultSigner)(nil)
+
+const seedLength = 32
+
+// PublicKey yields the public key derived from a seed: 32 bytes for ed25519, 33 bytes (compressed) for secp256k1.
+func (ds *DefaultSigner) PublicKey(scheme SignatureScheme, seed []byte) ([]byte, error) {
+	switch scheme {
+	case Ed25519:
+		privateKey, err := ed25519PrivateKey(seed)
+		if err != nil {
+			return []byte{}, err
+		}
+		return privateKey.Public().(ed25519.PublicKey), nil
+	case Secp256k1:
+		privateKey, err := secp256k1PrivateKey(seed)
+		if err != nil {
+			return []byte{}, err
+		}
+		return privateKey.PubKey().SerializeCompressed(), nil
+	default:
+		return []byte{}, fmt.Errorf("unknown signature scheme: %s", scheme)
+	}
+}
+
+// Sign signs a message with the key pair derived from a seed.
+func (ds *DefaultSigner) Sign(scheme SignatureScheme, seed []byte, message []byte) ([]byte, error) {
+	switch scheme {
+	case Ed25519:
+		privateKey, err := ed25519PrivateKey(seed)
+		if err != nil {
+			return []byte{}, err
+		}
+		return ed25519.Sign(privateKey, message), nil
+	case Secp256k1:
+		privateKey, err := secp256k1PrivateKey(seed)
+		if err != nil {
+			return []byte{}, err
+		}
+		hash := sha256.Sum256(message)
+		return ecdsa.Sign(privateKey, hash[:]).Serialize(), nil
+	default:
+		return []byte{}, fmt.Errorf("unknown signature scheme: %s", scheme)
+	}
+}
+
+func ed25519PrivateKey(seed []byte) (ed25519.PrivateKey, error) {
+	if len(seed) != seedLength {
+		return nil, fmt.Errorf("seed must be %d bytes long, got %d", seedLength, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+func secp256k1PrivateKey(seed []byte) (*secp256k1.PrivateKey, error) {
+	if len(seed) != seedLength {
+		return nil, fmt.Errorf("seed must be %d bytes long, got %d", seedLength, len(seed))
+	}
+	var scalar secp256k1.ModNScalar
+	overflow := scalar.SetByteSlice(seed)
+	if overflow || scalar.IsZero() {
+		return nil, errors.New("seed is not a valid secp256k1 private key")
+	}
+	return secp256k1.NewPrivateKey(&scalar), nil
+}
+
+// GetHasher yields the Hasher, the default one if none was provided.
+func (ei *ExprInterpreter) GetHasher() Hasher {
+	if ei.Hasher == nil {
+		return &DefaultHasher{}
+	}
+	return ei.Hasher
+}
+
+// GetSigner yields the Signer, the default one if none was provided.
+func (ei *ExprInterpreter) GetSigner() Signer {
+	if ei.Signer == nil {
+		return &DefaultSigner{}
+	}
+	return ei.Signer
+}
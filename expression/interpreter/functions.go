@@ -1,14 +1,13 @@
 package scenexpressioninterpreter
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"strings"
 
 	"github.com/bhagyaraj1208117/andes-core-go/core"
 	"golang.org/x/crypto/sha3"
-
-	pc "github.com/bhagyaraj1208117/andes-core-go/core/pubkeyConverter"
 )
 
 // SCAddressNumLeadingZeros is the number of zero bytes every smart contract address begins with.
@@ -20,7 +19,6 @@ const SCAddressNumLeadingZeros = 8
 const SCAddressReservedPrefixLength = SCAddressNumLeadingZeros + 2
 
 // Keccak256 cryptographic function
-// TODO: externalize the same way as the file resolver
 func Keccak256(data []byte) ([]byte, error) {
 	hash := sha3.NewLegacyKeccak256()
 	hash.Write(data)
@@ -81,14 +79,34 @@ func (ei *ExprInterpreter) scExpression(input string) ([]byte, error) {
 	return address, err
 }
 
-func bech32Decode(input string) ([]byte, error) {
-	addressLen := 32
-	bpc, _ := pc.NewBech32PubkeyConverter(addressLen, core.DefaultAddressPrefix)
-	str, err := bpc.Decode(input)
+// shardIdentifierLength is the number of trailing bytes that SC addresses copy from their creator.
+const shardIdentifierLength = 2
 
+// DeriveSCAddress computes the address of a smart contract the way the protocol does:
+// the Keccak-256 hash of the creator address and nonce (8 bytes, little endian),
+// with the SC prefix and VM type in front, and the last 2 bytes of the creator address at the end.
+func DeriveSCAddress(creatorAddress []byte, creatorNonce uint64, vmType []byte) ([]byte, error) {
+	if len(creatorAddress) != DefaultAddressLength {
+		return []byte{}, fmt.Errorf("creator address must be %d bytes long, got %d", DefaultAddressLength, len(creatorAddress))
+	}
+	if len(vmType) != core.VMTypeLen {
+		return []byte{}, fmt.Errorf("VM type must be %d bytes long, got %d", core.VMTypeLen, len(vmType))
+	}
+	nonceBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(nonceBytes, creatorNonce)
+	address, err := Keccak256(append(append([]byte{}, creatorAddress...), nonceBytes...))
 	if err != nil {
 		return []byte{}, err
 	}
+	for i := 0; i < SCAddressReservedPrefixLength-core.VMTypeLen; i++ {
+		address[i] = 0
+	}
+	copy(address[SCAddressReservedPrefixLength-core.VMTypeLen:], vmType)
+	copy(address[len(address)-shardIdentifierLength:], creatorAddress[len(creatorAddress)-shardIdentifierLength:])
+	return address, nil
+}
 
-	return str, err
+// DeriveSCAddress computes the address of a smart contract deployed by the creator, for the configured VM type.
+func (ei *ExprInterpreter) DeriveSCAddress(creatorAddress []byte, creatorNonce uint64) ([]byte, error) {
+	return DeriveSCAddress(creatorAddress, creatorNonce, ei.GetVMType()[:])
 }
@@ -0,0 +1,611 @@
+package scenexpressioninterpreter
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ExprKind is the kind of node in a parsed value expression.
+type ExprKind int
+
+const (
+	// ConcatExpr concatenates the values of its Parts. Parenthesized groups are also concatenations.
+	ConcatExpr ExprKind = iota
+
+	// CallExpr applies the function named by Prefix to the value of its only part, e.g. "keccak256:" or "nested:".
+	// Registered functions have one part per argument.
+	CallExpr
+
+	// LiteralExpr is a value written directly, e.g. "str:abc", "u32:5", "address:owner" or "1,000".
+	// Prefix is empty for numbers and bools.
+	LiteralExpr
+
+	// ConstantExpr is a part written "${name}", which has the value of the constant, like "var:name".
+	// Arg is the name of the constant.
+	ConstantExpr
+)
+
+// Expr is a node in the syntax tree of a value expression.
+type Expr struct {
+	Kind ExprKind
+
+	// Offset and End delimit the node in the source, as 0-based byte offsets.
+	Offset int
+	End    int
+
+	// Prefix is the function or literal prefix, including the ':', e.g. "str:".
+	Prefix string
+
+	// Arg is the argument of literals, with escape sequences already replaced.
+	Arg string
+
+	Parts []*Expr
+
+	// Grouped concatenations were written in parentheses.
+	Grouped bool
+}
+
+// ExpressionError is a problem at a given position in a value expression.
+type ExpressionError struct {
+	// Offset is the 0-based byte offset in the expression.
+	Offset int
+	Err    error
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("%v (at offset %d)", e.Err, e.Offset)
+}
+
+func (e *ExpressionError) Unwrap() error {
+	return e.Err
+}
+
+// ParseExpression builds the syntax tree of a value expression.
+//
+// The grammar is:
+//
+//	expression := part ("|" part)*
+//	part       := "" | "(" expression ")" | "${" name "}" | call | literal
+//	call       := hash (group | expression) | sign seed ":" (group | expression) | "nested:" part |
+//	              "sc-derived:" creator ":" number
+//	hash       := "keccak256:" | "sha256:" | "blake2b:" | "ripemd160:" | "pubkey:" | "pubkey-secp256k1:"
+//	sign       := "sign-ed25519:" | "sign-secp256k1:"
+//	seed       := "(" expression ")" | number
+//	creator    := "(" expression ")" | part
+//	literal    := prefix? argument
+//
+// The arguments of "file:" and "mxsc:" extend to the end of the enclosing expression, "|" included.
+// The creator of "sc-derived:" ends at the last ":" of the part, e.g. "sc-derived:address:owner:5".
+// Other arguments end at the next "|". Inside parentheses, an unescaped ")" also ends them,
+// unless it closes a parenthesis opened in the argument, as in arithmetic, e.g. "2*(1+2)".
+// A group followed by an arithmetic operator, e.g. "(1+2)*3", is a number, see EvaluateArithmetic.
+// String arguments, of "str:", "“" or "”", accept the escape sequences \\, \(, \), \|, \n, \r, \t and \xHH,
+// e.g. "str:a\|b" for a literal "|". Other backslashes are kept as they are, e.g. in "str:C:\path".
+// Calls to registered functions, and constant references, are only recognized by ExprInterpreter.ParseExpression,
+// the latter only when constants are in scope. A constant reference can also be written in the argument of a literal,
+// other than a string or a file path, e.g. "u32:${amount}", where it stands for the expression of the constant.
+func ParseExpression(source string) (*Expr, error) {
+	p := &exprParser{source: source}
+	return p.parseConcat()
+}
+
+// ParseExpression builds the syntax tree of a value expression,
+// which can also call the registered functions and reference the constants in scope.
+func (ei *ExprInterpreter) ParseExpression(source string) (*Expr, error) {
+	p := &exprParser{source: source, functions: ei.functions, constants: len(ei.Constants) > 0}
+	return p.parseConcat()
+}
+
+type exprParser struct {
+	source    string
+	pos       int
+	depth     int
+	functions map[string]*Function
+	constants bool
+}
+
+func (p *exprParser) errorf(offset int, format string, args ...interface{}) error {
+	return &ExpressionError{Offset: offset, Err: fmt.Errorf(format, args...)}
+}
+
+// atScopeEnd checks if the current expression ends here, at the end of the source or of a group.
+func (p *exprParser) atScopeEnd() bool {
+	return p.pos >= len(p.source) || (p.depth > 0 && p.source[p.pos] == ')')
+}
+
+func (p *exprParser) atPartEnd() bool {
+	return p.atScopeEnd() || p.source[p.pos] == '|'
+}
+
+func (p *exprParser) parseConcat() (*Expr, error) {
+	concat := &Expr{Kind: ConcatExpr, Offset: p.pos}
+	for {
+		part, err := p.parsePart()
+		if err != nil {
+			return nil, err
+		}
+		if part != nil {
+			concat.Parts = append(concat.Parts, part)
+		}
+		if p.atScopeEnd() {
+			break
+		}
+		if p.source[p.pos] != '|' {
+			return nil, p.errorf(p.pos, "unexpected character %q, expected \"|\"", p.source[p.pos])
+		}
+		p.pos++
+	}
+	concat.End = p.pos
+	return concat, nil
+}
+
+// parsePart yields nil for empty parts.
+func (p *exprParser) parsePart() (*Expr, error) {
+	start := p.pos
+	if p.atPartEnd() {
+		return nil, nil
+	}
+	if p.source[p.pos] == '(' && !p.startsArithmetic() {
+		return p.parseGroup()
+	}
+	if p.constants {
+		name, end := constantReference(p.source, p.pos)
+		if len(name) > 0 {
+			p.pos = end
+			if p.atPartEnd() {
+				return &Expr{Kind: ConstantExpr, Offset: start, End: p.pos, Arg: name}, nil
+			}
+			// part of a literal, e.g. "${amount} * 2"
+			p.pos = start
+		}
+	}
+
+	prefix := p.matchPrefix()
+	p.pos += len(prefix)
+	switch {
+	case p.functions[prefix] != nil:
+		return p.parseFunctionCall(start, prefix, p.functions[prefix])
+	case containsString(hashPrefixes, prefix):
+		arg, err := p.parseExpressionArg()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Kind: CallExpr, Offset: start, End: p.pos, Prefix: prefix, Parts: []*Expr{arg}}, nil
+	case prefix == signEd25519Prefix || prefix == signSecp256k1Prefix:
+		seed, err := p.parseSeed()
+		if err != nil {
+			return nil, err
+		}
+		message, err := p.parseExpressionArg()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Kind: CallExpr, Offset: start, End: p.pos, Prefix: prefix, Parts: []*Expr{seed, message}}, nil
+	case prefix == scDerivedPrefix:
+		creator, err := p.parseCreator()
+		if err != nil {
+			return nil, err
+		}
+		nonceStart := p.pos
+		p.scanLiteral()
+		nonce := &Expr{Kind: LiteralExpr, Offset: nonceStart, End: p.pos, Arg: p.source[nonceStart:p.pos]}
+		return &Expr{Kind: CallExpr, Offset: start, End: p.pos, Prefix: prefix, Parts: []*Expr{creator, nonce}}, nil
+	case prefix == nestedPrefix:
+		arg, err := p.parsePart()
+		if err != nil {
+			return nil, err
+		}
+		if arg == nil {
+			arg = &Expr{Kind: ConcatExpr, Offset: p.pos, End: p.pos}
+		}
+		return &Expr{Kind: CallExpr, Offset: start, End: p.pos, Prefix: prefix, Parts: []*Expr{arg}}, nil
+	case containsString(strPrefixes, prefix):
+		arg := p.scanEscaped()
+		return &Expr{Kind: LiteralExpr, Offset: start, End: p.pos, Prefix: prefix, Arg: arg}, nil
+	case containsString(WholeValuePrefixes(), prefix):
+		argStart := p.pos
+		for !p.atScopeEnd() {
+			p.pos++
+		}
+		return &Expr{Kind: LiteralExpr, Offset: start, End: p.pos, Prefix: prefix, Arg: p.source[argStart:p.pos]}, nil
+	default:
+		argStart := p.pos
+		p.scanLiteral()
+		return &Expr{Kind: LiteralExpr, Offset: start, End: p.pos, Prefix: prefix, Arg: p.source[argStart:p.pos]}, nil
+	}
+}
+
+// startsArithmetic checks if the parenthesized group at the current position is followed by an arithmetic operator,
+// as in "(1+2)*3", in which case it is not a concatenation.
+func (p *exprParser) startsArithmetic() bool {
+	depth := 0
+	for i := p.pos; i < len(p.source); i++ {
+		switch p.source[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				rest := strings.TrimLeft(p.source[i+1:], " ")
+				return len(rest) > 0 && strings.ContainsRune("+-*/%", rune(rest[0]))
+			}
+		}
+	}
+	return false
+}
+
+// scanLiteral skips a literal argument, up to the end of the part.
+// Parentheses opened in the literal do not end it when closed, as in "2*(1+2)".
+func (p *exprParser) scanLiteral() {
+	depth := 0
+	for p.pos < len(p.source) && p.source[p.pos] != '|' {
+		switch p.source[p.pos] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 && p.depth > 0 {
+				return
+			}
+			depth--
+		}
+		p.pos++
+	}
+}
+
+// parseExpressionArg reads a group, or the rest of the expression.
+func (p *exprParser) parseExpressionArg() (*Expr, error) {
+	if p.pos < len(p.source) && p.source[p.pos] == '(' {
+		return p.parseGroup()
+	}
+	return p.parseConcat()
+}
+
+// parseSeed reads the seed of a signature, a group or a number, followed by ":".
+func (p *exprParser) parseSeed() (*Expr, error) {
+	var seed *Expr
+	var err error
+	if p.pos < len(p.source) && p.source[p.pos] == '(' {
+		seed, err = p.parseGroupFollowedBy(':')
+	} else {
+		seedStart := p.pos
+		for !p.atPartEnd() && p.source[p.pos] != ':' {
+			p.pos++
+		}
+		seed = &Expr{Kind: LiteralExpr, Offset: seedStart, End: p.pos, Arg: p.source[seedStart:p.pos]}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.source) || p.source[p.pos] != ':' {
+		return nil, p.errorf(p.pos, "expected \":\" after the seed")
+	}
+	p.pos++
+	return seed, nil
+}
+
+// parseCreator reads the creator address of "sc-derived:", a group or a part, followed by ":".
+func (p *exprParser) parseCreator() (*Expr, error) {
+	if p.pos < len(p.source) && p.source[p.pos] == '(' {
+		group, err := p.parseGroupFollowedBy(':')
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.source) || p.source[p.pos] != ':' {
+			return nil, p.errorf(p.pos, "expected \":\" before the nonce")
+		}
+		p.pos++
+		return group, nil
+	}
+
+	lastColon := -1
+	for i := p.pos; i < len(p.source) && p.source[i] != '|' && !(p.depth > 0 && p.source[i] == ')'); i++ {
+		if p.source[i] == ':' {
+			lastColon = i
+		}
+	}
+	if lastColon < 0 {
+		return nil, p.errorf(p.pos, "expected \":\" before the nonce")
+	}
+	creatorParser := &exprParser{source: p.source[:lastColon], pos: p.pos, functions: p.functions, constants: p.constants}
+	creator, err := creatorParser.parsePart()
+	if err != nil {
+		return nil, err
+	}
+	if creator == nil {
+		return nil, p.errorf(p.pos, "missing creator address")
+	}
+	if creatorParser.pos != lastColon {
+		return nil, p.errorf(creatorParser.pos, "unexpected character %q in creator address", p.source[creatorParser.pos])
+	}
+	p.pos = lastColon + 1
+	return creator, nil
+}
+
+// parseFunctionCall reads the arguments of a registered function.
+// Non-expression arguments are literals without prefix, Arg holding their text.
+func (p *exprParser) parseFunctionCall(start int, prefix string, function *Function) (*Expr, error) {
+	call := &Expr{Kind: CallExpr, Offset: start, Prefix: prefix}
+	for i, kind := range function.Args {
+		last := i == len(function.Args)-1
+		if kind == ExpressionArg {
+			arg, err := p.parseExpressionArg()
+			if err != nil {
+				return nil, err
+			}
+			call.Parts = append(call.Parts, arg)
+			continue
+		}
+
+		argStart := p.pos
+		for !p.atPartEnd() && (last || p.source[p.pos] != ':') {
+			p.pos++
+		}
+		call.Parts = append(call.Parts, &Expr{Kind: LiteralExpr, Offset: argStart, End: p.pos, Arg: p.source[argStart:p.pos]})
+		if last {
+			break
+		}
+		if p.atPartEnd() {
+			return nil, p.errorf(p.pos, "%s expects %d arguments", function.Name, len(function.Args))
+		}
+		p.pos++
+	}
+	call.End = p.pos
+	return call, nil
+}
+
+func (p *exprParser) parseGroup() (*Expr, error) {
+	return p.parseGroupFollowedBy('|')
+}
+
+// parseGroupFollowedBy reads a parenthesized group, which must be followed by the given separator or end the part.
+func (p *exprParser) parseGroupFollowedBy(separator byte) (*Expr, error) {
+	start := p.pos
+	p.pos++
+	p.depth++
+	group, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.source) {
+		return nil, p.errorf(start, "unclosed \"(\"")
+	}
+	p.pos++
+	p.depth--
+	group.Offset = start
+	group.End = p.pos
+	group.Grouped = true
+	if !p.atScopeEnd() && p.source[p.pos] != separator {
+		return nil, p.errorf(p.pos, "unexpected character %q after \")\"", p.source[p.pos])
+	}
+	return group, nil
+}
+
+func (p *exprParser) matchPrefix() string {
+	for prefix := range p.functions {
+		if strings.HasPrefix(p.source[p.pos:], prefix) {
+			return prefix
+		}
+	}
+	for _, prefix := range Prefixes() {
+		if strings.HasPrefix(p.source[p.pos:], prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// scanEscaped reads a string argument, up to the end of the part.
+// As in scanLiteral, parentheses opened in the argument do not end it when closed.
+func (p *exprParser) scanEscaped() string {
+	var sb strings.Builder
+	depth := 0
+	for p.pos < len(p.source) && p.source[p.pos] != '|' {
+		c := p.source[p.pos]
+		if c != '\\' {
+			switch c {
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 && p.depth > 0 {
+					return sb.String()
+				}
+				depth--
+			}
+			sb.WriteByte(c)
+			p.pos++
+			continue
+		}
+		p.pos++
+		if p.pos >= len(p.source) {
+			// a trailing backslash is a backslash
+			sb.WriteByte(c)
+			break
+		}
+		switch escaped := p.source[p.pos]; escaped {
+		case '\\', '(', ')', '|':
+			sb.WriteByte(escaped)
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'x':
+			if p.pos+3 > len(p.source) {
+				sb.WriteByte(c)
+				continue
+			}
+			b, err := strconv.ParseUint(p.source[p.pos+1:p.pos+3], 16, 8)
+			if err != nil {
+				sb.WriteByte(c)
+				continue
+			}
+			sb.WriteByte(byte(b))
+			p.pos += 2
+		default:
+			// not an escape sequence, for backwards compatibility
+			sb.WriteByte(c)
+			continue
+		}
+		p.pos++
+	}
+	return sb.String()
+}
+
+// EscapeString converts a string to a "str:" argument that yields it back, also inside parentheses.
+func EscapeString(str string) string {
+	unbalanced := unbalancedParentheses(str)
+	var sb strings.Builder
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case c == '\\':
+			sb.WriteString(`\\`)
+		case c == '|' || unbalanced[i]:
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c < 32 || c == 127:
+			sb.WriteString(fmt.Sprintf(`\x%02x`, c))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// unbalancedParentheses yields the positions of the parentheses that have no match in the string.
+func unbalancedParentheses(str string) map[int]bool {
+	unbalanced := make(map[int]bool)
+	var open []int
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '(':
+			open = append(open, i)
+		case ')':
+			if len(open) == 0 {
+				unbalanced[i] = true
+			} else {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	for _, i := range open {
+		unbalanced[i] = true
+	}
+	return unbalanced
+}
+
+// evaluate computes the value of a syntax tree.
+// Errors are reported at the position of the innermost node that failed.
+func (ei *ExprInterpreter) evaluate(expr *Expr) ([]byte, error) {
+	switch expr.Kind {
+	case ConcatExpr:
+		concat := make([]byte, 0)
+		for _, part := range expr.Parts {
+			value, err := ei.evaluate(part)
+			if err != nil {
+				return []byte{}, err
+			}
+			concat = append(concat, value...)
+		}
+		return concat, nil
+	case CallExpr:
+		if function, isRegistered := ei.functions[expr.Prefix]; isRegistered {
+			return ei.callFunction(expr, function)
+		}
+		var args [][]byte
+		for _, part := range expr.Parts {
+			arg, err := ei.evaluate(part)
+			if err != nil {
+				return []byte{}, err
+			}
+			args = append(args, arg)
+		}
+		result, err := ei.call(expr.Prefix, args)
+		if err != nil {
+			return []byte{}, &ExpressionError{Offset: expr.Offset, Err: err}
+		}
+		return result, nil
+	case LiteralExpr, ConstantExpr:
+		var result []byte
+		var err error
+		if expr.Kind == ConstantExpr {
+			result, err = ei.interpretConstant(expr.Arg)
+		} else {
+			result, err = ei.interpretLiteral(expr.Prefix, expr.Arg)
+		}
+		if err != nil {
+			var exprErr *ExpressionError
+			if !errors.As(err, &exprErr) {
+				err = &ExpressionError{Offset: expr.Offset, Err: err}
+			}
+			return []byte{}, err
+		}
+		return result, nil
+	default:
+		return []byte{}, fmt.Errorf("unknown expression kind: %d", expr.Kind)
+	}
+}
+
+func (ei *ExprInterpreter) call(prefix string, args [][]byte) ([]byte, error) {
+	var result []byte
+	var err error
+	switch prefix {
+	case keccak256Prefix:
+		result, err = ei.GetHasher().Keccak256(args[0])
+	case sha256Prefix:
+		result, err = ei.GetHasher().Sha256(args[0])
+	case blake2bPrefix:
+		result, err = ei.GetHasher().Blake2b(args[0])
+	case ripemd160Prefix:
+		result, err = ei.GetHasher().Ripemd160(args[0])
+	case pubkeyPrefix:
+		result, err = ei.GetSigner().PublicKey(Ed25519, args[0])
+	case pubkeySecp256k1Prefix:
+		result, err = ei.GetSigner().PublicKey(Secp256k1, args[0])
+	case signEd25519Prefix:
+		result, err = ei.GetSigner().Sign(Ed25519, args[0], args[1])
+	case signSecp256k1Prefix:
+		result, err = ei.GetSigner().Sign(Secp256k1, args[0], args[1])
+	case scDerivedPrefix:
+		if len(args[1]) > 8 {
+			return []byte{}, fmt.Errorf("nonce does not fit in 8 bytes")
+		}
+		nonce := big.NewInt(0).SetBytes(args[1]).Uint64()
+		result, err = ei.DeriveSCAddress(args[0], nonce)
+	case nestedPrefix:
+		return nestedEncode(args[0]), nil
+	default:
+		return []byte{}, fmt.Errorf("unknown function: %s", prefix)
+	}
+	if err != nil {
+		return []byte{}, fmt.Errorf("error computing %s %w", prefix, err)
+	}
+	return result, nil
+}
+
+// hashPrefixes are the functions of a single expression argument, which can extend to the end of the expression.
+var hashPrefixes = []string{
+	keccak256Prefix, sha256Prefix, blake2bPrefix, ripemd160Prefix,
+	pubkeyPrefix, pubkeySecp256k1Prefix,
+}
+
+func containsString(list []string, str string) bool {
+	for _, elem := range list {
+		if elem == str {
+			return true
+		}
+	}
+	return false
+}
@@ -19,10 +19,19 @@ var strPrefixes = []string{"str:", "``", "''"}
 const addrPrefix = "address:"
 const scAddrPrefix = "sc:"
 const bech32Prefix = "bech32:"
+const scDerivedPrefix = "sc-derived:"
 
 const filePrefix = "file:"
 const mxscPrefix = "mxsc:"
 const keccak256Prefix = "keccak256:"
+const sha256Prefix = "sha256:"
+const blake2bPrefix = "blake2b:"
+const ripemd160Prefix = "ripemd160:"
+
+const pubkeyPrefix = "pubkey:"
+const pubkeySecp256k1Prefix = "pubkey-secp256k1:"
+const signEd25519Prefix = "sign-ed25519:"
+const signSecp256k1Prefix = "sign-secp256k1:"
 
 const u64Prefix = "u64:"
 const u32Prefix = "u32:"
@@ -37,10 +46,70 @@ const bigFloatPrefix = "bigfloat:"
 const biguintPrefix = "biguint:"
 const nestedPrefix = "nested:"
 
+// WholeValuePrefixes yields the prefixes whose argument extends to the end of the expression, "|" included.
+// Inside parentheses, the argument ends with the group.
+func WholeValuePrefixes() []string {
+	return []string{
+		mxscPrefix, filePrefix,
+		keccak256Prefix, sha256Prefix, blake2bPrefix, ripemd160Prefix,
+		pubkeyPrefix, pubkeySecp256k1Prefix, signEd25519Prefix, signSecp256k1Prefix,
+	}
+}
+
+// StringPrefixes yields the prefixes of string arguments, in which "\|" is a literal "|".
+func StringPrefixes() []string {
+	return append([]string{}, strPrefixes...)
+}
+
+// Prefixes yields all the prefixes that can start a value expression, or a part of a concatenation.
+// Numbers, "true" and "false" need no prefix.
+func Prefixes() []string {
+	prefixes := WholeValuePrefixes()
+	prefixes = append(prefixes, strPrefixes...)
+	return append(append(prefixes,
+		addrPrefix, scAddrPrefix, bech32Prefix, scDerivedPrefix,
+		u64Prefix, u32Prefix, u16Prefix, u8Prefix,
+		i64Prefix, i32Prefix, i16Prefix, i8Prefix,
+		bigFloatPrefix, biguintPrefix, nestedPrefix, varPrefix,
+	), denominationPrefixes()...)
+}
+
 // ExprInterpreter provides context for computing scenario values.
 type ExprInterpreter struct {
 	FileResolver fr.FileResolver
 	VMType       *[core.VMTypeLen]byte
+	Hasher       Hasher
+	Signer       Signer
+
+	// AddressConverter decodes "bech32:" addresses. The default HRP is "moa", for 32 byte addresses.
+	AddressConverter AddressConverter
+
+	// Constants are the scenario constants in scope, by name, as value expressions.
+	// They are referenced as "${name}" or "var:name", see ParseExpression.
+	Constants map[string]string
+
+	// resolving holds the constants being interpreted, to detect cycles
+	resolving []string
+
+	// functions are the user-defined functions, by prefix
+	functions map[string]*Function
+}
+
+// Clone creates an interpreter with the same settings and functions, and a cloned FileResolver.
+// The clone can be used concurrently with the original.
+func (ei *ExprInterpreter) Clone() ExprInterpreter {
+	clone := *ei
+	if ei.FileResolver != nil {
+		clone.FileResolver = ei.FileResolver.Clone()
+	}
+	clone.resolving = nil
+	if ei.functions != nil {
+		clone.functions = make(map[string]*Function, len(ei.functions))
+		for prefix, function := range ei.functions {
+			clone.functions[prefix] = function
+		}
+	}
+	return clone
 }
 
 // InterpretSubTree attempts to produce a value based on a JSON subtree.
@@ -94,115 +163,75 @@ func (ei *ExprInterpreter) InterpretSubTree(obj oj.OJsonObject) ([]byte, error)
 // - "true"/"false"
 // - "address:..."
 // - "sc:..." (also an address)
+// - "sc-derived:<creator>:<nonce>", the address of a contract deployed by the creator
 // - "file:..."
 // - "keccak256:..."
 // - concatenation using |
+// - grouping using parentheses, e.g. "keccak256:(str:a)|u32:1"
+// - constants, as "${name}" or "var:name"
+// See ParseExpression for the exact grammar.
 func (ei *ExprInterpreter) InterpretString(strRaw string) ([]byte, error) {
 	if len(strRaw) == 0 {
 		return []byte{}, nil
 	}
 
-	// file contents
-	// TODO: make this part of a proper parser
-	if strings.HasPrefix(strRaw, mxscPrefix) {
-		if ei.FileResolver == nil {
-			return []byte{}, errors.New("parser MxscResolver not provided")
-		}
-		fileContents, err := ei.FileResolver.ResolveFileValue(strRaw[len(mxscPrefix):])
-		if err != nil {
-			return []byte{}, err
-		}
-
-		return ei.interpretMxscJson(fileContents)
+	expr, err := ei.ParseExpression(strRaw)
+	if err != nil {
+		return []byte{}, err
 	}
+	return ei.evaluate(expr)
+}
 
-	// file contents
-	// TODO: make this part of a proper parser
-	if strings.HasPrefix(strRaw, filePrefix) {
-		if ei.FileResolver == nil {
-			return []byte{}, errors.New("parser FileResolver not provided")
-		}
-		fileContents, err := ei.FileResolver.ResolveFileValue(strRaw[len(filePrefix):])
+// interpretLiteral yields the value of a single, non-concatenated literal.
+func (ei *ExprInterpreter) interpretLiteral(prefix string, arg string) ([]byte, error) {
+	if !containsString(strPrefixes, prefix) && !containsString(WholeValuePrefixes(), prefix) {
+		var err error
+		arg, err = ei.expandArgument(arg, ei.resolving)
 		if err != nil {
 			return []byte{}, err
 		}
-		return fileContents, nil
 	}
-
-	// keccak256
-	// TODO: make this part of a proper parser
-	if strings.HasPrefix(strRaw, keccak256Prefix) {
-		arg, err := ei.InterpretString(strRaw[len(keccak256Prefix):])
-		if err != nil {
-			return []byte{}, fmt.Errorf("cannot parse keccak256 argument: %w", err)
+	switch {
+	case prefix == mxscPrefix:
+		if ei.FileResolver == nil {
+			return []byte{}, errors.New("parser MxscResolver not provided")
 		}
-		hash, err := Keccak256(arg)
+		fileContents, err := ei.FileResolver.ResolveFileValue(arg)
 		if err != nil {
-			return []byte{}, fmt.Errorf("error computing keccak256: %w", err)
+			return []byte{}, err
 		}
-		return hash, nil
-	}
-
-	// concatenate values of different formats
-	// TODO: make this part of a proper parser
-	parts := strings.Split(strRaw, "|")
-	if len(parts) > 1 {
-		concat := make([]byte, 0)
-		for _, part := range parts {
-			eval, err := ei.InterpretString(part)
-			if err != nil {
-				return []byte{}, err
-			}
-			concat = append(concat, eval...)
+		return ei.interpretMxscJson(fileContents)
+	case prefix == filePrefix:
+		if ei.FileResolver == nil {
+			return []byte{}, errors.New("parser FileResolver not provided")
 		}
-		return concat, nil
-	}
-
-	if strRaw == "false" {
+		return ei.FileResolver.ResolveFileValue(arg)
+	case containsString(strPrefixes, prefix):
+		// allow ascii strings, for readability
+		return []byte(arg), nil
+	case prefix == addrPrefix:
+		return addressExpression(arg)
+	case prefix == bech32Prefix:
+		return ei.GetAddressConverter().Decode(arg)
+	case prefix == scAddrPrefix:
+		// smart contract address (different format)
+		return ei.scExpression(arg)
+	case prefix == varPrefix:
+		return ei.interpretConstant(arg)
+	case containsString(denominationPrefixes(), prefix):
+		return ei.interpretNumber(prefix+arg, 0)
+	case len(prefix) > 0:
+		// fixed width numbers
+		_, result, err := ei.tryInterpretFixedWidth(prefix + arg)
+		return result, err
+	case arg == "false":
 		return []byte{}, nil
-	}
-
-	if strRaw == "true" {
+	case arg == "true":
 		return []byte{0x01}, nil
+	default:
+		// general numbers, arbitrary length
+		return ei.interpretNumber(arg, 0)
 	}
-
-	// allow ascii strings, for readability
-	for _, strPrefix := range strPrefixes {
-		if strings.HasPrefix(strRaw, strPrefix) {
-			str := strRaw[len(strPrefix):]
-			return []byte(str), nil
-		}
-	}
-
-	// address
-	if strings.HasPrefix(strRaw, addrPrefix) {
-		addrArgument := strRaw[len(addrPrefix):]
-		return addressExpression(addrArgument)
-	}
-
-	// bech32 address
-	if strings.HasPrefix(strRaw, bech32Prefix) {
-		addrArgument := strRaw[len(bech32Prefix):]
-		return bech32Decode(addrArgument)
-	}
-
-	// smart contract address (different format)
-	if strings.HasPrefix(strRaw, scAddrPrefix) {
-		addrArgument := strRaw[len(scAddrPrefix):]
-		return ei.scExpression(addrArgument)
-	}
-
-	// fixed width numbers
-	parsed, result, err := ei.tryInterpretFixedWidth(strRaw)
-	if err != nil {
-		return nil, err
-	}
-	if parsed {
-		return result, nil
-	}
-
-	// general numbers, arbitrary length
-	return ei.interpretNumber(strRaw, 0)
 }
 
 // GetVMType yields the configured VM type, which is used for generating SC addresses.
@@ -242,6 +271,10 @@ func (ei *ExprInterpreter) interpretFloatingPointNumber(strRaw string) ([]byte,
 
 // targetWidth = 0 means minimum length that can contain the result
 func (ei *ExprInterpreter) interpretNumber(strRaw string, targetWidth int) ([]byte, error) {
+	if isArithmetic(strRaw) {
+		return ei.interpretArithmetic(strRaw, targetWidth)
+	}
+
 	if strings.Contains(strRaw, ".") {
 		bfBytes, err := ei.interpretFloatingPointNumber(strRaw[:])
 		return bfBytes, err
@@ -276,7 +309,39 @@ func (ei *ExprInterpreter) interpretNumberWithSign(strRaw string, targetWidth in
 	return twos.ToBytesOfLength(number, targetWidth)
 }
 
+// interpretArithmetic computes a number. Results are signed if negative, or if explicitly signed with a leading "+".
+func (ei *ExprInterpreter) interpretArithmetic(strRaw string, targetWidth int) ([]byte, error) {
+	number, err := EvaluateArithmetic(strRaw)
+	if err != nil {
+		return []byte{}, err
+	}
+	if number.Sign() < 0 || strRaw[0] == '-' || strRaw[0] == '+' {
+		if targetWidth == 0 {
+			return twos.ToBytes(number), nil
+		}
+		return twos.ToBytesOfLength(number, targetWidth)
+	}
+	if targetWidth == 0 {
+		return number.Bytes(), nil
+	}
+	if len(number.Bytes()) > targetWidth {
+		return []byte{}, fmt.Errorf("representation of %s does not fit in %d bytes", strRaw, targetWidth)
+	}
+	return twos.CopyAlignRight(number.Bytes(), targetWidth), nil
+}
+
 func (ei *ExprInterpreter) interpretUnsignedNumber(strRaw string) ([]byte, error) {
+	if isArithmetic(strRaw) {
+		number, err := EvaluateArithmetic(strRaw)
+		if err != nil {
+			return []byte{}, err
+		}
+		if number.Sign() < 0 {
+			return []byte{}, fmt.Errorf("negative numbers not allowed in this context: %s", strRaw)
+		}
+		return number.Bytes(), nil
+	}
+
 	str := strings.ReplaceAll(strRaw, "_", "") // allow underscores, to group digits
 	strRaw = strings.ReplaceAll(str, ",", "")  // also allow commas to group digits
 
@@ -374,10 +439,6 @@ func (ei *ExprInterpreter) tryInterpretFixedWidth(strRaw string) (bool, []byte,
 		return ei.interpretExplicitFloatingPointNumber(strRaw)
 	}
 
-	if strings.HasPrefix(strRaw, nestedPrefix) {
-		return ei.interpretNestedBytes(strRaw)
-	}
-
 	return false, []byte{}, nil
 }
 
@@ -395,11 +456,11 @@ func (ei *ExprInterpreter) interpretExplicitBigUintNumber(strRaw string) (bool,
 	return true, append(encodedLength, biBytes...), err
 }
 
-func (ei *ExprInterpreter) interpretNestedBytes(strRaw string) (bool, []byte, error) {
-	nestedBytes, err := ei.InterpretString(strRaw[len(nestedPrefix):])
+// nestedEncode prepends the 4 byte length, as in the nested encoding of byte arrays.
+func nestedEncode(nestedBytes []byte) []byte {
 	lengthBytes := big.NewInt(int64(len(nestedBytes))).Bytes()
 	encodedLength := twos.CopyAlignRight(lengthBytes, 4)
-	return true, append(encodedLength, nestedBytes...), err
+	return append(encodedLength, nestedBytes...)
 }
 
 func (ei *ExprInterpreter) interpretMxscJson(fileContents []byte) ([]byte, error) {
@@ -0,0 +1,115 @@
+package scenexpressioninterpreter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// FunctionArgKind tells how a function argument is written and passed to the function.
+type FunctionArgKind int
+
+const (
+	// StringArg is passed as written, e.g. the ticker in "dctid:TICKER".
+	StringArg FunctionArgKind = iota
+
+	// NumberArg is a number, passed as its minimal big endian representation.
+	NumberArg
+
+	// ExpressionArg is a value expression, evaluated before calling the function.
+	// Like the argument of "keccak256:", it is a group in parentheses, or extends to the end of the expression.
+	// It can only be the last argument.
+	ExpressionArg
+)
+
+// Function is a user-defined function, written "name:arg1:arg2:...".
+// Arguments other than the last end at the next ":", the last one like any other literal, unless it is an expression.
+type Function struct {
+	Name string
+	Args []FunctionArgKind
+	Call func(args [][]byte) ([]byte, error)
+}
+
+// FunctionNamePattern is the regular expression of function names.
+const FunctionNamePattern = `[A-Za-z][A-Za-z0-9_.-]*`
+
+var functionNameRegexp = regexp.MustCompile(`^` + FunctionNamePattern + `$`)
+
+// RegisterFunction makes a function available to all expressions evaluated by the interpreter.
+func (ei *ExprInterpreter) RegisterFunction(function *Function) error {
+	if !functionNameRegexp.MatchString(function.Name) {
+		return fmt.Errorf("invalid function name: %q", function.Name)
+	}
+	prefix := function.Name + ":"
+	if containsString(Prefixes(), prefix) {
+		return fmt.Errorf("function %s is built in", function.Name)
+	}
+	if _, registered := ei.functions[prefix]; registered {
+		return fmt.Errorf("function %s is already registered", function.Name)
+	}
+	if len(function.Args) == 0 {
+		return fmt.Errorf("function %s must have at least one argument", function.Name)
+	}
+	for i, kind := range function.Args {
+		if kind == ExpressionArg && i != len(function.Args)-1 {
+			return fmt.Errorf("function %s: only the last argument can be an expression", function.Name)
+		}
+	}
+	if function.Call == nil {
+		return errors.New("function implementation not provided")
+	}
+
+	if ei.functions == nil {
+		ei.functions = make(map[string]*Function)
+	}
+	ei.functions[prefix] = function
+	return nil
+}
+
+// FunctionPrefixes yields the prefixes of the registered functions, sorted.
+func (ei *ExprInterpreter) FunctionPrefixes() []string {
+	var prefixes []string
+	for prefix := range ei.functions {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// callFunction evaluates the arguments of a registered function call, then calls it.
+func (ei *ExprInterpreter) callFunction(expr *Expr, function *Function) ([]byte, error) {
+	var args [][]byte
+	for i, kind := range function.Args {
+		part := expr.Parts[i]
+		switch kind {
+		case StringArg:
+			args = append(args, []byte(part.Arg))
+		case NumberArg:
+			if len(part.Arg) == 0 {
+				return []byte{}, &ExpressionError{Offset: part.Offset, Err: errors.New("missing number")}
+			}
+			arg, err := ei.expandArgument(part.Arg, ei.resolving)
+			if err != nil {
+				return []byte{}, &ExpressionError{Offset: part.Offset, Err: err}
+			}
+			number, err := ei.interpretNumber(arg, 0)
+			if err != nil {
+				return []byte{}, &ExpressionError{Offset: part.Offset, Err: err}
+			}
+			args = append(args, number)
+		default:
+			value, err := ei.evaluate(part)
+			if err != nil {
+				return []byte{}, err
+			}
+			args = append(args, value)
+		}
+	}
+
+	result, err := function.Call(args)
+	if err != nil {
+		return []byte{}, &ExpressionError{Offset: expr.Offset, Err: fmt.Errorf("%s: %w", function.Name, err)}
+	}
+	return result, nil
+}
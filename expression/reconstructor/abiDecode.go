@@ -0,0 +1,563 @@
+package scenexpressionreconstructor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	twos "github.com/bhagyaraj1208117/andes-components-big-int/twos-complement"
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+)
+
+// ReconstructTyped decodes a top-level encoded value according to an ABI type.
+//
+// The result is a structured expression, which yields the same bytes when interpreted with InterpretSubTree:
+// - numbers, bools, buffers and addresses are value expressions, with fixed width prefixes when nested, e.g. "u64:5";
+// - structs are maps from field name to value, or lists of single field maps if the fields are not declared in key order,
+// since InterpretSubTree concatenates map values sorted by key;
+// - enums and options are maps from the variant name to the discriminant, followed by the fields in a list;
+// - lists and tuples are lists, nested lists are preceded by their length.
+// Values that do not decode as the given type are returned in hex.
+func (er *ExprReconstructor) ReconstructTyped(value []byte, typeName string, abi *ei.ABI) oj.OJsonObject {
+	result, err := er.decodeTyped(value, typeName, abi)
+	if err != nil {
+		return stringToOJ(hexExpression(value))
+	}
+	return result
+}
+
+func (er *ExprReconstructor) decodeTyped(value []byte, typeName string, abi *ei.ABI) (oj.OJsonObject, error) {
+	tn, err := ei.ParseTypeName(typeName)
+	if err != nil {
+		return nil, err
+	}
+	dec := &typedDecoder{abi: abi, data: value}
+	result, err := dec.decode(tn, false)
+	if err != nil {
+		return nil, err
+	}
+	if dec.pos != len(dec.data) {
+		return nil, fmt.Errorf("%d bytes left after decoding %s", len(dec.data)-dec.pos, typeName)
+	}
+
+	// some values have several encodings, only the canonical one is reconstructed
+	interpreter := ei.ExprInterpreter{}
+	reencoded, err := interpreter.InterpretSubTree(result)
+	if err != nil || !bytes.Equal(reencoded, value) {
+		return nil, fmt.Errorf("value is not a canonical encoding of %s", typeName)
+	}
+	return result, nil
+}
+
+// ReconstructOutputs decodes the results of an endpoint, according to its outputs.
+// Results beyond the declared outputs are returned in hex.
+func (er *ExprReconstructor) ReconstructOutputs(values [][]byte, outputs []*ei.ABIParam, abi *ei.ABI) []oj.OJsonObject {
+	types := expandTypes(outputs, len(values))
+	var result []oj.OJsonObject
+	for i, value := range values {
+		if i < len(types) {
+			result = append(result, er.ReconstructTyped(value, types[i], abi))
+		} else {
+			result = append(result, stringToOJ(hexExpression(value)))
+		}
+	}
+	return result
+}
+
+// ReconstructEvent decodes the topics and data of a log, based on the event identifier found in the first topic.
+// Indexed event inputs are in the following topics, the others in the data.
+func (er *ExprReconstructor) ReconstructEvent(topics [][]byte, data [][]byte, abi *ei.ABI) ([]oj.OJsonObject, []oj.OJsonObject) {
+	if len(topics) == 0 {
+		return nil, er.ReconstructOutputs(data, nil, abi)
+	}
+	var event *ei.ABIEvent
+	if abi != nil {
+		event = abi.Event(string(topics[0]))
+	}
+	if event == nil {
+		return er.ReconstructOutputs(topics, nil, abi), er.ReconstructOutputs(data, nil, abi)
+	}
+
+	var indexed, notIndexed []*ei.ABIParam
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		} else {
+			notIndexed = append(notIndexed, input)
+		}
+	}
+	reconstructedTopics := []oj.OJsonObject{stringToOJ(bufferExpression(topics[0]))}
+	reconstructedTopics = append(reconstructedTopics, er.ReconstructOutputs(topics[1:], indexed, abi)...)
+	return reconstructedTopics, er.ReconstructOutputs(data, notIndexed, abi)
+}
+
+// ReconstructStorage decodes a storage entry, using the storage mappers declared in the ABI.
+// The key starts with the mapper name, followed by the nested encoding of the mapper keys.
+// The key is reconstructed as a single expression, since it is used as a map key.
+func (er *ExprReconstructor) ReconstructStorage(key []byte, value []byte, abi *ei.ABI) (string, oj.OJsonObject) {
+	var mappers []*ei.ABIStorageMapper
+	if abi != nil {
+		mappers = abi.Storage
+	}
+	for _, mapper := range mappers {
+		if !bytes.HasPrefix(key, []byte(mapper.Name)) {
+			continue
+		}
+		keyExpression, err := decodeStorageKey(key, mapper, abi)
+		if err != nil {
+			continue
+		}
+		return keyExpression, er.ReconstructTyped(value, mapper.Type, abi)
+	}
+	return bufferExpression(key), stringToOJ(hexExpression(value))
+}
+
+func decodeStorageKey(key []byte, mapper *ei.ABIStorageMapper, abi *ei.ABI) (string, error) {
+	parts := []string{"str:" + ei.EscapeString(mapper.Name)}
+	dec := &typedDecoder{abi: abi, data: key, pos: len(mapper.Name)}
+	for _, keyParam := range mapper.Keys {
+		tn, err := ei.ParseTypeName(keyParam.Type)
+		if err != nil {
+			return "", err
+		}
+		keyValue, err := dec.decode(tn, true)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, leafExpressions(keyValue)...)
+	}
+	if dec.pos != len(key) {
+		return "", errors.New("unexpected bytes at the end of the storage key")
+	}
+
+	keyExpression := concatExpression(parts)
+	interpreter := ei.ExprInterpreter{}
+	reencoded, err := interpreter.InterpretString(keyExpression)
+	if err != nil || !bytes.Equal(reencoded, key) {
+		return "", errors.New("storage key is not canonical")
+	}
+	return keyExpression, nil
+}
+
+// expandTypes lists the types of count consecutive values, following multi, optional and variadic types.
+func expandTypes(params []*ei.ABIParam, count int) []string {
+	var result []string
+	for _, param := range params {
+		tn, err := ei.ParseTypeName(param.Type)
+		if err != nil {
+			return result
+		}
+		switch {
+		case (tn.Name == "optional" || tn.Name == "variadic") && len(tn.Args) == 1:
+			itemTypes := tn.Args[0].MultiTypes()
+			for i := 0; len(result) < count; i++ {
+				if tn.Name == "optional" && i == len(itemTypes) {
+					break
+				}
+				result = append(result, itemTypes[i%len(itemTypes)].String())
+			}
+		default:
+			for _, itemType := range tn.MultiTypes() {
+				result = append(result, itemType.String())
+			}
+		}
+	}
+	return result
+}
+
+// leafExpressions lists the expressions of a structured expression, in the order in which they are concatenated.
+func leafExpressions(obj oj.OJsonObject) []string {
+	switch value := obj.(type) {
+	case *oj.OJsonString:
+		return []string{value.Value}
+	case *oj.OJsonList:
+		var result []string
+		for _, item := range value.AsList() {
+			result = append(result, leafExpressions(item)...)
+		}
+		return result
+	case *oj.OJsonMap:
+		var result []string
+		for _, kvp := range value.KeyValuePairsSortedByKey() {
+			result = append(result, leafExpressions(kvp.Value)...)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// concatExpression joins expressions with "|".
+// Parts whose arguments would otherwise extend to the end of the concatenation are put in parentheses.
+func concatExpression(parts []string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if len(part) > 0 {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	for i := 0; i < len(nonEmpty)-1; i++ {
+		for _, prefix := range ei.WholeValuePrefixes() {
+			if strings.Contains(nonEmpty[i], prefix) {
+				nonEmpty[i] = "(" + nonEmpty[i] + ")"
+				break
+			}
+		}
+	}
+	return strings.Join(nonEmpty, "|")
+}
+
+func stringToOJ(str string) oj.OJsonObject {
+	return &oj.OJsonString{Value: str}
+}
+
+func hexExpression(value []byte) string {
+	if len(value) == 0 {
+		return ""
+	}
+	return "0x" + hex.EncodeToString(value)
+}
+
+// bufferExpression yields a string expression for readable text, and hex otherwise.
+func bufferExpression(value []byte) string {
+	if canInterpretAsString(value) && !strings.ContainsAny(string(value), `\()|`) {
+		return "str:" + string(value)
+	}
+	return hexExpression(value)
+}
+
+type typedDecoder struct {
+	abi  *ei.ABI
+	data []byte
+	pos  int
+}
+
+func (dec *typedDecoder) read(length int) ([]byte, error) {
+	if dec.pos+length > len(dec.data) {
+		return nil, errors.New("unexpected end of value")
+	}
+	result := dec.data[dec.pos : dec.pos+length]
+	dec.pos += length
+	return result, nil
+}
+
+func (dec *typedDecoder) readRest() []byte {
+	result := dec.data[dec.pos:]
+	dec.pos = len(dec.data)
+	return result
+}
+
+func (dec *typedDecoder) readLength() (int, error) {
+	lengthBytes, err := dec.read(4)
+	if err != nil {
+		return 0, err
+	}
+	length := big.NewInt(0).SetBytes(lengthBytes).Uint64()
+	if length > uint64(len(dec.data)) {
+		return 0, fmt.Errorf("invalid length: %d", length)
+	}
+	return int(length), nil
+}
+
+// readBuffer reads the whole rest of top-level values, or a length-prefixed buffer for nested values.
+func (dec *typedDecoder) readBuffer(nested bool) ([]byte, error) {
+	if !nested {
+		return dec.readRest(), nil
+	}
+	length, err := dec.readLength()
+	if err != nil {
+		return nil, err
+	}
+	return dec.read(length)
+}
+
+func (dec *typedDecoder) atEnd() bool {
+	return dec.pos >= len(dec.data)
+}
+
+func (dec *typedDecoder) decode(tn *ei.TypeName, nested bool) (oj.OJsonObject, error) {
+	if width, signed, isInt := ei.IntTypeWidth(tn.Name); isInt {
+		return dec.decodeInt(tn.Name, width, signed, nested)
+	}
+
+	switch {
+	case tn.Name == "bool":
+		return dec.decodeBool(nested)
+	case tn.Name == "BigUint":
+		value, err := dec.readBuffer(nested)
+		if err != nil {
+			return nil, err
+		}
+		n := big.NewInt(0).SetBytes(value)
+		if nested {
+			return stringToOJ(fmt.Sprintf("biguint:%d", n)), nil
+		}
+		return stringToOJ(n.String()), nil
+	case tn.Name == "BigInt":
+		value, err := dec.readBuffer(nested)
+		if err != nil {
+			return nil, err
+		}
+		expression := signedExpression(twos.FromBytes(value))
+		if nested {
+			return stringToOJ("nested:" + expression), nil
+		}
+		return stringToOJ(expression), nil
+	case ei.IsBytesType(tn.Name):
+		value, err := dec.readBuffer(nested)
+		if err != nil {
+			return nil, err
+		}
+		if nested {
+			return stringToOJ("nested:" + bufferExpression(value)), nil
+		}
+		return stringToOJ(bufferExpression(value)), nil
+	case ei.IsAddressType(tn.Name):
+		value, err := dec.read(32)
+		if err != nil {
+			return nil, err
+		}
+		return stringToOJ(addressExpression(value)), nil
+	case tn.Name == "Option" && len(tn.Args) == 1:
+		return dec.decodeOption(tn.Args[0], nested)
+	case ei.IsListType(tn.Name) && len(tn.Args) == 1:
+		return dec.decodeList(tn.Args[0], nested)
+	case tn.Name == "tuple":
+		return dec.decodeItems(tn.Args)
+	}
+
+	var customType *ei.ABIType
+	if dec.abi != nil {
+		customType = dec.abi.Types[tn.Name]
+	}
+	if customType == nil {
+		return nil, fmt.Errorf("unknown type: %s", tn)
+	}
+	if customType.Type == "struct" {
+		return dec.decodeFields(customType.Fields)
+	}
+	return dec.decodeEnum(customType, nested)
+}
+
+func signedExpression(n *big.Int) string {
+	if n.Sign() == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%+d", n)
+}
+
+func (dec *typedDecoder) decodeInt(typeName string, width int, signed bool, nested bool) (oj.OJsonObject, error) {
+	var value []byte
+	var err error
+	if nested {
+		value, err = dec.read(width)
+	} else {
+		value = dec.readRest()
+		if len(value) > width {
+			err = fmt.Errorf("value too long for %s", typeName)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var expression string
+	if signed {
+		expression = signedExpression(twos.FromBytes(value))
+	} else {
+		expression = big.NewInt(0).SetBytes(value).String()
+	}
+	if nested {
+		expression = typeName + ":" + strings.TrimPrefix(expression, "+")
+	}
+	return stringToOJ(expression), nil
+}
+
+func (dec *typedDecoder) decodeBool(nested bool) (oj.OJsonObject, error) {
+	var value []byte
+	var err error
+	if nested {
+		value, err = dec.read(1)
+	} else {
+		value = dec.readRest()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case nested && value[0] == 0:
+		return stringToOJ("u8:0"), nil
+	case nested && value[0] == 1:
+		return stringToOJ("u8:1"), nil
+	case len(value) == 0:
+		return stringToOJ("false"), nil
+	case len(value) == 1 && value[0] == 1:
+		return stringToOJ("true"), nil
+	default:
+		return nil, errors.New("invalid bool")
+	}
+}
+
+// discriminantExpression yields the expression of an enum discriminant.
+// Top-level unit variants are encoded as numbers, 0 being empty.
+func discriminantExpression(discriminant int, unitTopLevel bool) string {
+	if unitTopLevel {
+		return fmt.Sprintf("%d", discriminant)
+	}
+	return fmt.Sprintf("u8:%d", discriminant)
+}
+
+func variantOJ(name string, discriminant string, fields oj.OJsonObject) oj.OJsonObject {
+	variant := oj.NewMap()
+	if fields == nil {
+		variant.Put(name, stringToOJ(discriminant))
+	} else {
+		variant.Put(name, oj.NewList([]oj.OJsonObject{stringToOJ(discriminant), fields}))
+	}
+	return variant
+}
+
+func (dec *typedDecoder) decodeOption(itemType *ei.TypeName, nested bool) (oj.OJsonObject, error) {
+	if !nested && dec.atEnd() {
+		return variantOJ("None", "", nil), nil
+	}
+	discriminant, err := dec.read(1)
+	if err != nil {
+		return nil, err
+	}
+	switch discriminant[0] {
+	case 0:
+		if !nested {
+			return nil, errors.New("invalid top-level None")
+		}
+		return variantOJ("None", "u8:0", nil), nil
+	case 1:
+		item, err := dec.decode(itemType, true)
+		if err != nil {
+			return nil, err
+		}
+		return variantOJ("Some", "u8:1", item), nil
+	default:
+		return nil, fmt.Errorf("invalid option discriminant: %d", discriminant[0])
+	}
+}
+
+func (dec *typedDecoder) decodeList(itemType *ei.TypeName, nested bool) (oj.OJsonObject, error) {
+	var items []oj.OJsonObject
+	if nested {
+		length, err := dec.readLength()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < length; i++ {
+			item, err := dec.decode(itemType, true)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return oj.NewList([]oj.OJsonObject{
+			stringToOJ(fmt.Sprintf("u32:%d", length)),
+			oj.NewList(items),
+		}), nil
+	}
+
+	for !dec.atEnd() {
+		item, err := dec.decode(itemType, true)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return oj.NewList(items), nil
+}
+
+func (dec *typedDecoder) decodeItems(itemTypes []*ei.TypeName) (oj.OJsonObject, error) {
+	var items []oj.OJsonObject
+	for _, itemType := range itemTypes {
+		item, err := dec.decode(itemType, true)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return oj.NewList(items), nil
+}
+
+func (dec *typedDecoder) decodeFields(fields []*ei.ABIParam) (oj.OJsonObject, error) {
+	var names []string
+	var values []oj.OJsonObject
+	for _, field := range fields {
+		fieldType, err := ei.ParseTypeName(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		value, err := dec.decode(fieldType, true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %s: %w", field.Name, err)
+		}
+		names = append(names, field.Name)
+		values = append(values, value)
+	}
+
+	if sort.StringsAreSorted(names) {
+		fieldsMap := oj.NewMap()
+		for i, name := range names {
+			fieldsMap.Put(name, values[i])
+		}
+		return fieldsMap, nil
+	}
+
+	var fieldList []oj.OJsonObject
+	for i, name := range names {
+		fieldMap := oj.NewMap()
+		fieldMap.Put(name, values[i])
+		fieldList = append(fieldList, fieldMap)
+	}
+	return oj.NewList(fieldList), nil
+}
+
+func (dec *typedDecoder) decodeEnum(enumType *ei.ABIType, nested bool) (oj.OJsonObject, error) {
+	discriminant := 0
+	if nested || !dec.atEnd() {
+		discriminantBytes, err := dec.read(1)
+		if err != nil {
+			return nil, err
+		}
+		discriminant = int(discriminantBytes[0])
+	}
+
+	for _, variant := range enumType.Variants {
+		if variant.Discriminant != discriminant {
+			continue
+		}
+		if len(variant.Fields) == 0 {
+			return variantOJ(variant.Name, discriminantExpression(discriminant, !nested), nil), nil
+		}
+		fields, err := dec.decodeFields(variant.Fields)
+		if err != nil {
+			return nil, err
+		}
+		return variantOJ(variant.Name, discriminantExpression(discriminant, false), fields), nil
+	}
+	return nil, fmt.Errorf("unknown discriminant: %d", discriminant)
+}
+
+// addressExpression yields the address:/sc: form when it encodes the same bytes, and hex otherwise.
+func addressExpression(value []byte) string {
+	pretty := addressPretty(value)
+	if strings.HasPrefix(pretty, "address:") || strings.HasPrefix(pretty, "sc:") {
+		interpreter := ei.ExprInterpreter{}
+		reencoded, err := interpreter.InterpretString(pretty)
+		if err == nil && bytes.Equal(reencoded, value) {
+			return pretty
+		}
+	}
+	return hexExpression(value)
+}
@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	"github.com/bhagyaraj1208117/andes-core-go/core"
-	pc "github.com/bhagyaraj1208117/andes-core-go/core/pubkeyConverter"
 	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
 )
 
@@ -31,31 +30,142 @@ const (
 
 	// CodeHint hints that value should be a smart contract code, normally loaded from a file
 	CodeHint
+
+	// MoaxHint hints that value is an amount of MOAX, written in MOAX rather than in its smallest unit, e.g. "moax:1.5"
+	MoaxHint
+
+	// U8Hint hints at a fixed width unsigned number, e.g. "u8:5"
+	U8Hint
+
+	// U16Hint hints at a fixed width unsigned number, e.g. "u16:5"
+	U16Hint
+
+	// U32Hint hints at a fixed width unsigned number, e.g. "u32:5"
+	U32Hint
+
+	// U64Hint hints at a fixed width unsigned number, e.g. "u64:5"
+	U64Hint
+
+	// I8Hint hints at a fixed width signed number, e.g. "i8:-5"
+	I8Hint
+
+	// I16Hint hints at a fixed width signed number, e.g. "i16:-5"
+	I16Hint
+
+	// I32Hint hints at a fixed width signed number, e.g. "i32:-5"
+	I32Hint
+
+	// I64Hint hints at a fixed width signed number, e.g. "i64:-5"
+	I64Hint
+
+	// SignedNumberHint hints at a signed number of minimal length, e.g. "-5" or "+255"
+	SignedNumberHint
+
+	// BigUintHint hints at a length-prefixed unsigned number, e.g. "biguint:5"
+	BigUintHint
+
+	// BoolHint hints at a boolean, "true" or "false"
+	BoolHint
+
+	// NestedStrHint hints at a length-prefixed string, e.g. "nested:str:abc"
+	NestedStrHint
+
+	// NestedHint hints at length-prefixed bytes, e.g. "nested:0x0102"
+	NestedHint
 )
 
 const maxBytesInterpretedAsNumber = 15
 
+// AddressFormat is a way of writing addresses.
+type AddressFormat int
+
+const (
+	// SCAddressFormat writes smart contract addresses as "sc:name", if that yields the same bytes.
+	SCAddressFormat AddressFormat = iota
+
+	// AccountAddressFormat writes addresses as "address:name", if that yields the same bytes.
+	AccountAddressFormat
+
+	// Bech32AddressFormat writes addresses as "bech32:<hrp>1...", if they have the length of the AddressConverter.
+	Bech32AddressFormat
+
+	// HexAddressFormat writes addresses as "0x...", it applies to all addresses.
+	HexAddressFormat
+)
+
 // ExprReconstructor is a component that attempts to convert raw bytes to a human-readable format.
 type ExprReconstructor struct {
+	// Bech32Addr is the same as AddressFormats: {Bech32AddressFormat}.
 	Bech32Addr bool
+
+	// AddressFormats is the policy for writing addresses: the first format that applies is used.
+	// Addresses that no format applies to are written as unknown values.
+	// By default, addresses are written as "sc:" or "address:", or hex along with the address form.
+	AddressFormats []AddressFormat
+
+	// AddressConverter encodes bech32 addresses. The default HRP is "moa", for 32 byte addresses.
+	AddressConverter ei.AddressConverter
+
+	functions []*registeredFunction
+}
+
+// ReverseFunction recognizes values produced by a user-defined function of the interpreter,
+// and yields the arguments of the call, e.g. "TICKER" for "dctid:TICKER".
+// It returns false for values it does not recognize.
+type ReverseFunction func(value []byte, hint ExprReconstructorHint) (args []string, ok bool)
+
+type registeredFunction struct {
+	name    string
+	reverse ReverseFunction
+}
+
+// RegisterFunction makes the reconstructor try a user-defined function, before the built-in formats.
+// Functions are tried in the order in which they were registered.
+func (er *ExprReconstructor) RegisterFunction(name string, reverse ReverseFunction) {
+	er.functions = append(er.functions, &registeredFunction{name: name, reverse: reverse})
 }
 
 // Reconstruct will return the string representation of the provided value
 func (er *ExprReconstructor) Reconstruct(value []byte, hint ExprReconstructorHint) string {
+	for _, function := range er.functions {
+		if args, ok := function.reverse(value, hint); ok {
+			return function.name + ":" + strings.Join(args, ":")
+		}
+	}
+
 	switch hint {
 	case NumberHint:
 		return fmt.Sprintf("%d", big.NewInt(0).SetBytes(value))
 	case StrHint:
-		return fmt.Sprintf("str:%s", string(value))
+		return fmt.Sprintf("str:%s", ei.EscapeString(string(value)))
 	case AddressHint:
-		return addressPretty(value, er.Bech32Addr)
+		return er.reconstructAddress(value)
 	case CodeHint:
 		return codePretty(value)
+	case MoaxHint:
+		return er.ReconstructDenominated(value, "moax")
+	case U8Hint, U16Hint, U32Hint, U64Hint, I8Hint, I16Hint, I32Hint, I64Hint,
+		SignedNumberHint, BigUintHint, BoolHint, NestedStrHint, NestedHint:
+		if pretty, ok := typedPretty(value, hint); ok {
+			return pretty
+		}
+		return unknownByteArrayPretty(value)
 	default:
 		return unknownByteArrayPretty(value)
 	}
 }
 
+// ReconstructDenominated writes an amount in the given denomination, e.g. "moax:1.5".
+// Unknown denominations yield the plain number.
+func (er *ExprReconstructor) ReconstructDenominated(value []byte, denominationName string) string {
+	for _, denomination := range ei.Denominations() {
+		if denomination.Name == denominationName {
+			return denominatedPretty(big.NewInt(0).SetBytes(value), denomination)
+		}
+	}
+	return er.Reconstruct(value, NumberHint)
+}
+
 // ReconstructFromBigInt will return the string of the provided big int
 func (er *ExprReconstructor) ReconstructFromBigInt(value *big.Int) string {
 	return er.Reconstruct(value.Bytes(), NumberHint)
@@ -96,13 +206,67 @@ func unknownByteArrayPretty(bytes []byte) string {
 	return fmt.Sprintf("0x%s (str:%s)", hex.EncodeToString(bytes), strconv.Quote(string(bytes)))
 }
 
-func addressPretty(value []byte, bech32Addr bool) string {
-	if len(value) != 32 {
-		return unknownByteArrayPretty(value)
+func (er *ExprReconstructor) getAddressConverter() ei.AddressConverter {
+	if er.AddressConverter == nil {
+		return ei.DefaultAddressConverter()
+	}
+	return er.AddressConverter
+}
+
+func (er *ExprReconstructor) reconstructAddress(value []byte) string {
+	formats := er.AddressFormats
+	if len(formats) == 0 && er.Bech32Addr {
+		formats = []AddressFormat{Bech32AddressFormat}
+	}
+	if len(formats) == 0 || len(value) == 0 {
+		return addressPretty(value)
+	}
+	for _, format := range formats {
+		if pretty, applies := er.tryAddressFormat(value, format); applies {
+			return pretty
+		}
 	}
+	return unknownByteArrayPretty(value)
+}
+
+func (er *ExprReconstructor) tryAddressFormat(value []byte, format AddressFormat) (string, bool) {
+	switch format {
+	case SCAddressFormat:
+		pretty := addressPretty(value)
+		if !strings.HasPrefix(pretty, "sc:") {
+			return "", false
+		}
+		// "sc:" takes the VM type from the interpreter
+		var vmType [core.VMTypeLen]byte
+		copy(vmType[:], value[ei.SCAddressReservedPrefixLength-core.VMTypeLen:])
+		return pretty, roundTrips(&ei.ExprInterpreter{VMType: &vmType}, pretty, value)
+	case AccountAddressFormat:
+		pretty := addressPretty(value)
+		if !strings.HasPrefix(pretty, "address:") {
+			return "", false
+		}
+		return pretty, roundTrips(&ei.ExprInterpreter{}, pretty, value)
+	case Bech32AddressFormat:
+		encoded, err := er.getAddressConverter().Encode(value)
+		if err != nil {
+			return "", false
+		}
+		return "bech32:" + encoded, true
+	case HexAddressFormat:
+		return "0x" + hex.EncodeToString(value), true
+	default:
+		return "", false
+	}
+}
 
-	if bech32Addr {
-		return bech32Pretty(value)
+func roundTrips(interpreter *ei.ExprInterpreter, expression string, value []byte) bool {
+	reencoded, err := interpreter.InterpretString(expression)
+	return err == nil && bytes.Equal(reencoded, value)
+}
+
+func addressPretty(value []byte) string {
+	if len(value) != 32 {
+		return unknownByteArrayPretty(value)
 	}
 
 	// smart contract addresses
@@ -151,6 +315,16 @@ func canInterpretAsString(bytes []byte) bool {
 	return true
 }
 
+func denominatedPretty(amount *big.Int, denomination *ei.Denomination) string {
+	unit := big.NewInt(0).Exp(big.NewInt(10), big.NewInt(int64(denomination.Decimals)), nil)
+	integerPart, fractionalPart := big.NewInt(0).QuoRem(amount, unit, big.NewInt(0))
+	if fractionalPart.Sign() == 0 {
+		return fmt.Sprintf("%s%d", denomination.Prefix(), integerPart)
+	}
+	fractionalStr := fmt.Sprintf("%0*d", denomination.Decimals, fractionalPart)
+	return fmt.Sprintf("%s%d.%s", denomination.Prefix(), integerPart, strings.TrimRight(fractionalStr, "0"))
+}
+
 func codePretty(bytes []byte) string {
 	if len(bytes) == 0 {
 		return ""
@@ -163,22 +337,3 @@ func codePretty(bytes []byte) string {
 
 	return fmt.Sprintf("0x%s", encoded)
 }
-
-func bech32Pretty(pkBytes []byte) string {
-	if len(pkBytes) == 0 {
-		return ""
-	}
-	addressLen := 32
-	bpc, _ := pc.NewBech32PubkeyConverter(addressLen, core.DefaultAddressPrefix)
-	encoded, err := bpc.Encode(pkBytes)
-
-	if err != nil {
-		return ""
-	}
-
-	if len(encoded) > 20 {
-		return fmt.Sprintf("bech32:%s", encoded[:62])
-	}
-
-	return fmt.Sprintf("bech32:%s", encoded)
-}
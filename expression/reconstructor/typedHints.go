@@ -0,0 +1,156 @@
+package scenexpressionreconstructor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	twos "github.com/bhagyaraj1208117/andes-components-big-int/twos-complement"
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+)
+
+const lengthPrefixSize = 4
+
+type fixedWidthHint struct {
+	prefix string
+	width  int
+	signed bool
+}
+
+var fixedWidthHints = map[ExprReconstructorHint]fixedWidthHint{
+	U8Hint:  {prefix: "u8:", width: 1},
+	U16Hint: {prefix: "u16:", width: 2},
+	U32Hint: {prefix: "u32:", width: 4},
+	U64Hint: {prefix: "u64:", width: 8},
+	I8Hint:  {prefix: "i8:", width: 1, signed: true},
+	I16Hint: {prefix: "i16:", width: 2, signed: true},
+	I32Hint: {prefix: "i32:", width: 4, signed: true},
+	I64Hint: {prefix: "i64:", width: 8, signed: true},
+}
+
+// ReconstructConcat writes a value made of consecutive segments, one per hint, e.g. "u32:5|i8:-1|biguint:7".
+// Only the last segment can have a hint without a fixed or length-prefixed width, such as NumberHint or StrHint.
+// Values that cannot be split according to the hints are written as unknown values.
+func (er *ExprReconstructor) ReconstructConcat(value []byte, hints ...ExprReconstructorHint) string {
+	if len(hints) == 1 {
+		return er.Reconstruct(value, hints[0])
+	}
+	parts := make([]string, 0, len(hints))
+	rest := value
+	for i, hint := range hints {
+		length, known := er.segmentLength(rest, hint)
+		if !known {
+			if i < len(hints)-1 {
+				return unknownByteArrayPretty(value)
+			}
+			length = len(rest)
+		}
+		if length > len(rest) {
+			return unknownByteArrayPretty(value)
+		}
+		parts = append(parts, er.segmentExpression(rest[:length], hint))
+		rest = rest[length:]
+	}
+	if len(rest) > 0 {
+		return unknownByteArrayPretty(value)
+	}
+	expression := concatExpression(parts)
+	if !roundTrips(&ei.ExprInterpreter{}, expression, value) {
+		return unknownByteArrayPretty(value)
+	}
+	return expression
+}
+
+// segmentLength yields the length of a segment at the start of the value, if the hint determines it.
+func (er *ExprReconstructor) segmentLength(value []byte, hint ExprReconstructorHint) (int, bool) {
+	if fixed, isFixed := fixedWidthHints[hint]; isFixed {
+		return fixed.width, true
+	}
+	switch hint {
+	case AddressHint:
+		return er.getAddressConverter().Len(), true
+	case BigUintHint, NestedStrHint, NestedHint:
+		if len(value) < lengthPrefixSize {
+			return 0, false
+		}
+		return lengthPrefixSize + int(binary.BigEndian.Uint32(value[:lengthPrefixSize])), true
+	default:
+		return 0, false
+	}
+}
+
+// segmentExpression is like Reconstruct, but always yields a valid expression, to be used in a concatenation.
+func (er *ExprReconstructor) segmentExpression(segment []byte, hint ExprReconstructorHint) string {
+	switch hint {
+	case NoHint, CodeHint:
+		return hexExpression(segment)
+	case AddressHint:
+		return addressExpression(segment)
+	default:
+		if pretty, ok := typedPretty(segment, hint); ok {
+			return pretty
+		}
+		if hint == NumberHint || hint == StrHint || hint == MoaxHint {
+			return er.Reconstruct(segment, hint)
+		}
+		return hexExpression(segment)
+	}
+}
+
+// typedPretty writes a value according to a fixed width, signed, length-prefixed or boolean hint.
+// It fails if the expression would not yield the same bytes.
+func typedPretty(value []byte, hint ExprReconstructorHint) (string, bool) {
+	var expression string
+	if fixed, isFixed := fixedWidthHints[hint]; isFixed {
+		if len(value) != fixed.width {
+			return "", false
+		}
+		if fixed.signed {
+			expression = fmt.Sprintf("%s%d", fixed.prefix, twos.FromBytes(value))
+		} else {
+			expression = fmt.Sprintf("%s%d", fixed.prefix, big.NewInt(0).SetBytes(value))
+		}
+	} else {
+		switch hint {
+		case SignedNumberHint:
+			expression = signedExpression(twos.FromBytes(value))
+		case BoolHint:
+			switch {
+			case len(value) == 0:
+				expression = "false"
+			case len(value) == 1 && value[0] == 1:
+				expression = "true"
+			default:
+				return "", false
+			}
+		case BigUintHint, NestedStrHint, NestedHint:
+			payload, ok := nestedPayload(value)
+			if !ok {
+				return "", false
+			}
+			switch hint {
+			case BigUintHint:
+				expression = fmt.Sprintf("biguint:%d", big.NewInt(0).SetBytes(payload))
+			case NestedStrHint:
+				expression = "nested:str:" + ei.EscapeString(string(payload))
+			default:
+				expression = "nested:" + hexExpression(payload)
+			}
+		default:
+			return "", false
+		}
+	}
+	return expression, roundTrips(&ei.ExprInterpreter{}, expression, value)
+}
+
+// nestedPayload yields the contents of a value with a 4 byte length prefix, which must cover the rest of the value.
+func nestedPayload(value []byte) ([]byte, bool) {
+	if len(value) < lengthPrefixSize {
+		return nil, false
+	}
+	length := binary.BigEndian.Uint32(value[:lengthPrefixSize])
+	if uint64(length) != uint64(len(value)-lengthPrefixSize) {
+		return nil, false
+	}
+	return value[lengthPrefixSize:], true
+}
@@ -7,6 +7,7 @@ require (
 	github.com/bhagyaraj1208117/andes-components-big-int v1.0.0
 	github.com/bhagyaraj1208117/andes-core-go v1.2.13
 	github.com/bhagyaraj1208117/andes-vm-common-go v1.5.2
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1
 	github.com/stretchr/testify v1.8.4
 	golang.org/x/crypto v0.18.0
 )
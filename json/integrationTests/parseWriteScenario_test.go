@@ -1,12 +1,20 @@
 package scenjsontest
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
 	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	mc "github.com/bhagyaraj1208117/andes-scenario-go/controller"
+	mer "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
 	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
 	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
 	mjwrite "github.com/bhagyaraj1208117/andes-scenario-go/json/write"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,3 +37,420 @@ func TestWriteScenario(t *testing.T) {
 
 	require.Equal(t, contents, []byte(serialized))
 }
+
+func TestParseScenarioStream(t *testing.T) {
+	contents, err := loadExampleFile("example.scen.json")
+	require.Nil(t, err)
+
+	p := mjparse.NewParser(
+		fr.NewDefaultFileResolver().ReplacePath(
+			"smart-contract.wasm",
+			"exampleFile.txt"))
+
+	scenario, parseErr := p.ParseScenarioReader(bytes.NewReader(contents))
+	require.Nil(t, parseErr)
+	require.Equal(t, contents, []byte(mjwrite.ScenarioToJSONString(scenario)))
+
+	var streamedSteps []mj.Step
+	streamed, parseErr := p.ParseScenarioStream(bytes.NewReader(contents), func(step mj.Step) error {
+		streamedSteps = append(streamedSteps, step)
+		return nil
+	})
+	require.Nil(t, parseErr)
+	require.Empty(t, streamed.Steps)
+	require.Equal(t, scenario.Steps, streamedSteps)
+
+	stopErr := errors.New("stop")
+	_, parseErr = p.ParseScenarioStream(bytes.NewReader(contents), func(step mj.Step) error {
+		return stopErr
+	})
+	require.Equal(t, stopErr, parseErr)
+}
+
+func TestWriteScenarioJSONC(t *testing.T) {
+	contents := `{
+    // comments are kept
+    "name": "jsonc",
+    "steps": [
+        {
+            "step": "scCall",
+            "id": "1",
+            "tx": {
+                "from": "address:owner", /* sender */
+                "to": "sc:adder",
+                "function": "add",
+                "arguments": [ "5" ],
+                "gasLimit": "5,000,000",
+                "gasPrice": "0",
+            },
+            "expect": {
+                "out": [],
+                "status": "",
+            }
+        }
+    ]
+}
+`
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	p.AllowJSONC = true
+	scenario, parseErr := p.ParseScenarioFile([]byte(contents))
+	require.Nil(t, parseErr)
+	require.Equal(t, contents, mjwrite.ScenarioToJSONString(scenario))
+
+	// a mechanical edit only changes one line
+	scenario.Steps[0].(*mj.TxStep).Tx.Function = "sub"
+	require.Equal(t,
+		strings.Replace(contents, `"function": "add"`, `"function": "sub"`, 1),
+		mjwrite.ScenarioToJSONString(scenario))
+}
+
+func TestParseScenarioWithABI(t *testing.T) {
+	contents := `{
+    "name": "abi",
+    "steps": [
+        {
+            "step": "scCall",
+            "id": "1",
+            "tx": {
+                "from": "address:owner",
+                "to": "sc:adder",
+                "function": "add",
+                "abi": "adder.abi.json",
+                "arguments": [
+                    {
+                        "value": "5",
+                        "weight": "None"
+                    }
+                ],
+                "gasLimit": "5,000,000",
+                "gasPrice": "0"
+            }
+        }
+    ]
+}
+`
+	abi := `{
+    "endpoints": [
+        { "name": "add", "inputs": [ { "name": "term", "type": "Term" } ] }
+    ],
+    "types": {
+        "Term": {
+            "type": "struct",
+            "fields": [
+                { "name": "weight", "type": "Option<u8>" },
+                { "name": "value", "type": "BigUint" }
+            ]
+        }
+    }
+}`
+	dir := t.TempDir()
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "adder.abi.json"), []byte(abi), 0644))
+
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	p.ExprInterpreter.FileResolver.SetContext(filepath.Join(dir, "adder.scen.json"))
+	scenario, parseErr := p.ParseScenarioFile([]byte(contents))
+	require.Nil(t, parseErr)
+	tx := scenario.Steps[0].(*mj.TxStep).Tx
+	require.Equal(t, "adder.abi.json", tx.ABIPath)
+	require.Equal(t, []byte{0, 0, 0, 0, 1, 5}, tx.Arguments[0].Value)
+	require.Equal(t, contents, mjwrite.ScenarioToJSONString(scenario))
+
+	_, parseErr = p.ParseScenarioFile([]byte(strings.Replace(contents, `"function": "add"`, `"function": "sub"`, 1)))
+	require.ErrorContains(t, parseErr, "endpoint sub not found in abi adder.abi.json")
+	require.Equal(t, "steps[0].tx.arguments", parseErr.(*mjparse.ParseError).KeyPath)
+}
+
+func TestParseScenarioWithConstants(t *testing.T) {
+	contents := `{
+    "name": "constants",
+    "constants": {
+        "owner": "address:owner",
+        "amount": "1,000",
+        "double": "${amount} * 2"
+    },
+    "steps": [
+        {
+            "step": "transfer",
+            "id": "1",
+            "tx": {
+                "from": "var:owner",
+                "to": "sc:adder",
+                "moaxValue": "${double}"
+            }
+        },
+        {
+            "step": "externalSteps",
+            "path": "steps.json"
+        }
+    ]
+}
+`
+	steps := `{
+    "constants": {
+        "amount": "5"
+    },
+    "steps": [
+        {
+            "step": "transfer",
+            "id": "2",
+            "tx": {
+                "from": "${owner}",
+                "to": "sc:adder",
+                "moaxValue": "var:double"
+            }
+        }
+    ]
+}
+`
+	dir := t.TempDir()
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "steps.json"), []byte(steps), 0644))
+
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	p.ExprInterpreter.FileResolver.SetContext(filepath.Join(dir, "main.scen.json"))
+	scenario, parseErr := p.ParseScenarioFile([]byte(contents))
+	require.Nil(t, parseErr)
+	require.Nil(t, p.ExprInterpreter.Constants)
+	require.Equal(t, []*mj.Constant{
+		{Name: "owner", Value: "address:owner"},
+		{Name: "amount", Value: "1,000"},
+		{Name: "double", Value: "${amount} * 2"},
+	}, scenario.Constants)
+	tx := scenario.Steps[0].(*mj.TxStep).Tx
+	owner, err := p.ExprInterpreter.InterpretString("address:owner")
+	require.Nil(t, err)
+	require.Equal(t, owner, tx.From.Value)
+	require.Equal(t, "2000", tx.MOAXValue.Value.String())
+	require.Equal(t, contents, mjwrite.ScenarioToJSONString(scenario))
+
+	// the steps file inherits the constants, and can shadow them
+	externalSteps, err := mc.ParseExternalSteps(p, scenario.Steps[1].(*mj.ExternalStepsStep))
+	require.Nil(t, err)
+	tx = externalSteps.Steps[0].(*mj.TxStep).Tx
+	require.Equal(t, owner, tx.From.Value)
+	require.Equal(t, "10", tx.MOAXValue.Value.String())
+	require.Equal(t, steps, mjwrite.ScenarioToJSONString(externalSteps))
+
+	_, parseErr = p.ParseScenarioFile([]byte(strings.Replace(contents, `"var:owner"`, `"var:other"`, 1)))
+	require.ErrorContains(t, parseErr, "unknown constant: other")
+	require.Equal(t, "steps[0].tx.from", parseErr.(*mjparse.ParseError).KeyPath)
+
+	_, parseErr = p.ParseScenarioFile([]byte(strings.Replace(contents, `"owner": "address:owner"`, `"owner": "var:double2"`, 1)))
+	require.ErrorContains(t, parseErr, "unknown constant: double2")
+	require.Equal(t, "constants.owner", parseErr.(*mjparse.ParseError).KeyPath)
+
+	_, parseErr = p.ParseScenarioStream(strings.NewReader(`{"steps": [], "constants": {}}`), nil)
+	require.ErrorContains(t, parseErr, "constants must be declared before the steps")
+}
+
+func TestParseScenarioDeriveNewAddresses(t *testing.T) {
+	contents := `{
+    "name": "derive",
+    "steps": [
+        {
+            "step": "setState",
+            "accounts": {
+                "address:owner": {
+                    "nonce": "3",
+                    "balance": "0"
+                }
+            },
+            "newAddresses": [
+                {
+                    "creatorAddress": "address:owner",
+                    "creatorNonce": "4"
+                }
+            ]
+        },
+        {
+            "step": "scDeploy",
+            "id": "1",
+            "tx": {
+                "from": "address:owner",
+                "contractCode": "file:exampleFile.txt",
+                "arguments": [],
+                "gasLimit": "5,000,000",
+                "gasPrice": "0"
+            }
+        },
+        {
+            "step": "scDeploy",
+            "id": "2",
+            "tx": {
+                "from": "address:owner",
+                "contractCode": "file:exampleFile.txt",
+                "arguments": [],
+                "gasLimit": "5,000,000",
+                "gasPrice": "0"
+            }
+        }
+    ]
+}
+`
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	p.DeriveNewAddresses = true
+	scenario, parseErr := p.ParseScenarioFile([]byte(contents))
+	require.Nil(t, parseErr)
+
+	mock := scenario.Steps[0].(*mj.SetStateStep).NewAddressMocks[0]
+	expected, err := p.ExprInterpreter.InterpretString("sc-derived:address:owner:4")
+	require.Nil(t, err)
+	require.Equal(t, expected, mock.NewAddress.Value)
+	require.True(t, mock.Derived)
+
+	// the second deploy is covered by the newAddresses entry, the first one is not
+	require.Len(t, scenario.Steps, 4)
+	derivedStep := scenario.Steps[1].(*mj.SetStateStep)
+	require.True(t, derivedStep.Derived)
+	require.Len(t, derivedStep.NewAddressMocks, 1)
+	derived := derivedStep.NewAddressMocks[0]
+	require.True(t, derived.Derived)
+	require.Equal(t, uint64(3), derived.CreatorNonce.Value)
+	require.Equal(t, "sc-derived:address:owner:3", derived.NewAddress.Original)
+	expected, err = p.ExprInterpreter.InterpretString("sc-derived:address:owner:3")
+	require.Nil(t, err)
+	require.Equal(t, expected, derived.NewAddress.Value)
+	require.Equal(t, "1", scenario.Steps[2].(*mj.TxStep).TxIdent)
+	require.Equal(t, "2", scenario.Steps[3].(*mj.TxStep).TxIdent)
+
+	require.Equal(t, contents, mjwrite.ScenarioToJSONString(scenario))
+
+	var streamedSteps []mj.Step
+	_, parseErr = p.ParseScenarioStream(strings.NewReader(contents), func(step mj.Step) error {
+		streamedSteps = append(streamedSteps, step)
+		return nil
+	})
+	require.Nil(t, parseErr)
+	require.Equal(t, scenario.Steps, streamedSteps)
+
+	p.DeriveNewAddresses = false
+	scenario, parseErr = p.ParseScenarioFile([]byte(contents))
+	require.Nil(t, parseErr)
+	require.Empty(t, scenario.Steps[0].(*mj.SetStateStep).NewAddressMocks[0].NewAddress.Value)
+	require.Len(t, scenario.Steps, 3)
+}
+
+func TestWriteNewAddressMockValue(t *testing.T) {
+	// mocks built in code only have a value
+	creator := bytes.Repeat([]byte{1}, 32)
+	newAddress := bytes.Repeat([]byte{2}, 32)
+	scenario := &mj.Scenario{Steps: []mj.Step{&mj.SetStateStep{
+		NewAddressMocks: []*mj.NewAddressMock{{
+			CreatorAddress: mj.JSONBytesFromString{Value: creator},
+			CreatorNonce:   mj.JSONUint64{Value: 3},
+			NewAddress:     mj.JSONBytesFromString{Value: newAddress},
+		}},
+	}}}
+	serialized := mjwrite.ScenarioToJSONString(scenario)
+	require.Contains(t, serialized, `"newAddress": "0x`+hex.EncodeToString(newAddress)+`"`)
+
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	parsed, parseErr := p.ParseScenarioFile([]byte(serialized))
+	require.Nil(t, parseErr)
+	require.Equal(t, newAddress, parsed.Steps[0].(*mj.SetStateStep).NewAddressMocks[0].NewAddress.Value)
+}
+
+func TestParseScenarioDeriveNewAddressesUnknownNonce(t *testing.T) {
+	deploy := `{"step": "scDeploy", "tx": {"from": "address:owner", "contractCode": "", "gasLimit": "1", "gasPrice": "0"}}`
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	p.DeriveNewAddresses = true
+
+	// e.g. a steps file, the owner could have sent transactions before it
+	scenario, parseErr := p.ParseScenarioFile([]byte(`{"steps": [` + deploy + `]}`))
+	require.Nil(t, parseErr)
+	require.Len(t, scenario.Steps, 1)
+
+	// the transactions of external steps are not followed
+	scenario, parseErr = p.ParseScenarioFile([]byte(`{"steps": [
+		{"step": "setState", "accounts": {"address:owner": {"nonce": "2"}}},
+		` + deploy + `,
+		{"step": "externalSteps", "path": "other.steps.json"},
+		` + deploy + `
+	]}`))
+	require.Nil(t, parseErr)
+	require.Len(t, scenario.Steps, 5)
+	require.Equal(t, "sc-derived:address:owner:2", scenario.Steps[1].(*mj.SetStateStep).NewAddressMocks[0].NewAddress.Original)
+	require.IsType(t, &mj.TxStep{}, scenario.Steps[2])
+	require.IsType(t, &mj.ExternalStepsStep{}, scenario.Steps[3])
+	require.IsType(t, &mj.TxStep{}, scenario.Steps[4])
+
+	// invalid transactions are kept when collecting all errors
+	scenario, diagnostics := p.ParseScenarioFileDiagnostics([]byte(`{"steps": [
+		{"step": "setState", "accounts": {"address:owner": {}}},
+		{"step": "scDeploy", "tx": 5}
+	]}`))
+	require.True(t, mjparse.HasErrors(diagnostics))
+	require.Nil(t, scenario.Steps[1].(*mj.TxStep).Tx)
+}
+
+func TestWriteScenarioWithHints(t *testing.T) {
+	owner := []byte("owner___________________________")
+	adder := append(make([]byte, 10), []byte("adder_________________")...)
+	tx := &mj.Transaction{
+		Type:     mj.ScCall,
+		From:     mj.NewJSONBytesFromString(owner, ""),
+		To:       mj.NewJSONBytesFromString(adder, ""),
+		Function: "add",
+		Arguments: []mj.JSONBytesFromTree{
+			{Value: []byte{0xff, 0xff, 0xff, 0xfb}},
+			{Value: []byte{0, 0, 0, 2, 0x03, 0xe8, 1}},
+			{Value: []byte{0, 5}},
+		},
+		GasLimit: mj.JSONUint64{Value: 5000000, Original: "5000000"},
+		GasPrice: mj.JSONUint64{Value: 0, Original: "0"},
+	}
+	scenario := &mj.Scenario{
+		CheckGas: true,
+		Steps:    []mj.Step{&mj.TxStep{Tx: tx}},
+	}
+
+	plain := mjwrite.ScenarioToJSONString(scenario)
+	require.Contains(t, plain, `"0xfffffffb"`)
+
+	serialized, err := mjwrite.ScenarioToJSONStringWithHints(scenario, mjwrite.FieldHints{
+		"steps[*].tx.from":            {mer.AddressHint},
+		"steps[0].tx.to":              {mer.AddressHint},
+		"steps[*].tx.arguments[0]":    {mer.I32Hint},
+		"steps[*].tx.arguments[1]":    {mer.BigUintHint, mer.BoolHint},
+		`steps[*]["tx"].arguments[2]`: {mer.NumberHint},
+	})
+	require.Nil(t, err)
+	require.Contains(t, serialized, `"from": "address:owner"`)
+	require.Contains(t, serialized, `"to": "sc:adder"`)
+	require.Contains(t, serialized, `"i32:-5"`)
+	require.Contains(t, serialized, `"biguint:1000|true"`)
+	// "5" would not yield the leading zero
+	require.Contains(t, serialized, `"0x0005"`)
+
+	// the hinted scenario yields the same values
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	reparsed, err := p.ParseScenarioFile([]byte(serialized))
+	require.Nil(t, err)
+	reparsedTx := reparsed.Steps[0].(*mj.TxStep).Tx
+	require.Equal(t, adder, reparsedTx.To.Value)
+	for i, argument := range reparsedTx.Arguments {
+		require.Equal(t, tx.Arguments[i].Value, argument.Value)
+	}
+
+	_, err = mjwrite.ScenarioToJSONStringWithHints(scenario, mjwrite.FieldHints{"steps[x]": {mer.NumberHint}})
+	require.EqualError(t, err, "invalid key path steps[x]: invalid index x")
+}
+
+func TestParseScenarioTags(t *testing.T) {
+	contents := []byte(`{
+    "name": "tagged",
+    "tags": [
+        "dct",
+        "slow"
+    ],
+    "steps": []
+}
+`)
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	scenario, err := p.ParseScenarioFile(contents)
+	require.Nil(t, err)
+	require.Equal(t, []string{"dct", "slow"}, scenario.Tags)
+	require.Equal(t, string(contents), mjwrite.ScenarioToJSONString(scenario))
+
+	_, err = p.ParseScenarioFile([]byte(`{"tags": "dct", "steps": []}`))
+	require.ErrorContains(t, err, "bad scenario tags: not a JSON list")
+}
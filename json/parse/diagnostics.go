@@ -0,0 +1,142 @@
+package scenjsonparse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+)
+
+// Severity indicates how serious a diagnostic is.
+type Severity int
+
+const (
+	// SeverityError means that the file is invalid.
+	SeverityError Severity = iota
+
+	// SeverityWarning means that the file is valid, but likely needs attention.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic is a problem found while parsing, in collect-all-errors mode.
+type Diagnostic struct {
+	Severity Severity
+	FilePath string
+	Pos      oj.Position
+	KeyPath  string
+	Message  string
+}
+
+// String yields a message of the form "file:line:column: severity: keyPath: message".
+func (d *Diagnostic) String() string {
+	var sb strings.Builder
+	if len(d.FilePath) > 0 {
+		sb.WriteString(d.FilePath)
+		sb.WriteString(":")
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s: ", d.Pos, d.Severity))
+	if len(d.KeyPath) > 0 {
+		sb.WriteString(d.KeyPath)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(d.Message)
+	return sb.String()
+}
+
+// HasErrors returns true if any of the diagnostics is an error.
+func HasErrors(diagnostics []*Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScenarioFileDiagnostics parses a scenario without stopping at the first error.
+// It returns every problem found, along with the partially populated scenario.
+// Entries that could not be parsed are left out of the scenario.
+// The scenario is nil only if the JSON itself is malformed.
+func (p *Parser) ParseScenarioFileDiagnostics(jsonString []byte) (*mj.Scenario, []*Diagnostic) {
+	return p.collectDiagnostics(func() (*mj.Scenario, error) {
+		return p.parseScenarioFile(jsonString)
+	})
+}
+
+// ParseScenarioReaderDiagnostics is ParseScenarioFileDiagnostics for a stream,
+// which is parsed like ParseScenarioStream, without building the full JSON tree in memory.
+func (p *Parser) ParseScenarioReaderDiagnostics(reader io.Reader) (*mj.Scenario, []*Diagnostic) {
+	return p.collectDiagnostics(func() (*mj.Scenario, error) {
+		return p.parseScenarioStream(reader, nil)
+	})
+}
+
+func (p *Parser) collectDiagnostics(parse func() (*mj.Scenario, error)) (*mj.Scenario, []*Diagnostic) {
+	p.resetKeyPath()
+	p.collectingErrors = true
+	p.diagnostics = nil
+	defer func() {
+		p.collectingErrors = false
+		p.diagnostics = nil
+	}()
+
+	scenario, err := parse()
+	if err != nil {
+		// only errors outside of any map entry or list item end up here
+		p.addDiagnostic(SeverityError, err)
+	}
+	return scenario, p.diagnostics
+}
+
+// recoverFrom is called when parsing a map entry or list item failed,
+// with the depth of the key path before entering it.
+// When collecting all errors, the error is recorded and nil is returned, so that parsing can go on.
+func (p *Parser) recoverFrom(err error, depth int) error {
+	if !p.collectingErrors {
+		return err
+	}
+	var syntaxErr *oj.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// the input stream is broken, cannot continue
+		return err
+	}
+	p.addDiagnostic(SeverityError, err)
+	p.keyPath = p.keyPath[:depth]
+	return nil
+}
+
+// warn records a warning at the current key path, when collecting all errors.
+func (p *Parser) warn(message string) {
+	if p.collectingErrors {
+		p.addDiagnostic(SeverityWarning, errors.New(message))
+	}
+}
+
+func (p *Parser) addDiagnostic(severity Severity, err error) {
+	diagnostic := &Diagnostic{
+		Severity: severity,
+		Message:  err.Error(),
+	}
+	var parseErr *ParseError
+	if errors.As(p.wrapParseError(err), &parseErr) {
+		diagnostic.FilePath = parseErr.FilePath
+		diagnostic.Pos = parseErr.Pos
+		diagnostic.KeyPath = parseErr.KeyPath
+		diagnostic.Message = parseErr.Err.Error()
+	}
+	p.diagnostics = append(p.diagnostics, diagnostic)
+}
@@ -0,0 +1,137 @@
+package scenjsonparse
+
+import (
+	"strings"
+	"testing"
+
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+	"github.com/stretchr/testify/require"
+)
+
+const manyErrorsScenario = `{
+    "name": "many errors",
+    "steps": [
+        {
+            "step": "setState",
+            "accounts": {
+                "address:a": {
+                    "nonce": "not a number",
+                    "balance": "100"
+                },
+                "address:b": {
+                    "unknown": "1"
+                }
+            }
+        },
+        {
+            "step": "noSuchStep"
+        },
+        {
+            "step": "scCall",
+            "tx": {
+                "from": "address:a",
+                "to": "sc:b",
+                "function": "f",
+                "gasLimit": "1"
+            },
+            "expect": {
+                "out": "0x01",
+                "foo": ""
+            }
+        }
+    ]
+}`
+
+func TestParseScenarioFileDiagnostics(t *testing.T) {
+	p := Parser{
+		SourcePath:                       "example.scen.json",
+		AllowSingleValueInCheckValueList: true,
+	}
+	parsed, diagnostics := p.ParseScenarioFileDiagnostics([]byte(manyErrorsScenario))
+	require.NotNil(t, parsed)
+	require.True(t, HasErrors(diagnostics))
+
+	var messages []string
+	for _, diagnostic := range diagnostics {
+		messages = append(messages, diagnostic.String())
+	}
+	require.Equal(t, []string{
+		`example.scen.json:8:21: error: steps[0].accounts["address:a"].nonce: invalid account nonce`,
+		`example.scen.json:12:21: error: steps[0].accounts["address:b"].unknown: unknown account field: unknown`,
+		`example.scen.json:16:9: error: steps[1]: unknown step type: noSuchStep`,
+		`example.scen.json:28:17: warning: steps[2].expect.out: single value instead of a list`,
+		`example.scen.json:29:17: error: steps[2].expect.foo: unknown tx result field: foo`,
+	}, messages)
+
+	// everything else is still parsed
+	require.Equal(t, "many errors", parsed.Name)
+	require.Len(t, parsed.Steps, 2)
+	setState := parsed.Steps[0].(*mj.SetStateStep)
+	require.Len(t, setState.Accounts, 2)
+	require.Equal(t, "100", setState.Accounts[0].Balance.Original)
+	txStep := parsed.Steps[1].(*mj.TxStep)
+	require.Equal(t, "f", txStep.Tx.Function)
+	require.Len(t, txStep.ExpectedResult.Out.Values, 1)
+
+	// the regular mode still stops at the first error
+	_, err := p.ParseScenarioFile([]byte(manyErrorsScenario))
+	require.Contains(t, err.Error(), "invalid account nonce")
+}
+
+func TestParseScenarioReaderDiagnostics(t *testing.T) {
+	p := Parser{
+		SourcePath:                       "example.scen.json",
+		AllowSingleValueInCheckValueList: true,
+	}
+	parsed, diagnostics := p.ParseScenarioFileDiagnostics([]byte(manyErrorsScenario))
+	streamed, streamedDiagnostics := p.ParseScenarioReaderDiagnostics(strings.NewReader(manyErrorsScenario))
+	require.Equal(t, diagnostics, streamedDiagnostics)
+	require.Equal(t, parsed.Steps, streamed.Steps)
+
+	// values that fail are skipped, and the stream goes on
+	for _, scenario := range []string{
+		`{"steps": {"step": "setState"}, "constants": {"A": "1"}, "name": "n"}`,
+		`{"steps": [], "constants": {"A": "1"}, "name": "n"}`,
+	} {
+		parsed, diagnostics = p.ParseScenarioFileDiagnostics([]byte(scenario))
+		streamed, streamedDiagnostics = p.ParseScenarioReaderDiagnostics(strings.NewReader(scenario))
+		require.NotEmpty(t, streamedDiagnostics, scenario)
+		require.Equal(t, diagnostics, streamedDiagnostics, scenario)
+		require.Equal(t, "n", streamed.Name, scenario)
+		require.Equal(t, parsed, streamed, scenario)
+	}
+
+	_, streamedDiagnostics = p.ParseScenarioReaderDiagnostics(strings.NewReader(`{"steps": [}`))
+	require.Len(t, streamedDiagnostics, 1)
+	require.Equal(t, 1, streamedDiagnostics[0].Pos.Line)
+}
+
+func TestParseScenarioFileDiagnosticsSyntaxError(t *testing.T) {
+	p := Parser{}
+	parsed, diagnostics := p.ParseScenarioFileDiagnostics([]byte(`{"steps": [}`))
+	require.Nil(t, parsed)
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, SeverityError, diagnostics[0].Severity)
+	require.Equal(t, 1, diagnostics[0].Pos.Line)
+}
+
+func TestParseScenarioFileDiagnosticsInvalidTx(t *testing.T) {
+	for _, scenario := range []string{
+		`{"steps":[{"step":"scCall","tx":5,"expect":{"status":"0"}}]}`,
+		`{"steps":[{"step":"scCall","expect":{"status":"0"},"tx":5}]}`,
+	} {
+		p := Parser{}
+		parsed, diagnostics := p.ParseScenarioFileDiagnostics([]byte(scenario))
+		require.NotNil(t, parsed)
+		var messages []string
+		for _, diagnostic := range diagnostics {
+			messages = append(messages, diagnostic.KeyPath+": "+diagnostic.Message)
+		}
+		require.ElementsMatch(t, []string{
+			"steps[0].tx: cannot parse tx step transaction: unmarshalled transaction is not a map",
+			"steps[0].expect: expect without a valid tx",
+		}, messages)
+		require.Len(t, parsed.Steps, 1)
+		require.Nil(t, parsed.Steps[0].(*mj.TxStep).Tx)
+	}
+}
@@ -0,0 +1,519 @@
+package scenjsonparse
+
+import (
+	"fmt"
+
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+)
+
+// ValueKind is the kind of JSON value accepted in some position.
+type ValueKind int
+
+const (
+	// ValueString is a plain string, not interpreted.
+	ValueString ValueKind = iota
+
+	// ValueBool is a JSON bool.
+	ValueBool
+
+	// ValueEnum is one of a fixed set of strings.
+	ValueEnum
+
+	// ValueExpression is a string holding a value expression, e.g. "str:abc", "1,000" or "address:owner".
+	ValueExpression
+
+	// ValueExpressionTree is a value expression, or a list or map of expression trees, which get concatenated.
+	ValueExpressionTree
+
+	// ValueList is a list of items described by Items.
+	ValueList
+
+	// ValueObject is a map with a known set of fields, described by Object.
+	ValueObject
+
+	// ValueMap is a map with arbitrary keys, described by Keys, and values described by Items.
+	ValueMap
+
+	// ValueAnyOf accepts values matching any of the Alternatives.
+	ValueAnyOf
+)
+
+// ValueSpec describes the JSON values accepted in some position.
+type ValueSpec struct {
+	Kind         ValueKind
+	Enum         []string
+	Items        *ValueSpec
+	Keys         *ValueSpec
+	Object       *ObjectSpec
+	Alternatives []*ValueSpec
+
+	// Star means that "*" is also accepted, to skip a check.
+	Star bool
+
+	// MoreAllowed means that lists also accept "+" items, and maps "+" keys, to allow unchecked extra entries.
+	MoreAllowed bool
+
+	// Deprecated values are only accepted for backwards compatibility.
+	Deprecated bool
+}
+
+// FieldSpec describes a field of a JSON object.
+type FieldSpec struct {
+	Name        string
+	Description string
+	Value       *ValueSpec
+
+	// Deprecated fields are only accepted for backwards compatibility.
+	Deprecated bool
+}
+
+// ObjectSpec describes a JSON map with a known set of fields.
+// The parser rejects all fields that are not listed.
+type ObjectSpec struct {
+	// Name identifies the object, e.g. in generated schemas.
+	Name        string
+	Description string
+	Fields      []*FieldSpec
+
+	unknownFieldFormat string
+}
+
+// Field yields the field with the given name, or nil if there is none.
+func (spec *ObjectSpec) Field(name string) *FieldSpec {
+	for _, field := range spec.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func (spec *ObjectSpec) unknownFieldError(name string) error {
+	return fmt.Errorf(spec.unknownFieldFormat, name)
+}
+
+// errFieldNotHandled signals a field that is in the registry, but which the parser does not know how to handle.
+func errFieldNotHandled(name string) error {
+	return fmt.Errorf("field %s is registered, but not handled by the parser", name)
+}
+
+func field(name string, description string, value *ValueSpec) *FieldSpec {
+	return &FieldSpec{Name: name, Description: description, Value: value}
+}
+
+func deprecatedField(name string, description string, value *ValueSpec) *FieldSpec {
+	return &FieldSpec{Name: name, Description: description, Value: value, Deprecated: true}
+}
+
+func objectValue(spec *ObjectSpec) *ValueSpec {
+	return &ValueSpec{Kind: ValueObject, Object: spec}
+}
+
+func listOf(items *ValueSpec) *ValueSpec {
+	return &ValueSpec{Kind: ValueList, Items: items}
+}
+
+func expressionMapOf(items *ValueSpec) *ValueSpec {
+	return &ValueSpec{Kind: ValueMap, Keys: expressionValue, Items: items}
+}
+
+func enumValue(values ...string) *ValueSpec {
+	return &ValueSpec{Kind: ValueEnum, Enum: values}
+}
+
+func anyOf(alternatives ...*ValueSpec) *ValueSpec {
+	return &ValueSpec{Kind: ValueAnyOf, Alternatives: alternatives}
+}
+
+func deprecated(value *ValueSpec) *ValueSpec {
+	result := *value
+	result.Deprecated = true
+	return &result
+}
+
+func withStar(value *ValueSpec) *ValueSpec {
+	result := *value
+	result.Star = true
+	return &result
+}
+
+func withMoreAllowed(value *ValueSpec) *ValueSpec {
+	result := *value
+	result.MoreAllowed = true
+	return &result
+}
+
+var (
+	stringValue         = &ValueSpec{Kind: ValueString}
+	boolValue           = &ValueSpec{Kind: ValueBool}
+	expressionValue     = &ValueSpec{Kind: ValueExpression}
+	expressionTreeValue = &ValueSpec{Kind: ValueExpressionTree}
+
+	checkExpressionValue     = withStar(expressionValue)
+	checkExpressionTreeValue = withStar(expressionTreeValue)
+	checkValueListValue      = anyOf(
+		withStar(listOf(checkExpressionTreeValue)),
+		deprecated(checkExpressionTreeValue),
+	)
+)
+
+var blockInfoSpec = &ObjectSpec{
+	Name:        "blockInfo",
+	Description: "Block information, as seen by smart contracts.",
+	Fields: []*FieldSpec{
+		field("blockTimestamp", "", expressionValue),
+		field("blockNonce", "", expressionValue),
+		field("blockRound", "", expressionValue),
+		field("blockEpoch", "", expressionValue),
+		field("blockRandomSeed", "Must be 48 bytes long.", expressionTreeValue),
+	},
+	unknownFieldFormat: "unknown block info field: %s",
+}
+
+var newAddressMockSpec = &ObjectSpec{
+	Name:        "newAddress",
+	Description: "Fixes the address of a contract deployed by some account.",
+	Fields: []*FieldSpec{
+		field("creatorAddress", "", expressionValue),
+		field("creatorNonce", "", expressionValue),
+		field("newAddress", "", expressionValue),
+	},
+	unknownFieldFormat: "unknown nam field: %s",
+}
+
+var dctInstanceSpec = &ObjectSpec{
+	Name:        "dctInstance",
+	Description: "One nonce of a DCT token.",
+	Fields:      dctInstanceFields(false),
+
+	unknownFieldFormat: "invalid account DCT instance field in instances list: `%s`",
+}
+
+var checkDCTInstanceSpec = &ObjectSpec{
+	Name:        "checkDctInstance",
+	Description: "Expected state of one nonce of a DCT token.",
+	Fields:      dctInstanceFields(true),
+
+	unknownFieldFormat: "invalid account DCT instance field in instances list: `%s`",
+}
+
+func dctInstanceFields(check bool) []*FieldSpec {
+	expression, expressionTree, valueList := expressionValue, expressionTreeValue, listOf(expressionValue)
+	if check {
+		expression, expressionTree, valueList = checkExpressionValue, checkExpressionTreeValue, checkValueListValue
+	}
+	return []*FieldSpec{
+		field("nonce", "", expressionValue),
+		field("balance", "", expression),
+		field("creator", "", expressionTree),
+		field("royalties", "At most 10000.", expression),
+		field("hash", "", expressionTree),
+		field("uri", "", valueList),
+		field("attributes", "", expressionTree),
+	}
+}
+
+var dctDataSpec = &ObjectSpec{
+	Name:        "dctData",
+	Description: "Token data of an account, for one token identifier.",
+	Fields:      dctDataFields(dctInstanceSpec, expressionValue),
+
+	unknownFieldFormat: "unknown DCT data field: %s",
+}
+
+var checkDCTDataSpec = &ObjectSpec{
+	Name:        "checkDctData",
+	Description: "Expected token data of an account, for one token identifier.",
+	Fields:      dctDataFields(checkDCTInstanceSpec, checkExpressionValue),
+
+	unknownFieldFormat: "unknown DCT data field: %s",
+}
+
+func dctDataFields(instanceSpec *ObjectSpec, expression *ValueSpec) []*FieldSpec {
+	fields := []*FieldSpec{
+		field("instances", "", listOf(objectValue(instanceSpec))),
+		field("lastNonce", "", expression),
+		field("roles", "", listOf(stringValue)),
+		field("frozen", "", expression),
+	}
+	// legacy syntax: the fields of the first instance, given directly
+	for _, instanceField := range instanceSpec.Fields {
+		legacyField := *instanceField
+		legacyField.Description = "Should be moved to \"instances\"."
+		legacyField.Deprecated = true
+		fields = append(fields, &legacyField)
+	}
+	return fields
+}
+
+var accountSpec = &ObjectSpec{
+	Name:        "account",
+	Description: "Account state.",
+	Fields: []*FieldSpec{
+		field("comment", "", stringValue),
+		field("update", "Only update the given fields of an existing account.", boolValue),
+		field("shard", "", expressionValue),
+		field("nonce", "", expressionValue),
+		field("balance", "", expressionValue),
+		field("dct", "Tokens, by token identifier. A string value is the balance of a fungible token.",
+			expressionMapOf(anyOf(expressionValue, objectValue(dctDataSpec)))),
+		field("username", "", expressionValue),
+		field("storage", "", expressionMapOf(expressionTreeValue)),
+		field("code", "", expressionValue),
+		field("codeMetadata", "", expressionValue),
+		field("owner", "", expressionValue),
+		field("asyncCallData", "", stringValue),
+		field("developerRewards", "", expressionValue),
+	},
+	unknownFieldFormat: "unknown account field: %s",
+}
+
+var checkAccountSpec = &ObjectSpec{
+	Name:        "checkAccount",
+	Description: "Expected account state. Fields that are missing are not checked.",
+	Fields: []*FieldSpec{
+		field("comment", "", stringValue),
+		field("nonce", "", checkExpressionValue),
+		field("balance", "", checkExpressionValue),
+		field("dct", "Expected tokens, by token identifier. A string value is the balance of a fungible token.",
+			withStar(withMoreAllowed(expressionMapOf(anyOf(checkExpressionValue, objectValue(checkDCTDataSpec)))))),
+		field("username", "", checkExpressionTreeValue),
+		field("storage", "", withStar(withMoreAllowed(expressionMapOf(checkExpressionTreeValue)))),
+		field("code", "", checkExpressionTreeValue),
+		field("codeMetadata", "", checkExpressionTreeValue),
+		field("owner", "", checkExpressionTreeValue),
+		field("asyncCallData", "", checkExpressionTreeValue),
+		field("developerRewards", "", checkExpressionValue),
+	},
+	unknownFieldFormat: "unknown account field: %s",
+}
+
+var txDCTSpec = &ObjectSpec{
+	Name:        "txDct",
+	Description: "A DCT transfer.",
+	Fields: []*FieldSpec{
+		field("tokenIdentifier", "", expressionValue),
+		field("nonce", "", expressionValue),
+		field("value", "", expressionValue),
+	},
+	unknownFieldFormat: "unknown transaction DCT data field: %s",
+}
+
+var transactionSpec = &ObjectSpec{
+	Name:        "transaction",
+	Description: "A transaction. Which fields are allowed depends on the step type.",
+	Fields: []*FieldSpec{
+		field("nonce", "", expressionValue),
+		field("from", "", expressionValue),
+		field("to", "", expressionValue),
+		field("function", "", stringValue),
+		field("abi", "Path to the contract ABI, relative to the current file. Arguments are then encoded according to the endpoint inputs.", stringValue),
+		deprecatedField("value", "Replaced by \"moaxValue\".", expressionValue),
+		field("moaxValue", "", expressionValue),
+		deprecatedField("dct", "Replaced by \"dctValue\".", anyOf(listOf(objectValue(txDCTSpec)), deprecated(objectValue(txDCTSpec)))),
+		field("dctValue", "", anyOf(listOf(objectValue(txDCTSpec)), deprecated(objectValue(txDCTSpec)))),
+		field("arguments", "", listOf(expressionTreeValue)),
+		field("contractCode", "", expressionValue),
+		field("codeMetadata", "", expressionValue),
+		field("gasLimit", "", expressionValue),
+		field("gasPrice", "", expressionValue),
+	},
+	unknownFieldFormat: "unknown field in transaction: %s",
+}
+
+var logEntrySpec = &ObjectSpec{
+	Name:        "logEntry",
+	Description: "Expected log entry.",
+	Fields: []*FieldSpec{
+		field("address", "", checkExpressionTreeValue),
+		field("endpoint", "", checkExpressionTreeValue),
+		field("topics", "", checkValueListValue),
+		field("data", "", checkValueListValue),
+	},
+	unknownFieldFormat: "unknown log field: %s",
+}
+
+var txResultSpec = &ObjectSpec{
+	Name:        "txResult",
+	Description: "Expected transaction result. Fields that are missing are not checked.",
+	Fields: []*FieldSpec{
+		field("out", "", checkValueListValue),
+		field("status", "", checkExpressionValue),
+		field("message", "", checkExpressionTreeValue),
+		field("logs", "", withStar(withMoreAllowed(listOf(objectValue(logEntrySpec))))),
+		field("gas", "", checkExpressionValue),
+		field("refund", "", checkExpressionValue),
+	},
+	unknownFieldFormat: "unknown tx result field: %s",
+}
+
+func stepTypeField(stepName string) *FieldSpec {
+	return field("step", "The step type.", enumValue(stepName))
+}
+
+var externalStepsSpec = &ObjectSpec{
+	Name:        "externalStepsStep",
+	Description: "Runs the steps of another file.",
+	Fields: []*FieldSpec{
+		stepTypeField(mj.StepNameExternalSteps),
+		field("comment", "", stringValue),
+		field("traceGas", "", boolValue),
+		field("path", "Path to the steps file, relative to the current file.", stringValue),
+	},
+	unknownFieldFormat: "invalid externalSteps field: %s",
+}
+
+var setStateSpec = &ObjectSpec{
+	Name:        "setStateStep",
+	Description: "Sets up accounts and blockchain state.",
+	Fields: []*FieldSpec{
+		stepTypeField(mj.StepNameSetState),
+		field("id", "", stringValue),
+		field("comment", "", stringValue),
+		field("accounts", "", expressionMapOf(objectValue(accountSpec))),
+		field("newAddresses", "", listOf(objectValue(newAddressMockSpec))),
+		field("previousBlockInfo", "", objectValue(blockInfoSpec)),
+		field("currentBlockInfo", "", objectValue(blockInfoSpec)),
+		field("blockHashes", "", listOf(expressionValue)),
+	},
+	unknownFieldFormat: "invalid set state field: %s",
+}
+
+var checkStateSpec = &ObjectSpec{
+	Name:        "checkStateStep",
+	Description: "Checks the state of accounts.",
+	Fields: []*FieldSpec{
+		stepTypeField(mj.StepNameCheckState),
+		field("id", "", stringValue),
+		field("comment", "", stringValue),
+		field("accounts", "", withMoreAllowed(expressionMapOf(objectValue(checkAccountSpec)))),
+	},
+	unknownFieldFormat: "invalid check state field: %s",
+}
+
+var dumpStateSpec = &ObjectSpec{
+	Name:        "dumpStateStep",
+	Description: "Prints the state of all accounts.",
+	Fields: []*FieldSpec{
+		stepTypeField(mj.StepNameDumpState),
+		field("comment", "", stringValue),
+	},
+	unknownFieldFormat: "invalid check state field: %s",
+}
+
+func newTxStepSpec(stepName string, description string) *ObjectSpec {
+	return &ObjectSpec{
+		Name:        stepName + "Step",
+		Description: description,
+		Fields: []*FieldSpec{
+			stepTypeField(stepName),
+			deprecatedField("txId", "Replaced by \"id\".", stringValue),
+			field("id", "", stringValue),
+			field("displayLogs", "", boolValue),
+			field("comment", "", stringValue),
+			field("tx", "", objectValue(transactionSpec)),
+			field("expect", "Only allowed for smart contract transactions.", objectValue(txResultSpec)),
+		},
+		unknownFieldFormat: "invalid tx step field: %s",
+	}
+}
+
+var txStepSpecs = map[mj.TransactionType]*ObjectSpec{
+	mj.ScCall:          newTxStepSpec(mj.StepNameScCall, "Calls a smart contract endpoint."),
+	mj.ScDeploy:        newTxStepSpec(mj.StepNameScDeploy, "Deploys a smart contract."),
+	mj.ScUpgrade:       newTxStepSpec(mj.StepNameScUpgrade, "Upgrades a smart contract."),
+	mj.ScQuery:         newTxStepSpec(mj.StepNameScQuery, "Calls a smart contract endpoint, without changing the state."),
+	mj.Transfer:        newTxStepSpec(mj.StepNameTransfer, "Transfers MOAX or DCT tokens."),
+	mj.ValidatorReward: newTxStepSpec(mj.StepNameValidatorReward, "Sends a validator reward to an account."),
+}
+
+var stepSpecs = []*ObjectSpec{
+	externalStepsSpec,
+	setStateSpec,
+	checkStateSpec,
+	dumpStateSpec,
+	txStepSpecs[mj.ScCall],
+	txStepSpecs[mj.ScDeploy],
+	txStepSpecs[mj.ScUpgrade],
+	txStepSpecs[mj.ScQuery],
+	txStepSpecs[mj.Transfer],
+	txStepSpecs[mj.ValidatorReward],
+}
+
+var stepValue = func() *ValueSpec {
+	var alternatives []*ValueSpec
+	for _, spec := range stepSpecs {
+		alternatives = append(alternatives, objectValue(spec))
+	}
+	return anyOf(alternatives...)
+}()
+
+var scenarioSpec = &ObjectSpec{
+	Name:        "scenario",
+	Description: "A scenario, or a steps file referenced by externalSteps.",
+	Fields: []*FieldSpec{
+		field("name", "", stringValue),
+		field("comment", "", stringValue),
+		field("tags", "Labels for selecting scenarios to run, e.g. \"slow\".", listOf(stringValue)),
+		field("checkGas", "", boolValue),
+		field("traceGas", "", boolValue),
+		field("gasSchedule", "", enumValue("default", "dummy", "v3", "v4")),
+		field("constants",
+			"Value expressions by name, referenced as \"${name}\" or \"var:name\" in the values of the steps. "+
+				"Must come before the steps. Steps files referenced by externalSteps inherit them.",
+			&ValueSpec{Kind: ValueMap, Keys: stringValue, Items: expressionValue}),
+		field("steps", "", listOf(stepValue)),
+	},
+	unknownFieldFormat: "unknown scenario field: %s",
+}
+
+var blockHeaderSpec = &ObjectSpec{
+	Name: "blockHeader",
+	Fields: []*FieldSpec{
+		field("gasLimit", "", expressionValue),
+		field("number", "", expressionValue),
+		field("difficulty", "", expressionValue),
+		field("timestamp", "", expressionValue),
+		field("coinbase", "", expressionValue),
+	},
+	unknownFieldFormat: "unknown block header field: %s",
+}
+
+var blockSpec = &ObjectSpec{
+	Name:        "block",
+	Description: "A block of transactions, with their expected results.",
+	Fields: []*FieldSpec{
+		field("results", "", listOf(objectValue(txResultSpec))),
+		field("transactions", "", listOf(objectValue(transactionSpec))),
+		field("blockHeader", "", objectValue(blockHeaderSpec)),
+	},
+	unknownFieldFormat: "unknown block field: %s",
+}
+
+var testSpec = &ObjectSpec{
+	Name:        "test",
+	Description: "A test, in the older test format.",
+	Fields: []*FieldSpec{
+		field("checkGas", "", boolValue),
+		field("pre", "", expressionMapOf(objectValue(accountSpec))),
+		field("blocks", "", listOf(objectValue(blockSpec))),
+		field("network", "", stringValue),
+		field("blockHashes", "", listOf(expressionValue)),
+		field("postState", "", withMoreAllowed(expressionMapOf(objectValue(checkAccountSpec)))),
+	},
+	unknownFieldFormat: "unknown test: %s",
+}
+
+// ScenarioSpec describes the top level object of scenario and steps files.
+func ScenarioSpec() *ObjectSpec {
+	return scenarioSpec
+}
+
+// TestFileSpec describes the top level object of test files, which maps test names to tests.
+func TestFileSpec() *ValueSpec {
+	return &ValueSpec{
+		Kind:  ValueMap,
+		Keys:  stringValue,
+		Items: objectValue(testSpec),
+	}
+}
@@ -44,103 +44,115 @@ func (p *Parser) processAccount(acctRaw oj.OJsonObject) (*mj.Account, error) {
 
 	var err error
 
-	for _, kvp := range acctMap.OrderedKV {
+	err = p.forEachField(accountSpec, acctMap, func(kvp *oj.OJsonKeyValuePair) error {
 		switch kvp.Key {
 		case "comment":
 			acct.Comment, err = p.parseString(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account comment: %w", err)
+				return fmt.Errorf("invalid account comment: %w", err)
 			}
 		case "update":
 			acct.Update, err = p.parseBool(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid update flag bool: %w", err)
+				return fmt.Errorf("invalid update flag bool: %w", err)
 			}
 		case "shard":
 			acct.Shard, err = p.processUint64(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid shard number: %w", err)
+				return fmt.Errorf("invalid shard number: %w", err)
 			}
 		case "nonce":
 			acct.Nonce, err = p.processUint64(kvp.Value)
 			if err != nil {
-				return nil, errors.New("invalid account nonce")
+				return errors.New("invalid account nonce")
 			}
 		case "balance":
 			acct.Balance, err = p.processBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, errors.New("invalid account balance")
+				return errors.New("invalid account balance")
 			}
 		case "dct":
 			dctMap, dctOk := kvp.Value.(*oj.OJsonMap)
 			if !dctOk {
-				return nil, errors.New("invalid DCT map")
+				return errors.New("invalid DCT map")
 			}
-			for _, dctKvp := range dctMap.OrderedKV {
+			err = p.forEachEntry(dctMap, func(dctKvp *oj.OJsonKeyValuePair) error {
 				tokenNameStr, err := p.ExprInterpreter.InterpretString(dctKvp.Key)
 				if err != nil {
-					return nil, fmt.Errorf("invalid dct token identifer: %w", err)
+					return fmt.Errorf("invalid dct token identifer: %w", err)
 				}
 				tokenName := mj.NewJSONBytesFromString(tokenNameStr, dctKvp.Key)
 				dctItem, err := p.processDCTData(tokenName, dctKvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid dct value: %w", err)
+					return fmt.Errorf("invalid dct value: %w", err)
 				}
 				acct.DCTData = append(acct.DCTData, dctItem)
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 		case "username":
 			acct.Username, err = p.processStringAsByteArray(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account username: %w", err)
+				return fmt.Errorf("invalid account username: %w", err)
 			}
 		case "storage":
 			storageMap, storageOk := kvp.Value.(*oj.OJsonMap)
 			if !storageOk {
-				return nil, errors.New("invalid account storage")
+				return errors.New("invalid account storage")
 			}
-			for _, storageKvp := range storageMap.OrderedKV {
+			err = p.forEachEntry(storageMap, func(storageKvp *oj.OJsonKeyValuePair) error {
 				byteKey, err := p.ExprInterpreter.InterpretString(storageKvp.Key)
 				if err != nil {
-					return nil, fmt.Errorf("invalid account storage key: %w", err)
+					return fmt.Errorf("invalid account storage key: %w", err)
 				}
 				byteVal, err := p.processSubTreeAsByteArray(storageKvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid account storage value: %w", err)
+					return fmt.Errorf("invalid account storage value: %w", err)
 				}
 				stElem := mj.StorageKeyValuePair{
 					Key:   mj.NewJSONBytesFromString(byteKey, storageKvp.Key),
 					Value: byteVal,
 				}
 				acct.Storage = append(acct.Storage, &stElem)
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 		case "code":
 			acct.Code, err = p.processStringAsByteArray(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account code: %w", err)
+				return fmt.Errorf("invalid account code: %w", err)
 			}
 		case "codeMetadata":
 			acct.CodeMetadata, err = p.processStringAsByteArray(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account codeMetadata: %w", err)
+				return fmt.Errorf("invalid account codeMetadata: %w", err)
 			}
 		case "owner":
 			acct.Owner, err = p.processStringAsByteArray(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account owner: %w", err)
+				return fmt.Errorf("invalid account owner: %w", err)
 			}
 		case "asyncCallData":
 			acct.AsyncCallData, err = p.parseString(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid asyncCallData string: %w", err)
+				return fmt.Errorf("invalid asyncCallData string: %w", err)
 			}
 		case "developerRewards":
 			acct.DeveloperReward, err = p.processBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, errors.New("invalid developerRewards")
+				return errors.New("invalid developerRewards")
 			}
 		default:
-			return nil, fmt.Errorf("unknown account field: %s", kvp.Key)
+			return errFieldNotHandled(kvp.Key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &acct, nil
@@ -152,18 +164,21 @@ func (p *Parser) processAccountMap(acctMapRaw oj.OJsonObject) ([]*mj.Account, er
 	if !isPreMap {
 		return nil, errors.New("unmarshalled account map object is not a map")
 	}
-	for _, acctKVP := range preMap.OrderedKV {
+	err := p.forEachEntry(preMap, func(acctKVP *oj.OJsonKeyValuePair) error {
 		acct, acctErr := p.processAccount(acctKVP.Value)
 		if acctErr != nil {
-			return nil, acctErr
+			return acctErr
 		}
 		acctAddr, hexErr := p.parseAccountAddress(acctKVP.Key)
 		if hexErr != nil {
-			return nil, hexErr
+			return hexErr
 		}
 		acct.Address = acctAddr
 		accounts = append(accounts, acct)
-
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return accounts, nil
 }
@@ -34,51 +34,55 @@ func (p *Parser) processCheckAccount(acctRaw oj.OJsonObject) (*mj.CheckAccount,
 	}
 	var err error
 
-	for _, kvp := range acctMap.OrderedKV {
+	err = p.forEachField(checkAccountSpec, acctMap, func(kvp *oj.OJsonKeyValuePair) error {
 		switch kvp.Key {
 		case "comment":
 			acct.Comment, err = p.parseString(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid check account comment: %w", err)
+				return fmt.Errorf("invalid check account comment: %w", err)
 			}
 		case "nonce":
 			acct.Nonce, err = p.processCheckUint64(kvp.Value)
 			if err != nil {
-				return nil, errors.New("invalid account nonce")
+				return errors.New("invalid account nonce")
 			}
 		case "balance":
 			acct.Balance, err = p.processCheckBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, errors.New("invalid account balance")
+				return errors.New("invalid account balance")
 			}
 		case "dct":
 			acct.IgnoreDCT = IsStar(kvp.Value)
 			if !acct.IgnoreDCT {
 				dctMap, dctOk := kvp.Value.(*oj.OJsonMap)
 				if !dctOk {
-					return nil, errors.New("invalid DCT map")
+					return errors.New("invalid DCT map")
 				}
-				for _, dctKvp := range dctMap.OrderedKV {
+				err = p.forEachEntry(dctMap, func(dctKvp *oj.OJsonKeyValuePair) error {
 					if dctKvp.Key == "+" {
 						acct.MoreDCTTokensAllowed = true
 					} else {
 						tokenNameStr, err := p.ExprInterpreter.InterpretString(dctKvp.Key)
 						if err != nil {
-							return nil, fmt.Errorf("invalid dct token identifer: %w", err)
+							return fmt.Errorf("invalid dct token identifer: %w", err)
 						}
 						tokenName := mj.NewJSONBytesFromString(tokenNameStr, dctKvp.Key)
 						dctItem, err := p.processCheckDCTData(tokenName, dctKvp.Value)
 						if err != nil {
-							return nil, fmt.Errorf("invalid dct value: %w", err)
+							return fmt.Errorf("invalid dct value: %w", err)
 						}
 						acct.CheckDCTData = append(acct.CheckDCTData, dctItem)
 					}
+					return nil
+				})
+				if err != nil {
+					return err
 				}
 			}
 		case "username":
 			acct.Username, err = p.parseCheckBytes(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account username: %w", err)
+				return fmt.Errorf("invalid account username: %w", err)
 			}
 		case "storage":
 			acct.ExplicitStorage = true
@@ -86,19 +90,19 @@ func (p *Parser) processCheckAccount(acctRaw oj.OJsonObject) (*mj.CheckAccount,
 			if !acct.IgnoreStorage {
 				storageMap, storageOk := kvp.Value.(*oj.OJsonMap)
 				if !storageOk {
-					return nil, errors.New("invalid account storage")
+					return errors.New("invalid account storage")
 				}
-				for _, storageKvp := range storageMap.OrderedKV {
+				err = p.forEachEntry(storageMap, func(storageKvp *oj.OJsonKeyValuePair) error {
 					if storageKvp.Key == "+" {
 						acct.MoreStorageAllowed = true
 					} else {
 						byteKey, err := p.ExprInterpreter.InterpretString(storageKvp.Key)
 						if err != nil {
-							return nil, fmt.Errorf("invalid account storage key: %w", err)
+							return fmt.Errorf("invalid account storage key: %w", err)
 						}
 						byteVal, err := p.parseCheckBytes(storageKvp.Value)
 						if err != nil {
-							return nil, fmt.Errorf("invalid account storage value: %w", err)
+							return fmt.Errorf("invalid account storage value: %w", err)
 						}
 						stElem := mj.CheckStorageKeyValuePair{
 							Key:        mj.NewJSONBytesFromString(byteKey, storageKvp.Key),
@@ -106,37 +110,45 @@ func (p *Parser) processCheckAccount(acctRaw oj.OJsonObject) (*mj.CheckAccount,
 						}
 						acct.CheckStorage = append(acct.CheckStorage, &stElem)
 					}
+					return nil
+				})
+				if err != nil {
+					return err
 				}
 			}
 		case "code":
 			acct.Code, err = p.parseCheckBytes(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account code: %w", err)
+				return fmt.Errorf("invalid account code: %w", err)
 			}
 		case "codeMetadata":
 			acct.CodeMetadata, err = p.parseCheckBytes(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account codeMetadata: %w", err)
+				return fmt.Errorf("invalid account codeMetadata: %w", err)
 			}
 		case "owner":
 			acct.Owner, err = p.parseCheckBytes(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account owner: %w", err)
+				return fmt.Errorf("invalid account owner: %w", err)
 			}
 		case "asyncCallData":
 			acct.AsyncCallData, err = p.parseCheckBytes(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid asyncCallData: %w", err)
+				return fmt.Errorf("invalid asyncCallData: %w", err)
 			}
 		case "developerRewards":
 			acct.DeveloperReward, err = p.processCheckBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, fmt.Errorf("invalid developerRewards: %w", err)
+				return fmt.Errorf("invalid developerRewards: %w", err)
 			}
 
 		default:
-			return nil, fmt.Errorf("unknown account field: %s", kvp.Key)
+			return errFieldNotHandled(kvp.Key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &acct, nil
@@ -152,21 +164,25 @@ func (p *Parser) processCheckAccountMap(acctMapRaw oj.OJsonObject) (*mj.CheckAcc
 	if !isPreMap {
 		return nil, errors.New("unmarshalled check account map object is not a map")
 	}
-	for _, acctKVP := range preMap.OrderedKV {
+	err := p.forEachEntry(preMap, func(acctKVP *oj.OJsonKeyValuePair) error {
 		if acctKVP.Key == "+" {
 			checkAccounts.MoreAccountsAllowed = true
 		} else {
 			acct, acctErr := p.processCheckAccount(acctKVP.Value)
 			if acctErr != nil {
-				return nil, acctErr
+				return acctErr
 			}
 			acctAddr, hexErr := p.parseAccountAddress(acctKVP.Key)
 			if hexErr != nil {
-				return nil, hexErr
+				return hexErr
 			}
 			acct.Address = acctAddr
 			checkAccounts.Accounts = append(checkAccounts.Accounts, acct)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return checkAccounts, nil
 }
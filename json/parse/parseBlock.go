@@ -15,30 +15,34 @@ func (p *Parser) processBlock(blockRaw oj.OJsonObject) (*mj.Block, error) {
 	}
 	bl := mj.Block{}
 
-	for _, kvp := range blockMap.OrderedKV {
+	err := p.forEachField(blockSpec, blockMap, func(kvp *oj.OJsonKeyValuePair) error {
 		switch kvp.Key {
 		case "results":
 			resultsRaw, resultsOk := kvp.Value.(*oj.OJsonList)
 			if !resultsOk {
-				return nil, errors.New("unmarshalled block results object is not a list")
+				return errors.New("unmarshalled block results object is not a list")
 			}
-			for _, resRaw := range resultsRaw.AsList() {
+			err := p.forEachItem(resultsRaw, func(_ int, resRaw oj.OJsonObject) error {
 				blr, blrErr := p.processTxExpectedResult(resRaw)
 				if blrErr != nil {
-					return nil, blrErr
+					return blrErr
 				}
 				bl.Results = append(bl.Results, blr)
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 		case "transactions":
 			transactionsRaw, transactionsOk := kvp.Value.(*oj.OJsonList)
 			if !transactionsOk {
-				return nil, errors.New("unmarshalled block transactions object is not a list")
+				return errors.New("unmarshalled block transactions object is not a list")
 			}
-			for _, trRaw := range transactionsRaw.AsList() {
+			err := p.forEachItem(transactionsRaw, func(_ int, trRaw oj.OJsonObject) error {
 				var txType mj.TransactionType
 				isCreate, err := p.txIsCreate(trRaw)
 				if err != nil {
-					return nil, err
+					return err
 				}
 				if isCreate {
 					txType = mj.ScDeploy
@@ -47,19 +51,27 @@ func (p *Parser) processBlock(blockRaw oj.OJsonObject) (*mj.Block, error) {
 				}
 				tr, trErr := p.processTx(txType, trRaw)
 				if trErr != nil {
-					return nil, trErr
+					return trErr
 				}
 				bl.Transactions = append(bl.Transactions, tr)
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 		case "blockHeader":
 			blh, blhErr := p.processBlockHeader(kvp.Value)
 			if blhErr != nil {
-				return nil, blhErr
+				return blhErr
 			}
 			bl.BlockHeader = blh
 		default:
-			return nil, fmt.Errorf("unknown block field: %s", kvp.Key)
+			return errFieldNotHandled(kvp.Key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if len(bl.Results) != len(bl.Transactions) {
@@ -97,36 +109,40 @@ func (p *Parser) processBlockHeader(blhRaw interface{}) (*mj.BlockHeader, error)
 	blh := mj.BlockHeader{}
 	var err error
 
-	for _, kvp := range blhMap.OrderedKV {
+	err = p.forEachField(blockHeaderSpec, blhMap, func(kvp *oj.OJsonKeyValuePair) error {
 		switch kvp.Key {
 		case "gasLimit":
 			blh.GasLimit, err = p.processBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block header gasLimit: %w", err)
+				return fmt.Errorf("invalid block header gasLimit: %w", err)
 			}
 		case "number":
 			blh.Number, err = p.processBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block header number: %w", err)
+				return fmt.Errorf("invalid block header number: %w", err)
 			}
 		case "difficulty":
 			blh.Difficulty, err = p.processBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block header difficulty: %w", err)
+				return fmt.Errorf("invalid block header difficulty: %w", err)
 			}
 		case "timestamp":
 			blh.Timestamp, err = p.processUint64(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block header timestamp: %w", err)
+				return fmt.Errorf("invalid block header timestamp: %w", err)
 			}
 		case "coinbase":
 			blh.Beneficiary, err = p.processBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block header coinbase: %w", err)
+				return fmt.Errorf("invalid block header coinbase: %w", err)
 			}
 		default:
-			return nil, fmt.Errorf("unknown block header field: %s", kvp.Key)
+			return errFieldNotHandled(kvp.Key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &blh, nil
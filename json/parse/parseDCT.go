@@ -51,11 +51,11 @@ func (p *Parser) processDCTDataMap(tokenName mj.JSONBytesFromString, dctDataMap
 	firstInstanceLoaded := false
 	var explicitInstances []*mj.DCTInstance
 
-	for _, kvp := range dctDataMap.OrderedKV {
+	err := p.forEachField(dctDataSpec, dctDataMap, func(kvp *oj.OJsonKeyValuePair) error {
 		// it is allowed to load the instance directly, fields set to the first instance
 		instanceFieldLoaded, err := p.tryProcessDCTInstanceField(kvp, firstInstance)
 		if err != nil {
-			return nil, fmt.Errorf("invalid account DCT instance field: %w", err)
+			return fmt.Errorf("invalid account DCT instance field: %w", err)
 		}
 		if instanceFieldLoaded {
 			firstInstanceLoaded = true
@@ -64,33 +64,38 @@ func (p *Parser) processDCTDataMap(tokenName mj.JSONBytesFromString, dctDataMap
 			case "instances":
 				explicitInstances, err = p.processDCTInstances(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid account DCT instances: %w", err)
+					return fmt.Errorf("invalid account DCT instances: %w", err)
 				}
 			case "lastNonce":
 				dctData.LastNonce, err = p.processUint64(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid account DCT lastNonce: %w", err)
+					return fmt.Errorf("invalid account DCT lastNonce: %w", err)
 				}
 			case "roles":
 				dctData.Roles, err = p.processStringList(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid account DCT roles: %w", err)
+					return fmt.Errorf("invalid account DCT roles: %w", err)
 				}
 			case "frozen":
 				dctData.Frozen, err = p.processUint64(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid DCT frozen flag: %w", err)
+					return fmt.Errorf("invalid DCT frozen flag: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("unknown DCT data field: %s", kvp.Key)
+				return errFieldNotHandled(kvp.Key)
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if firstInstanceLoaded {
 		if !p.AllowDctLegacySetSyntax {
 			return nil, fmt.Errorf("wrong DCT set state syntax: instances in root no longer allowed")
 		}
+		p.warn("legacy DCT set state syntax: instance fields should be moved to \"instances\"")
 		dctData.Instances = []*mj.DCTInstance{firstInstance}
 	}
 	dctData.Instances = append(dctData.Instances, explicitInstances...)
@@ -148,26 +153,33 @@ func (p *Parser) processDCTInstances(dctInstancesRaw oj.OJsonObject) ([]*mj.DCTI
 	if !isList {
 		return nil, errors.New("dct instances object is not a list")
 	}
-	for _, instanceItem := range dctInstancesList.AsList() {
+	err := p.forEachItem(dctInstancesList, func(_ int, instanceItem oj.OJsonObject) error {
 		instanceAsMap, isMap := instanceItem.(*oj.OJsonMap)
 		if !isMap {
-			return nil, errors.New("JSON map expected as dct instances list item")
+			return errors.New("JSON map expected as dct instances list item")
 		}
 
 		instance := &mj.DCTInstance{}
 
-		for _, kvp := range instanceAsMap.OrderedKV {
+		err := p.forEachField(dctInstanceSpec, instanceAsMap, func(kvp *oj.OJsonKeyValuePair) error {
 			instanceFieldLoaded, err := p.tryProcessDCTInstanceField(kvp, instance)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account DCT instance field in instances list: %w", err)
+				return fmt.Errorf("invalid account DCT instance field in instances list: %w", err)
 			}
 			if !instanceFieldLoaded {
-				return nil, fmt.Errorf("invalid account DCT instance field in instances list: `%s`", kvp.Key)
+				return errFieldNotHandled(kvp.Key)
 			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 
 		instancesResult = append(instancesResult, instance)
-
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return instancesResult, nil
@@ -60,11 +60,11 @@ func (p *Parser) processCheckDCTDataMap(tokenName mj.JSONBytesFromString, dctDat
 	firstInstanceLoaded := false
 	var explicitInstances []*mj.CheckDCTInstance
 
-	for _, kvp := range dctDataMap.OrderedKV {
+	err := p.forEachField(checkDCTDataSpec, dctDataMap, func(kvp *oj.OJsonKeyValuePair) error {
 		// it is allowed to load the instance directly, fields set to the first instance
 		instanceFieldLoaded, err := p.tryProcessCheckDCTInstanceField(kvp, firstInstance)
 		if err != nil {
-			return nil, fmt.Errorf("invalid account DCT instance field: %w", err)
+			return fmt.Errorf("invalid account DCT instance field: %w", err)
 		}
 		if instanceFieldLoaded {
 			firstInstanceLoaded = true
@@ -73,33 +73,38 @@ func (p *Parser) processCheckDCTDataMap(tokenName mj.JSONBytesFromString, dctDat
 			case "instances":
 				explicitInstances, err = p.processCheckDCTInstances(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid account DCT instances: %w", err)
+					return fmt.Errorf("invalid account DCT instances: %w", err)
 				}
 			case "lastNonce":
 				dctData.LastNonce, err = p.processCheckUint64(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid account DCT lastNonce: %w", err)
+					return fmt.Errorf("invalid account DCT lastNonce: %w", err)
 				}
 			case "roles":
 				dctData.Roles, err = p.processStringList(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid account DCT roles: %w", err)
+					return fmt.Errorf("invalid account DCT roles: %w", err)
 				}
 			case "frozen":
 				dctData.Frozen, err = p.processCheckUint64(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("invalid DCT frozen flag: %w", err)
+					return fmt.Errorf("invalid DCT frozen flag: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("unknown DCT data field: %s", kvp.Key)
+				return errFieldNotHandled(kvp.Key)
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if firstInstanceLoaded {
 		if !p.AllowDctLegacyCheckSyntax {
 			return nil, fmt.Errorf("wrong DCT check state syntax: instances in root no longer allowed")
 		}
+		p.warn("legacy DCT check state syntax: instance fields should be moved to \"instances\"")
 		dctData.Instances = []*mj.CheckDCTInstance{firstInstance}
 	}
 	dctData.Instances = append(dctData.Instances, explicitInstances...)
@@ -160,26 +165,33 @@ func (p *Parser) processCheckDCTInstances(dctInstancesRaw oj.OJsonObject) ([]*mj
 	if !isList {
 		return nil, errors.New("dct instances object is not a list")
 	}
-	for _, instanceItem := range dctInstancesList.AsList() {
+	err := p.forEachItem(dctInstancesList, func(_ int, instanceItem oj.OJsonObject) error {
 		instanceAsMap, isMap := instanceItem.(*oj.OJsonMap)
 		if !isMap {
-			return nil, errors.New("JSON map expected as dct instances list item")
+			return errors.New("JSON map expected as dct instances list item")
 		}
 
 		instance := mj.NewCheckDCTInstance()
 
-		for _, kvp := range instanceAsMap.OrderedKV {
+		err := p.forEachField(checkDCTInstanceSpec, instanceAsMap, func(kvp *oj.OJsonKeyValuePair) error {
 			instanceFieldLoaded, err := p.tryProcessCheckDCTInstanceField(kvp, instance)
 			if err != nil {
-				return nil, fmt.Errorf("invalid account DCT instance field in instances list: %w", err)
+				return fmt.Errorf("invalid account DCT instance field in instances list: %w", err)
 			}
 			if !instanceFieldLoaded {
-				return nil, fmt.Errorf("invalid account DCT instance field in instances list: `%s`", kvp.Key)
+				return errFieldNotHandled(kvp.Key)
 			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 
 		instancesResult = append(instancesResult, instance)
-
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return instancesResult, nil
@@ -16,6 +16,7 @@ func (p *Parser) processTxDCT(txDctRaw oj.OJsonObject) ([]*mj.DCTTxData, error)
 		if !p.AllowDctTxLegacySyntax {
 			return nil, fmt.Errorf("wrong DCT Multi-Transfer format, list expected")
 		}
+		p.warn("legacy DCT transfer syntax: a list of transfers is expected")
 		entry, err := p.parseSingleTxDctEntry(txDct)
 		if err != nil {
 			return nil, err
@@ -23,18 +24,22 @@ func (p *Parser) processTxDCT(txDctRaw oj.OJsonObject) ([]*mj.DCTTxData, error)
 
 		allDctData = append(allDctData, entry)
 	case *oj.OJsonList:
-		for _, txDctListItem := range txDct.AsList() {
+		err := p.forEachItem(txDct, func(_ int, txDctListItem oj.OJsonObject) error {
 			txDctMap, isMap := txDctListItem.(*oj.OJsonMap)
 			if !isMap {
-				return nil, fmt.Errorf("wrong DCT Multi-Transfer format")
+				return fmt.Errorf("wrong DCT Multi-Transfer format")
 			}
 
 			entry, err := p.parseSingleTxDctEntry(txDctMap)
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			allDctData = append(allDctData, entry)
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 	default:
 		return nil, fmt.Errorf("wrong DCT transfer format, expected list")
@@ -47,26 +52,30 @@ func (p *Parser) parseSingleTxDctEntry(dctTxEntry *oj.OJsonMap) (*mj.DCTTxData,
 	dctData := mj.DCTTxData{}
 	var err error
 
-	for _, kvp := range dctTxEntry.OrderedKV {
+	err = p.forEachField(txDCTSpec, dctTxEntry, func(kvp *oj.OJsonKeyValuePair) error {
 		switch kvp.Key {
 		case "tokenIdentifier":
 			dctData.TokenIdentifier, err = p.processStringAsByteArray(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid DCT token name: %w", err)
+				return fmt.Errorf("invalid DCT token name: %w", err)
 			}
 		case "nonce":
 			dctData.Nonce, err = p.processUint64(kvp.Value)
 			if err != nil {
-				return nil, errors.New("invalid account nonce")
+				return errors.New("invalid account nonce")
 			}
 		case "value":
 			dctData.Value, err = p.processBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, fmt.Errorf("invalid DCT balance: %w", err)
+				return fmt.Errorf("invalid DCT balance: %w", err)
 			}
 		default:
-			return nil, fmt.Errorf("unknown transaction DCT data field: %s", kvp.Key)
+			return errFieldNotHandled(kvp.Key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &dctData, nil
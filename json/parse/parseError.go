@@ -0,0 +1,192 @@
+package scenjsonparse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+)
+
+// ParseError is returned when a scenario or test file cannot be parsed.
+// It points to the location in the source where the problem was found.
+type ParseError struct {
+	FilePath string
+	Pos      oj.Position
+	KeyPath  string
+	Err      error
+}
+
+// Error yields a message of the form "file:line:column: keyPath: cause".
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+	if len(e.FilePath) > 0 {
+		sb.WriteString(e.FilePath)
+		sb.WriteString(":")
+	}
+	sb.WriteString(e.Pos.String())
+	sb.WriteString(": ")
+	if len(e.KeyPath) > 0 {
+		sb.WriteString(e.KeyPath)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Err.Error())
+	return sb.String()
+}
+
+// Unwrap yields the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+var identifierKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// keyPathElem is one level of the key path, either a map key or a list index.
+type keyPathElem struct {
+	key   string
+	index int
+	pos   oj.Position
+}
+
+func (elem keyPathElem) isIndex() bool {
+	return elem.index >= 0
+}
+
+// enterKey records that the parser descends into a map entry.
+// Every call must be matched by exitKeyPath, unless an error is returned,
+// in which case the key path is kept, to indicate where the error occurred.
+func (p *Parser) enterKey(kvp *oj.OJsonKeyValuePair) {
+	p.keyPath = append(p.keyPath, keyPathElem{
+		key:   kvp.Key,
+		index: -1,
+		pos:   kvp.KeySpan.Start,
+	})
+}
+
+// enterIndex records that the parser descends into a list item.
+func (p *Parser) enterIndex(index int, item oj.OJsonObject) {
+	var pos oj.Position
+	if item != nil {
+		pos = item.SourceSpan().Start
+	}
+	p.keyPath = append(p.keyPath, keyPathElem{
+		index: index,
+		pos:   pos,
+	})
+}
+
+// exitKeyPath is called after an entry was successfully parsed.
+func (p *Parser) exitKeyPath() {
+	p.keyPath = p.keyPath[:len(p.keyPath)-1]
+}
+
+func (p *Parser) resetKeyPath() {
+	p.keyPath = nil
+}
+
+// currentKeyPath formats the key path, e.g. `steps[12].expect.out[0]`.
+func (p *Parser) currentKeyPath() string {
+	var sb strings.Builder
+	for _, elem := range p.keyPath {
+		switch {
+		case elem.isIndex():
+			sb.WriteString(fmt.Sprintf("[%d]", elem.index))
+		case identifierKeyRegex.MatchString(elem.key):
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(elem.key)
+		default:
+			sb.WriteString(fmt.Sprintf("[%q]", elem.key))
+		}
+	}
+	return sb.String()
+}
+
+// currentPosition yields the position of the innermost entry on the key path.
+func (p *Parser) currentPosition() oj.Position {
+	for i := len(p.keyPath) - 1; i >= 0; i-- {
+		if p.keyPath[i].pos.IsValid() {
+			return p.keyPath[i].pos
+		}
+	}
+	return oj.Position{}
+}
+
+// wrapParseError converts any error to a ParseError, based on the current key path.
+func (p *Parser) wrapParseError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return err
+	}
+
+	var syntaxErr *oj.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return &ParseError{
+			FilePath: p.SourcePath,
+			Pos:      syntaxErr.Pos,
+			Err:      errors.New(syntaxErr.Msg),
+		}
+	}
+
+	return &ParseError{
+		FilePath: p.SourcePath,
+		Pos:      p.currentPosition(),
+		KeyPath:  p.currentKeyPath(),
+		Err:      err,
+	}
+}
+
+// forEachEntry calls handleEntry for each entry of a map, keeping track of the key path.
+// When collecting all errors, entries that fail are recorded as diagnostics and skipped.
+func (p *Parser) forEachEntry(m *oj.OJsonMap, handleEntry func(kvp *oj.OJsonKeyValuePair) error) error {
+	for _, kvp := range m.OrderedKV {
+		depth := len(p.keyPath)
+		p.enterKey(kvp)
+		err := handleEntry(kvp)
+		if err != nil {
+			err = p.recoverFrom(err, depth)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		p.exitKeyPath()
+	}
+	return nil
+}
+
+// forEachItem calls handleItem for each item of a list, keeping track of the key path.
+// When collecting all errors, items that fail are recorded as diagnostics and skipped.
+func (p *Parser) forEachItem(list *oj.OJsonList, handleItem func(index int, item oj.OJsonObject) error) error {
+	for i, item := range list.AsList() {
+		depth := len(p.keyPath)
+		p.enterIndex(i, item)
+		err := handleItem(i, item)
+		if err != nil {
+			err = p.recoverFrom(err, depth)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		p.exitKeyPath()
+	}
+	return nil
+}
+
+// forEachField is forEachEntry for maps with a known set of fields.
+// Fields that are not in the spec are rejected before reaching handleField.
+func (p *Parser) forEachField(spec *ObjectSpec, m *oj.OJsonMap, handleField func(kvp *oj.OJsonKeyValuePair) error) error {
+	return p.forEachEntry(m, func(kvp *oj.OJsonKeyValuePair) error {
+		if spec.Field(kvp.Key) == nil {
+			return spec.unknownFieldError(kvp.Key)
+		}
+		return handleField(kvp)
+	})
+}
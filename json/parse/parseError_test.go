@@ -0,0 +1,107 @@
+package scenjsonparse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorKeyPath(t *testing.T) {
+	scenario := `{
+    "steps": [
+        {
+            "step": "scCall",
+            "tx": {
+                "from": "address:a",
+                "to": "sc:b",
+                "function": "f",
+                "gasLimit": "1"
+            },
+            "expect": {
+                "out": [ "1", "*" ],
+                "foo": ""
+            }
+        }
+    ]
+}`
+	p := Parser{SourcePath: "example.scen.json"}
+	_, err := p.ParseScenarioFile([]byte(scenario))
+	require.NotNil(t, err)
+
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, "steps[0].expect.foo", parseErr.KeyPath)
+	require.Equal(t, 13, parseErr.Pos.Line)
+	require.Equal(t, 17, parseErr.Pos.Column)
+	require.Contains(t, err.Error(), "example.scen.json:13:17: steps[0].expect.foo: ")
+	require.Contains(t, err.Error(), "unknown tx result field: foo")
+
+	_, streamErr := p.ParseScenarioReader(strings.NewReader(scenario))
+	require.Equal(t, err.Error(), streamErr.Error())
+}
+
+func TestParseErrorListIndex(t *testing.T) {
+	scenario := `{
+    "steps": [
+        {
+            "step": "setState",
+            "accounts": {
+                "address:a": {
+                    "storage": {
+                        "str:key": [ "1", true ]
+                    }
+                }
+            }
+        }
+    ]
+}`
+	p := Parser{}
+	_, err := p.ParseScenarioFile([]byte(scenario))
+	require.NotNil(t, err)
+
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, `steps[0].accounts["address:a"].storage["str:key"]`, parseErr.KeyPath)
+	require.Equal(t, 8, parseErr.Pos.Line)
+}
+
+func TestParseErrorSyntax(t *testing.T) {
+	p := Parser{}
+	_, err := p.ParseTestFile([]byte("{\n  \"t\": ]\n}"))
+	require.NotNil(t, err)
+
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, "", parseErr.KeyPath)
+	require.Equal(t, 2, parseErr.Pos.Line)
+	require.Equal(t, 8, parseErr.Pos.Column)
+}
+
+func TestParseErrorStrictStrings(t *testing.T) {
+	for scenario, expectedErr := range map[string]string{
+		"{\n  \"name\": \"a\tb\"\n}":  "2:11: control character 0x09 not allowed in string",
+		"{\n  \"name\": \"a\nb\"\n}":  "2:11: control character 0x0a not allowed in string",
+		"{\n  \"name\": \"a\\qb\"\n}": "2:11: invalid escape sequence \\q in string",
+	} {
+		p := Parser{}
+		_, err := p.ParseScenarioFile([]byte(scenario))
+		require.NotNil(t, err, scenario)
+		require.Equal(t, expectedErr, err.Error(), scenario)
+
+		_, streamErr := p.ParseScenarioReader(strings.NewReader(scenario))
+		require.NotNil(t, streamErr, scenario)
+		require.Equal(t, expectedErr, streamErr.Error(), scenario)
+	}
+
+	// escape sequences are decoded
+	scenario := `{"name": "\"café\"\t\/"}`
+	p := Parser{}
+	parsed, err := p.ParseScenarioFile([]byte(scenario))
+	require.Nil(t, err)
+	require.Equal(t, "\"café\"\t/", parsed.Name)
+	streamed, err := p.ParseScenarioReader(strings.NewReader(scenario))
+	require.Nil(t, err)
+	require.Equal(t, parsed.Name, streamed.Name)
+}
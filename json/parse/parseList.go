@@ -3,6 +3,7 @@ package scenjsonparse
 import (
 	"errors"
 
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
 	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
 	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
 )
@@ -13,12 +14,14 @@ func (p *Parser) processStringList(obj interface{}) ([]string, error) {
 		return nil, errors.New("not a JSON list")
 	}
 	var result []string
-	for _, elemRaw := range listRaw.AsList() {
+	for i, elemRaw := range listRaw.AsList() {
+		p.enterIndex(i, elemRaw)
 		strVal, err := p.parseString(elemRaw)
 		if err != nil {
 			return nil, err
 		}
 		result = append(result, strVal)
+		p.exitKeyPath()
 	}
 	return result, nil
 }
@@ -29,12 +32,14 @@ func (p *Parser) parseValueList(obj interface{}) (mj.JSONValueList, error) {
 		return mj.JSONValueList{}, errors.New("not a JSON list")
 	}
 	var result []mj.JSONBytesFromString
-	for _, elemRaw := range listRaw.AsList() {
+	for i, elemRaw := range listRaw.AsList() {
+		p.enterIndex(i, elemRaw)
 		ba, err := p.processStringAsByteArray(elemRaw)
 		if err != nil {
 			return mj.JSONValueList{}, err
 		}
 		result = append(result, ba)
+		p.exitKeyPath()
 	}
 	return mj.JSONValueList{
 		Values: result,
@@ -47,12 +52,34 @@ func (p *Parser) parseSubTreeList(obj interface{}) ([]mj.JSONBytesFromTree, erro
 		return nil, errors.New("not a JSON list")
 	}
 	var result []mj.JSONBytesFromTree
-	for _, elemRaw := range listRaw.AsList() {
+	for i, elemRaw := range listRaw.AsList() {
+		p.enterIndex(i, elemRaw)
 		ba, err := p.processSubTreeAsByteArray(elemRaw)
 		if err != nil {
 			return nil, err
 		}
 		result = append(result, ba)
+		p.exitKeyPath()
+	}
+	return result, nil
+}
+
+// parseTypedArgumentList encodes endpoint arguments according to the ABI input types.
+func (p *Parser) parseTypedArgumentList(obj oj.OJsonObject, inputs []*ei.ABIParam, abi *ei.ABI) ([]mj.JSONBytesFromTree, error) {
+	listRaw, listOk := obj.(*oj.OJsonList)
+	if !listOk {
+		return nil, errors.New("not a JSON list")
+	}
+	values, err := p.ExprInterpreter.InterpretArguments(listRaw.AsList(), inputs, abi)
+	if err != nil {
+		return nil, err
+	}
+	var result []mj.JSONBytesFromTree
+	for i, elemRaw := range listRaw.AsList() {
+		result = append(result, mj.JSONBytesFromTree{
+			Value:    values[i],
+			Original: elemRaw,
+		})
 	}
 	return result, nil
 }
@@ -70,6 +97,7 @@ func (p *Parser) parseCheckValueList(obj oj.OJsonObject) (mj.JSONCheckValueList,
 	if !p.AllowSingleValueInCheckValueList {
 		return mj.JSONCheckValueList{}, errors.New("not a JSON list")
 	}
+	p.warn("single value instead of a list")
 
 	singleValue, err := p.parseCheckBytes(obj)
 	if err != nil {
@@ -90,12 +118,14 @@ func (p *Parser) parseCheckValueList(obj oj.OJsonObject) (mj.JSONCheckValueList,
 
 func (p *Parser) parseCheckValueJSONList(listRaw *oj.OJsonList) (mj.JSONCheckValueList, error) {
 	var values []mj.JSONCheckBytes
-	for _, elemRaw := range listRaw.AsList() {
+	for i, elemRaw := range listRaw.AsList() {
+		p.enterIndex(i, elemRaw)
 		checkBytes, err := p.parseCheckBytes(elemRaw)
 		if err != nil {
 			return mj.JSONCheckValueList{}, err
 		}
 		values = append(values, checkBytes)
+		p.exitKeyPath()
 	}
 	return mj.JSONCheckValueList{
 		Values: values,
@@ -27,50 +27,58 @@ func (p *Parser) processLogList(logsRaw oj.OJsonObject) (mj.LogList, error) {
 		List:             nil,
 	}
 	var err error
-	for _, logRaw := range logList.AsList() {
+	err = p.forEachItem(logList, func(_ int, logRaw oj.OJsonObject) error {
 		switch logItem := logRaw.(type) {
 		case *oj.OJsonString:
 			if logItem.Value == "+" {
 				result.MoreAllowedAtEnd = true
 			} else {
-				return mj.LogList{}, errors.New("unmarshalled log entry is an invalid string")
+				return errors.New("unmarshalled log entry is an invalid string")
 			}
 		case *oj.OJsonMap:
 			if result.MoreAllowedAtEnd {
-				return mj.LogList{}, errors.New("log entry ")
+				return errors.New("log entry ")
 			}
 
 			logEntry := mj.LogEntry{}
-			for _, kvp := range logItem.OrderedKV {
+			err = p.forEachField(logEntrySpec, logItem, func(kvp *oj.OJsonKeyValuePair) error {
 				switch kvp.Key {
 				case "address":
 					logEntry.Address, err = p.parseCheckBytes(kvp.Value)
 					if err != nil {
-						return mj.LogList{}, fmt.Errorf("invalid log address: %w", err)
+						return fmt.Errorf("invalid log address: %w", err)
 					}
 				case "endpoint":
 					logEntry.Endpoint, err = p.parseCheckBytes(kvp.Value)
 					if err != nil {
-						return mj.LogList{}, fmt.Errorf("invalid log identifier: %w", err)
+						return fmt.Errorf("invalid log identifier: %w", err)
 					}
 				case "topics":
 					logEntry.Topics, err = p.parseCheckValueList(kvp.Value)
 					if err != nil {
-						return mj.LogList{}, fmt.Errorf("invalid log entry topics: %w", err)
+						return fmt.Errorf("invalid log entry topics: %w", err)
 					}
 				case "data":
 					logEntry.Data, err = p.parseCheckValueList(kvp.Value)
 					if err != nil {
-						return mj.LogList{}, fmt.Errorf("invalid log data: %w", err)
+						return fmt.Errorf("invalid log data: %w", err)
 					}
 				default:
-					return mj.LogList{}, fmt.Errorf("unknown log field: %s", kvp.Key)
+					return errFieldNotHandled(kvp.Key)
 				}
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 			result.List = append(result.List, &logEntry)
 		default:
-			return mj.LogList{}, errors.New("log entry should be either string or object")
+			return errors.New("log entry should be either string or object")
 		}
+		return nil
+	})
+	if err != nil {
+		return mj.LogList{}, err
 	}
 
 	return result, nil
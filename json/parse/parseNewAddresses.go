@@ -3,6 +3,8 @@ package scenjsonparse
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
 	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
@@ -15,43 +17,147 @@ func (p *Parser) processNewAddressMocks(namsRaw oj.OJsonObject) ([]*mj.NewAddres
 	}
 	var namEntries []*mj.NewAddressMock
 	var err error
-	for _, namRaw := range namList.AsList() {
+	err = p.forEachItem(namList, func(_ int, namRaw oj.OJsonObject) error {
 		namMap, isMap := namRaw.(*oj.OJsonMap)
 		if !isMap {
-			return nil, errors.New("new address mock entry is not a map")
+			return errors.New("new address mock entry is not a map")
 		}
 		namEntry := mj.NewAddressMock{}
-		for _, kvp := range namMap.OrderedKV {
+		err = p.forEachField(newAddressMockSpec, namMap, func(kvp *oj.OJsonKeyValuePair) error {
 			switch kvp.Key {
 			case "creatorAddress":
 				caStr, err := p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("creatorAddress is not a json string: %w", err)
+					return fmt.Errorf("creatorAddress is not a json string: %w", err)
 				}
 				namEntry.CreatorAddress, err = p.parseAccountAddress(caStr)
 				if err != nil {
-					return nil, err
+					return err
 				}
 			case "creatorNonce":
 				namEntry.CreatorNonce, err = p.processUint64(kvp.Value)
 				if err != nil {
-					return nil, errors.New("invalid creatorNonce")
+					return errors.New("invalid creatorNonce")
 				}
 			case "newAddress":
 				naStr, err := p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("newAddress is not a json string: %w", err)
+					return fmt.Errorf("newAddress is not a json string: %w", err)
 				}
 				namEntry.NewAddress, err = p.parseAccountAddress(naStr)
 				if err != nil {
-					return nil, err
+					return err
 				}
 			default:
-				return nil, fmt.Errorf("unknown nam field: %s", kvp.Key)
+				return errFieldNotHandled(kvp.Key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if p.DeriveNewAddresses && len(namEntry.NewAddress.Original) == 0 {
+			namEntry.Derived = true
+			namEntry.NewAddress.Value, err = p.ExprInterpreter.DeriveSCAddress(
+				namEntry.CreatorAddress.Value, namEntry.CreatorNonce.Value)
+			if err != nil {
+				return fmt.Errorf("cannot derive newAddress: %w", err)
 			}
 		}
 		namEntries = append(namEntries, &namEntry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return namEntries, nil
 }
+
+// deployTracker follows the nonces of accounts through a scenario, to derive the addresses of deployed contracts.
+// Only the nonces set by the setState steps of the file, or by the transactions, are known.
+// Steps files start with none, and externalSteps steps forget them, since their transactions are not followed.
+type deployTracker struct {
+	nonces map[string]uint64
+	mocked map[string]bool
+}
+
+func newDeployTracker() *deployTracker {
+	return &deployTracker{
+		nonces: make(map[string]uint64),
+		mocked: make(map[string]bool),
+	}
+}
+
+func deployKey(creator []byte, nonce uint64) string {
+	return string(creator) + "#" + strconv.FormatUint(nonce, 10)
+}
+
+// startDeployTracking resets the nonces, when deriving new addresses. It yields the previous tracker, to restore.
+func (p *Parser) startDeployTracking() *deployTracker {
+	previous := p.deploys
+	p.deploys = nil
+	if p.DeriveNewAddresses {
+		p.deploys = newDeployTracker()
+	}
+	return previous
+}
+
+// trackDeploys updates the nonces after a step, and derives the address of contracts deployed without a mock.
+// It yields the step, preceded by a setState step registering the derived address, if any.
+func (p *Parser) trackDeploys(step mj.Step) ([]mj.Step, error) {
+	if p.deploys == nil {
+		return []mj.Step{step}, nil
+	}
+	switch typedStep := step.(type) {
+	case *mj.SetStateStep:
+		for _, account := range typedStep.Accounts {
+			p.deploys.nonces[string(account.Address.Value)] = account.Nonce.Value
+		}
+		for _, mock := range typedStep.NewAddressMocks {
+			p.deploys.mocked[deployKey(mock.CreatorAddress.Value, mock.CreatorNonce.Value)] = true
+		}
+	case *mj.ExternalStepsStep:
+		p.deploys.nonces = make(map[string]uint64)
+	case *mj.TxStep:
+		tx := typedStep.Tx
+		if tx == nil || !tx.Type.HasSender() {
+			// invalid transactions are only kept when collecting all errors
+			break
+		}
+		sender := string(tx.From.Value)
+		nonce, known := p.deploys.nonces[sender]
+		if len(tx.Nonce.Original) > 0 {
+			nonce = tx.Nonce.Value
+			known = true
+		}
+		if !known {
+			break
+		}
+		p.deploys.nonces[sender] = nonce + 1
+
+		if tx.Type != mj.ScDeploy || p.deploys.mocked[deployKey(tx.From.Value, nonce)] {
+			break
+		}
+		address, err := p.ExprInterpreter.DeriveSCAddress(tx.From.Value, nonce)
+		if err != nil {
+			return nil, fmt.Errorf("cannot derive deployed contract address: %w", err)
+		}
+		creator := tx.From.Original
+		if strings.ContainsAny(creator, "|()") {
+			creator = "(" + creator + ")"
+		}
+		nonceStr := strconv.FormatUint(nonce, 10)
+		newAddressStep := &mj.SetStateStep{
+			NewAddressMocks: []*mj.NewAddressMock{{
+				CreatorAddress: tx.From,
+				CreatorNonce:   mj.JSONUint64{Value: nonce, Original: nonceStr},
+				NewAddress:     mj.JSONBytesFromString{Value: address, Original: "sc-derived:" + creator + ":" + nonceStr},
+				Derived:        true,
+			}},
+			Derived: true,
+		}
+		return []mj.Step{newAddressStep, step}, nil
+	}
+	return []mj.Step{step}, nil
+}
@@ -3,14 +3,28 @@ package scenjsonparse
 import (
 	"errors"
 	"fmt"
+	"io"
 
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
 	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
 	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
 )
 
-// ParseScenarioFile converts a scenario json string to scenario object representation
+// ParseScenarioFile converts a scenario json string to scenario object representation.
+// The input must be strict JSON, or JSONC if AllowJSONC is set: strings cannot contain raw control characters,
+// such as tabs or newlines, nor unknown escape sequences, and the escape sequences they do contain are decoded.
+// Errors are of type *ParseError.
 func (p *Parser) ParseScenarioFile(jsonString []byte) (*mj.Scenario, error) {
-	jobj, err := oj.ParseOrderedJSON(jsonString)
+	p.resetKeyPath()
+	scenario, err := p.parseScenarioFile(jsonString)
+	if err != nil {
+		return nil, p.wrapParseError(err)
+	}
+	return scenario, nil
+}
+
+func (p *Parser) parseScenarioFile(jsonString []byte) (*mj.Scenario, error) {
+	jobj, err := p.parseOrderedJSON(jsonString)
 	if err != nil {
 		return nil, err
 	}
@@ -20,51 +34,300 @@ func (p *Parser) ParseScenarioFile(jsonString []byte) (*mj.Scenario, error) {
 		return nil, errors.New("unmarshalled test top level object is not a map")
 	}
 
-	scenario := &mj.Scenario{
+	inheritedConstants := p.ExprInterpreter.Constants
+	previousDeploys := p.startDeployTracking()
+	defer func() {
+		p.ExprInterpreter.Constants = inheritedConstants
+		p.deploys = previousDeploys
+	}()
+
+	scenario := newScenario()
+	if p.AllowJSONC {
+		scenario.Source = jobj
+	}
+	stepsSeen := false
+	err = p.forEachEntry(topMap, func(kvp *oj.OJsonKeyValuePair) error {
+		if kvp.Key == "constants" && stepsSeen {
+			return errConstantsAfterSteps
+		}
+		stepsSeen = stepsSeen || kvp.Key == "steps"
+		return p.processScenarioField(scenario, kvp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scenario, nil
+}
+
+// ParseScenarioReader reads and converts a scenario json from a stream.
+// It accepts the same input as ParseScenarioFile.
+// Errors are of type *ParseError.
+func (p *Parser) ParseScenarioReader(reader io.Reader) (*mj.Scenario, error) {
+	if p.AllowJSONC {
+		// the full source tree is retained anyway
+		jsonString, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		return p.ParseScenarioFile(jsonString)
+	}
+	return p.ParseScenarioStream(reader, nil)
+}
+
+// ParseScenarioStream reads a scenario json from a stream, without building the full JSON tree in memory.
+// Steps are parsed one at a time and passed to handleStep as soon as they are complete,
+// in which case they are not retained in the returned scenario.
+// If handleStep is nil, the steps are collected in the returned scenario.
+// The source tree is not retained, even in JSONC mode.
+// It accepts the same input as ParseScenarioFile.
+// Errors are of type *ParseError, except for errors returned by handleStep, which are passed through.
+func (p *Parser) ParseScenarioStream(reader io.Reader, handleStep func(mj.Step) error) (*mj.Scenario, error) {
+	p.resetKeyPath()
+	var handlerErr error
+	var stepHandler func(mj.Step) error
+	if handleStep != nil {
+		stepHandler = func(step mj.Step) error {
+			handlerErr = handleStep(step)
+			return handlerErr
+		}
+	}
+	scenario, err := p.parseScenarioStream(reader, stepHandler)
+	if err != nil {
+		if handlerErr != nil {
+			return nil, handlerErr
+		}
+		return nil, p.wrapParseError(err)
+	}
+	return scenario, nil
+}
+
+func (p *Parser) parseScenarioStream(reader io.Reader, handleStep func(mj.Step) error) (*mj.Scenario, error) {
+	decoder := p.newDecoder(reader)
+	isMap, err := decoder.IsNextMap()
+	if err != nil {
+		return nil, err
+	}
+	if !isMap {
+		return nil, errors.New("unmarshalled test top level object is not a map")
+	}
+
+	inheritedConstants := p.ExprInterpreter.Constants
+	previousDeploys := p.startDeployTracking()
+	defer func() {
+		p.ExprInterpreter.Constants = inheritedConstants
+		p.deploys = previousDeploys
+	}()
+
+	scenario := newScenario()
+	seenKeys := make(map[string]bool)
+	_, err = decoder.DecodeMapStream(func(key string, keySpan oj.Span) error {
+		kvp := &oj.OJsonKeyValuePair{Key: key, KeySpan: keySpan}
+		if seenKeys[key] {
+			// duplicate keys are ignored, same as when parsing the full tree
+			_, err := decoder.Decode()
+			return err
+		}
+		seenKeys[key] = true
+
+		depth := len(p.keyPath)
+		p.enterKey(kvp)
+		err := p.processScenarioEntryStream(decoder, scenario, kvp, seenKeys, handleStep)
+		if err != nil {
+			return p.recoverFrom(err, depth)
+		}
+		p.exitKeyPath()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = decoder.ExpectEnd()
+	if err != nil {
+		return nil, err
+	}
+	return scenario, nil
+}
+
+// processScenarioEntryStream consumes the value of the entry, even if it fails, so that the stream can go on when collecting all errors.
+func (p *Parser) processScenarioEntryStream(
+	decoder *oj.Decoder,
+	scenario *mj.Scenario,
+	kvp *oj.OJsonKeyValuePair,
+	seenKeys map[string]bool,
+	handleStep func(mj.Step) error) error {
+
+	if kvp.Key == "steps" {
+		err := p.processScenarioStepStream(decoder, scenario, handleStep)
+		if err != nil {
+			return fmt.Errorf("error processing steps: %w", err)
+		}
+		return nil
+	}
+
+	var err error
+	kvp.Value, err = decoder.Decode()
+	if err != nil {
+		return err
+	}
+	if kvp.Key == "constants" && seenKeys["steps"] {
+		return errConstantsAfterSteps
+	}
+	return p.processScenarioField(scenario, kvp)
+}
+
+func (p *Parser) processScenarioStepStream(
+	decoder *oj.Decoder,
+	scenario *mj.Scenario,
+	handleStep func(mj.Step) error) error {
+
+	isList, err := decoder.IsNextList()
+	if err != nil {
+		return err
+	}
+	if !isList {
+		_, err = decoder.Decode()
+		if err != nil {
+			return err
+		}
+		return errors.New("steps not a JSON list")
+	}
+	_, err = decoder.DecodeListStream(func(index int) error {
+		elemRaw, err := decoder.Decode()
+		if err != nil {
+			return err
+		}
+		depth := len(p.keyPath)
+		p.enterIndex(index, elemRaw)
+		step, err := p.processScenarioStep(elemRaw)
+		var steps []mj.Step
+		if err == nil {
+			steps, err = p.trackDeploys(step)
+		}
+		if err != nil {
+			return p.recoverFrom(err, depth)
+		}
+		if handleStep == nil {
+			scenario.Steps = append(scenario.Steps, steps...)
+		} else {
+			for _, step := range steps {
+				err = handleStep(step)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		p.exitKeyPath()
+		return nil
+	})
+	return err
+}
+
+func newScenario() *mj.Scenario {
+	return &mj.Scenario{
 		CheckGas:    true,
 		TraceGas:    false,
 		GasSchedule: mj.GasScheduleDefault,
 	}
+}
 
-	for _, kvp := range topMap.OrderedKV {
-		switch kvp.Key {
-		case "name":
-			scenario.Name, err = p.parseString(kvp.Value)
-			if err != nil {
-				return nil, fmt.Errorf("bad scenario name: %w", err)
-			}
-		case "comment":
-			scenario.Comment, err = p.parseString(kvp.Value)
-			if err != nil {
-				return nil, fmt.Errorf("bad scenario comment: %w", err)
-			}
-		case "checkGas":
-			checkGasOJ, isBool := kvp.Value.(*oj.OJsonBool)
-			if !isBool {
-				return nil, errors.New("scenario checkGas flag is not boolean")
-			}
-			scenario.CheckGas = bool(*checkGasOJ)
-		case "traceGas":
-			traceGasOJ, isBool := kvp.Value.(*oj.OJsonBool)
-			if !isBool {
-				return nil, errors.New("scenario traceGas flag is not boolean")
-			}
-			scenario.TraceGas = bool(*traceGasOJ)
-		case "gasSchedule":
-			scenario.GasSchedule, err = p.parseGasSchedule(kvp.Value)
-			if err != nil {
-				return nil, fmt.Errorf("bad scenario gasSchedule: %w", err)
-			}
-		case "steps":
-			scenario.Steps, err = p.processScenarioStepList(kvp.Value)
-			if err != nil {
-				return nil, fmt.Errorf("error processing steps: %w", err)
-			}
-		default:
-			return nil, fmt.Errorf("unknown scenario field: %s", kvp.Key)
+func (p *Parser) processScenarioField(scenario *mj.Scenario, kvp *oj.OJsonKeyValuePair) error {
+	if scenarioSpec.Field(kvp.Key) == nil {
+		return scenarioSpec.unknownFieldError(kvp.Key)
+	}
+
+	var err error
+	switch kvp.Key {
+	case "name":
+		scenario.Name, err = p.parseString(kvp.Value)
+		if err != nil {
+			return fmt.Errorf("bad scenario name: %w", err)
+		}
+	case "comment":
+		scenario.Comment, err = p.parseString(kvp.Value)
+		if err != nil {
+			return fmt.Errorf("bad scenario comment: %w", err)
 		}
+	case "tags":
+		scenario.Tags, err = p.processStringList(kvp.Value)
+		if err != nil {
+			return fmt.Errorf("bad scenario tags: %w", err)
+		}
+	case "checkGas":
+		checkGasOJ, isBool := kvp.Value.(*oj.OJsonBool)
+		if !isBool {
+			return errors.New("scenario checkGas flag is not boolean")
+		}
+		scenario.CheckGas = checkGasOJ.Value
+	case "traceGas":
+		traceGasOJ, isBool := kvp.Value.(*oj.OJsonBool)
+		if !isBool {
+			return errors.New("scenario traceGas flag is not boolean")
+		}
+		scenario.TraceGas = traceGasOJ.Value
+	case "gasSchedule":
+		scenario.GasSchedule, err = p.parseGasSchedule(kvp.Value)
+		if err != nil {
+			return fmt.Errorf("bad scenario gasSchedule: %w", err)
+		}
+	case "constants":
+		scenario.Constants, err = p.processConstants(kvp.Value)
+		if err != nil {
+			return err
+		}
+	case "steps":
+		scenario.Steps, err = p.processScenarioStepList(kvp.Value)
+		if err != nil {
+			return fmt.Errorf("error processing steps: %w", err)
+		}
+	default:
+		return errFieldNotHandled(kvp.Key)
 	}
-	return scenario, nil
+	return nil
+}
+
+// errConstantsAfterSteps prevents using constants before they are declared, also when streaming steps.
+var errConstantsAfterSteps = errors.New("constants must be declared before the steps")
+
+// processConstants adds the scenario constants to the ones in scope, which they shadow.
+func (p *Parser) processConstants(obj oj.OJsonObject) ([]*mj.Constant, error) {
+	constantsMap, isMap := obj.(*oj.OJsonMap)
+	if !isMap {
+		return nil, errors.New("constants not a JSON map")
+	}
+
+	scope := make(map[string]string)
+	for name, value := range p.ExprInterpreter.Constants {
+		scope[name] = value
+	}
+	var constants []*mj.Constant
+	err := p.forEachEntry(constantsMap, func(kvp *oj.OJsonKeyValuePair) error {
+		err := ei.CheckConstantName(kvp.Key)
+		if err != nil {
+			return err
+		}
+		value, err := p.parseString(kvp.Value)
+		if err != nil {
+			return fmt.Errorf("bad constant value: %w", err)
+		}
+		constants = append(constants, &mj.Constant{Name: kvp.Key, Value: value})
+		scope[kvp.Key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.ExprInterpreter.Constants = scope
+
+	// constants can refer to each other, so they are only checked once all are in scope
+	err = p.forEachEntry(constantsMap, func(kvp *oj.OJsonKeyValuePair) error {
+		_, err := p.ExprInterpreter.InterpretString("var:" + kvp.Key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return constants, nil
 }
 
 func (p *Parser) parseGasSchedule(value oj.OJsonObject) (mj.GasSchedule, error) {
@@ -92,12 +355,20 @@ func (p *Parser) processScenarioStepList(obj interface{}) ([]mj.Step, error) {
 		return nil, errors.New("steps not a JSON list")
 	}
 	var stepList []mj.Step
-	for _, elemRaw := range listRaw.AsList() {
+	err := p.forEachItem(listRaw, func(_ int, elemRaw oj.OJsonObject) error {
 		step, err := p.processScenarioStep(elemRaw)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		steps, err := p.trackDeploys(step)
+		if err != nil {
+			return err
 		}
-		stepList = append(stepList, step)
+		stepList = append(stepList, steps...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return stepList, nil
 }
@@ -105,12 +376,17 @@ func (p *Parser) processScenarioStepList(obj interface{}) ([]mj.Step, error) {
 // ParseScenarioStep parses a single scenario step, instead of an entire file.
 // Handy for tests, where step snippets can be embedded in code.
 func (p *Parser) ParseScenarioStep(jsonSnippet string) (mj.Step, error) {
-	jobj, err := oj.ParseOrderedJSON([]byte(jsonSnippet))
+	p.resetKeyPath()
+	jobj, err := p.parseOrderedJSON([]byte(jsonSnippet))
 	if err != nil {
-		return nil, err
+		return nil, p.wrapParseError(err)
 	}
 
-	return p.processScenarioStep(jobj)
+	step, err := p.processScenarioStep(jobj)
+	if err != nil {
+		return nil, p.wrapParseError(err)
+	}
+	return step, nil
 }
 
 func (p *Parser) processScenarioStep(stepObj oj.OJsonObject) (mj.Step, error) {
@@ -122,12 +398,14 @@ func (p *Parser) processScenarioStep(stepObj oj.OJsonObject) (mj.Step, error) {
 	var err error
 	stepTypeStr := ""
 	for _, kvp := range stepMap.OrderedKV {
+		p.enterKey(kvp)
 		if kvp.Key == "step" {
 			stepTypeStr, err = p.parseString(kvp.Value)
 			if err != nil {
 				return nil, fmt.Errorf("step type not a string: %w", err)
 			}
 		}
+		p.exitKeyPath()
 	}
 
 	switch stepTypeStr {
@@ -135,21 +413,21 @@ func (p *Parser) processScenarioStep(stepObj oj.OJsonObject) (mj.Step, error) {
 		return nil, errors.New("no step type field provided")
 	case mj.StepNameExternalSteps:
 		traceGasStatus := mj.Undefined
-		step := &mj.ExternalStepsStep{TraceGas: traceGasStatus}
-		for _, kvp := range stepMap.OrderedKV {
+		step := &mj.ExternalStepsStep{TraceGas: traceGasStatus, Constants: p.ExprInterpreter.Constants}
+		err = p.forEachField(externalStepsSpec, stepMap, func(kvp *oj.OJsonKeyValuePair) error {
 			switch kvp.Key {
 			case "step":
 			case "comment":
 				step.Comment, err = p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("bad externalSteps step comment: %w", err)
+					return fmt.Errorf("bad externalSteps step comment: %w", err)
 				}
 			case "traceGas":
 				traceGasOJ, isBool := kvp.Value.(*oj.OJsonBool)
 				if !isBool {
-					return nil, errors.New("scenario traceGas flag is not boolean")
+					return errors.New("scenario traceGas flag is not boolean")
 				}
-				if *traceGasOJ {
+				if traceGasOJ.Value {
 					step.TraceGas = 1
 				} else {
 					step.TraceGas = 0
@@ -157,96 +435,112 @@ func (p *Parser) processScenarioStep(stepObj oj.OJsonObject) (mj.Step, error) {
 			case "path":
 				step.Path, err = p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("bad externalSteps path: %w", err)
+					return fmt.Errorf("bad externalSteps path: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("invalid externalSteps field: %s", kvp.Key)
+				return errFieldNotHandled(kvp.Key)
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 		return step, nil
 	case mj.StepNameSetState:
 		step := &mj.SetStateStep{}
-		for _, kvp := range stepMap.OrderedKV {
+		err = p.forEachField(setStateSpec, stepMap, func(kvp *oj.OJsonKeyValuePair) error {
 			switch kvp.Key {
 			case "step":
 			case "id":
 				step.SetStateIdent, err = p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("bad tx set state step id: %w", err)
+					return fmt.Errorf("bad tx set state step id: %w", err)
 				}
 			case "comment":
 				step.Comment, err = p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("bad set state step comment: %w", err)
+					return fmt.Errorf("bad set state step comment: %w", err)
 				}
 			case "accounts":
 				step.Accounts, err = p.processAccountMap(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("cannot parse set state step: %w", err)
+					return fmt.Errorf("cannot parse set state step: %w", err)
 				}
 			case "newAddresses":
 				step.NewAddressMocks, err = p.processNewAddressMocks(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("error parsing new addresses: %w", err)
+					return fmt.Errorf("error parsing new addresses: %w", err)
 				}
 			case "previousBlockInfo":
 				step.PreviousBlockInfo, err = p.processBlockInfo(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("error parsing previousBlockInfo: %w", err)
+					return fmt.Errorf("error parsing previousBlockInfo: %w", err)
 				}
 			case "currentBlockInfo":
 				step.CurrentBlockInfo, err = p.processBlockInfo(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("error parsing currentBlockInfo: %w", err)
+					return fmt.Errorf("error parsing currentBlockInfo: %w", err)
 				}
 			case "blockHashes":
 				step.BlockHashes, err = p.parseValueList(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("error parsing block hashes: %w", err)
+					return fmt.Errorf("error parsing block hashes: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("invalid set state field: %s", kvp.Key)
+				return errFieldNotHandled(kvp.Key)
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 		return step, nil
 	case mj.StepNameCheckState:
 		step := &mj.CheckStateStep{}
-		for _, kvp := range stepMap.OrderedKV {
+		err = p.forEachField(checkStateSpec, stepMap, func(kvp *oj.OJsonKeyValuePair) error {
 			switch kvp.Key {
 			case "step":
 			case "id":
 				step.CheckStateIdent, err = p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("bad check state step id: %w", err)
+					return fmt.Errorf("bad check state step id: %w", err)
 				}
 			case "comment":
 				step.Comment, err = p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("bad check state step comment: %w", err)
+					return fmt.Errorf("bad check state step comment: %w", err)
 				}
 			case "accounts":
 				step.CheckAccounts, err = p.processCheckAccountMap(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("cannot parse check state step: %w", err)
+					return fmt.Errorf("cannot parse check state step: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("invalid check state field: %s", kvp.Key)
+				return errFieldNotHandled(kvp.Key)
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 		return step, nil
 	case mj.StepNameDumpState:
 		step := &mj.DumpStateStep{}
-		for _, kvp := range stepMap.OrderedKV {
+		err = p.forEachField(dumpStateSpec, stepMap, func(kvp *oj.OJsonKeyValuePair) error {
 			switch kvp.Key {
 			case "step":
 			case "comment":
 				step.Comment, err = p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("bad check state step comment: %w", err)
+					return fmt.Errorf("bad check state step comment: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("invalid check state field: %s", kvp.Key)
+				return errFieldNotHandled(kvp.Key)
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 		return step, nil
 	case mj.StepNameScCall:
@@ -269,7 +563,7 @@ func (p *Parser) processScenarioStep(stepObj oj.OJsonObject) (mj.Step, error) {
 func (p *Parser) parseTxStep(txType mj.TransactionType, stepMap *oj.OJsonMap) (*mj.TxStep, error) {
 	step := &mj.TxStep{}
 	var err error
-	for _, kvp := range stepMap.OrderedKV {
+	err = p.forEachField(txStepSpecs[txType], stepMap, func(kvp *oj.OJsonKeyValuePair) error {
 		switch kvp.Key {
 		case "step":
 		case "txId":
@@ -277,34 +571,42 @@ func (p *Parser) parseTxStep(txType mj.TransactionType, stepMap *oj.OJsonMap) (*
 		case "id":
 			step.TxIdent, err = p.parseString(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("bad tx step id: %w", err)
+				return fmt.Errorf("bad tx step id: %w", err)
 			}
 		case "displayLogs":
 			step.DisplayLogs, err = p.parseBool(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("bad tx step displayLogs: %w", err)
+				return fmt.Errorf("bad tx step displayLogs: %w", err)
 			}
 		case "comment":
 			step.Comment, err = p.parseString(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("bad tx step comment: %w", err)
+				return fmt.Errorf("bad tx step comment: %w", err)
 			}
 		case "tx":
 			step.Tx, err = p.processTx(txType, kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("cannot parse tx step transaction: %w", err)
+				return fmt.Errorf("cannot parse tx step transaction: %w", err)
 			}
 		case "expect":
+			if step.Tx == nil {
+				// the tx is either missing, invalid, or comes after the expect
+				return errors.New("expect without a valid tx")
+			}
 			if !step.Tx.Type.IsSmartContractTx() {
-				return nil, fmt.Errorf("no expected result allowed for step of type %s", step.StepTypeName())
+				return fmt.Errorf("no expected result allowed for step of type %s", step.StepTypeName())
 			}
 			step.ExpectedResult, err = p.processTxExpectedResult(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("cannot parse tx expected result: %w", err)
+				return fmt.Errorf("cannot parse tx expected result: %w", err)
 			}
 		default:
-			return nil, fmt.Errorf("invalid tx step field: %s", kvp.Key)
+			return errFieldNotHandled(kvp.Key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return step, nil
 }
@@ -8,10 +8,19 @@ import (
 	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
 )
 
-// ParseTestFile converts json string to object representation
+// ParseTestFile converts json string to object representation.
+// Errors are of type *ParseError.
 func (p *Parser) ParseTestFile(jsonString []byte) ([]*mj.Test, error) {
+	p.resetKeyPath()
+	top, err := p.parseTestFile(jsonString)
+	if err != nil {
+		return nil, p.wrapParseError(err)
+	}
+	return top, nil
+}
 
-	jobj, err := oj.ParseOrderedJSON(jsonString)
+func (p *Parser) parseTestFile(jsonString []byte) ([]*mj.Test, error) {
+	jobj, err := p.parseOrderedJSON(jsonString)
 	if err != nil {
 		return nil, err
 	}
@@ -22,13 +31,17 @@ func (p *Parser) ParseTestFile(jsonString []byte) ([]*mj.Test, error) {
 	}
 
 	var top []*mj.Test
-	for _, kvp := range topMap.OrderedKV {
+	err = p.forEachEntry(topMap, func(kvp *oj.OJsonKeyValuePair) error {
 		t, tErr := p.processTest(kvp.Value)
 		if tErr != nil {
-			return nil, tErr
+			return tErr
 		}
 		t.TestName = kvp.Key
 		top = append(top, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return top, nil
 }
@@ -41,50 +54,58 @@ func (p *Parser) processTest(testObj oj.OJsonObject) (*mj.Test, error) {
 	test := mj.Test{CheckGas: true}
 
 	var err error
-	for _, kvp := range testMap.OrderedKV {
+	err = p.forEachField(testSpec, testMap, func(kvp *oj.OJsonKeyValuePair) error {
 		switch kvp.Key {
 		case "checkGas":
 			checkGasOJ, isBool := kvp.Value.(*oj.OJsonBool)
 			if !isBool {
-				return nil, errors.New("unmarshalled test checkGas flag is not boolean")
+				return errors.New("unmarshalled test checkGas flag is not boolean")
 			}
-			test.CheckGas = bool(*checkGasOJ)
+			test.CheckGas = checkGasOJ.Value
 		case "pre":
 			test.Pre, err = p.processAccountMap(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("cannot parse pre: %w", err)
+				return fmt.Errorf("cannot parse pre: %w", err)
 			}
 		case "blocks":
 			blocksRaw, blocksOk := kvp.Value.(*oj.OJsonList)
 			if !blocksOk {
-				return nil, errors.New("unmarshalled blocks object is not a list")
+				return errors.New("unmarshalled blocks object is not a list")
 			}
-			for _, blRaw := range blocksRaw.AsList() {
+			err = p.forEachItem(blocksRaw, func(_ int, blRaw oj.OJsonObject) error {
 				bl, blErr := p.processBlock(blRaw)
 				if blErr != nil {
-					return nil, blErr
+					return blErr
 				}
 				test.Blocks = append(test.Blocks, bl)
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 		case "network":
 			test.Network, err = p.parseString(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("test network value not a string: %w", err)
+				return fmt.Errorf("test network value not a string: %w", err)
 			}
 
 		case "blockHashes":
 			test.BlockHashes, err = p.parseValueList(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("unmarshalled blockHashes object is not a list: %w", err)
+				return fmt.Errorf("unmarshalled blockHashes object is not a list: %w", err)
 			}
 		case "postState":
 			test.PostState, err = p.processCheckAccountMap(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("cannot parse postState: %w", err)
+				return fmt.Errorf("cannot parse postState: %w", err)
 			}
 		default:
-			return nil, fmt.Errorf("unknown test: %s", kvp.Key)
+			return errFieldNotHandled(kvp.Key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &test, nil
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
 	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
 	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
 )
@@ -21,117 +22,175 @@ func (p *Parser) processTx(txType mj.TransactionType, blrRaw oj.OJsonObject) (*m
 	}
 
 	var err error
-	for _, kvp := range bltMap.OrderedKV {
+	err = p.forEachField(transactionSpec, bltMap, func(kvp *oj.OJsonKeyValuePair) error {
 
 		switch kvp.Key {
 		case "nonce":
 			blt.Nonce, err = p.processUint64(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction nonce: %w", err)
+				return fmt.Errorf("invalid transaction nonce: %w", err)
 			}
 		case "from":
 			if !txType.HasSender() {
-				return nil, errors.New("`from` not allowed in transaction, it is always the zero address")
+				return errors.New("`from` not allowed in transaction, it is always the zero address")
 			}
 			fromStr, err := p.parseString(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction from: %w", err)
+				return fmt.Errorf("invalid transaction from: %w", err)
 			}
 			var fromErr error
 			blt.From, fromErr = p.parseAccountAddress(fromStr)
 			if fromErr != nil {
-				return nil, fromErr
+				return fromErr
 			}
 		case "to":
 			toStr, err := p.parseString(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction to: %w", err)
+				return fmt.Errorf("invalid transaction to: %w", err)
 			}
 
 			if txType == mj.ScDeploy {
 				if len(toStr) > 0 {
-					return nil, errors.New("transaction to field not allowed for scDeploy transactions")
+					return errors.New("transaction to field not allowed for scDeploy transactions")
 				}
 			} else {
 				blt.To, err = p.parseAccountAddress(toStr)
 				if err != nil {
-					return nil, err
+					return err
 				}
 			}
 		case "function":
 			blt.Function, err = p.parseString(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction function: %w", err)
+				return fmt.Errorf("invalid transaction function: %w", err)
 			}
 			if !txType.HasFunction() && len(blt.Function) > 0 {
-				return nil, errors.New("transaction function field not allowed in this context")
+				return errors.New("transaction function field not allowed in this context")
 			}
 		case "value":
 			// backwards compatibility
 			fallthrough
 		case "moaxValue":
 			if !txType.HasValue() {
-				return nil, errors.New("`moaxValue` not allowed in this context")
+				return errors.New("`moaxValue` not allowed in this context")
 			}
 			blt.MOAXValue, err = p.processBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction moaxValue: %w", err)
+				return fmt.Errorf("invalid transaction moaxValue: %w", err)
 			}
 		case "dct":
 			// backwards compatibility
 			fallthrough
 		case "dctValue":
 			if !txType.HasDCT() {
-				return nil, errors.New("`dctValue` not allowed in this context")
+				return errors.New("`dctValue` not allowed in this context")
 			}
 			blt.DCTValue, err = p.processTxDCT(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction dctValue: %w", err)
+				return fmt.Errorf("invalid transaction dctValue: %w", err)
+			}
+		case "abi":
+			blt.ABIPath, err = p.parseString(kvp.Value)
+			if err != nil {
+				return fmt.Errorf("invalid transaction abi: %w", err)
 			}
 		case "arguments":
-			blt.Arguments, err = p.parseSubTreeList(kvp.Value)
+			abi, endpoint, err := p.txABIEndpoint(txType, bltMap)
+			if err != nil {
+				return err
+			}
+			if endpoint != nil {
+				blt.Arguments, err = p.parseTypedArgumentList(kvp.Value, endpoint.Inputs, abi)
+			} else {
+				blt.Arguments, err = p.parseSubTreeList(kvp.Value)
+			}
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction arguments: %w", err)
+				return fmt.Errorf("invalid transaction arguments: %w", err)
 			}
 			if txType == mj.Transfer && len(blt.Arguments) > 0 {
-				return nil, errors.New("function arguments not allowed for transfer transactions")
+				return errors.New("function arguments not allowed for transfer transactions")
 			}
 		case "contractCode":
 			blt.Code, err = p.processStringAsByteArray(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction contract code: %w", err)
+				return fmt.Errorf("invalid transaction contract code: %w", err)
 			}
 			if txType != mj.ScDeploy && txType != mj.ScUpgrade && len(blt.Code.Value) > 0 {
-				return nil, errors.New("transaction contractCode field only allowed in scDeploy or scUpgrade transactions")
+				return errors.New("transaction contractCode field only allowed in scDeploy or scUpgrade transactions")
 			}
 		case "codeMetadata":
 			blt.CodeMetadata, err = p.processStringAsByteArray(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction contract codeMetadata: %w", err)
+				return fmt.Errorf("invalid transaction contract codeMetadata: %w", err)
 			}
 			if txType != mj.ScDeploy && txType != mj.ScUpgrade && len(blt.CodeMetadata.Value) > 0 {
-				return nil, errors.New("transaction codeMetadata field only allowed in scDeploy or scUpgrade transactions")
+				return errors.New("transaction codeMetadata field only allowed in scDeploy or scUpgrade transactions")
 			}
 		case "gasLimit":
 			if !txType.HasGasLimit() {
-				return nil, errors.New("`gasLimit` not allowed in this context")
+				return errors.New("`gasLimit` not allowed in this context")
 			}
 			blt.GasLimit, err = p.processUint64(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction gasLimit: %w", err)
+				return fmt.Errorf("invalid transaction gasLimit: %w", err)
 			}
 		case "gasPrice":
 			if !txType.HasGasPrice() {
-				return nil, errors.New("`gasPrice` not allowed in this context")
+				return errors.New("`gasPrice` not allowed in this context")
 			}
 			blt.GasPrice, err = p.processUint64(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid transaction gasPrice: %w", err)
+				return fmt.Errorf("invalid transaction gasPrice: %w", err)
 			}
 		default:
-			return nil, fmt.Errorf("unknown field in transaction: %s", kvp.Key)
+			return errFieldNotHandled(kvp.Key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &blt, nil
 }
+
+// txABIEndpoint yields the endpoint called by the transaction, if it references an ABI, or nil otherwise.
+// Deployments call the constructor, upgrades the upgrade constructor.
+func (p *Parser) txABIEndpoint(txType mj.TransactionType, bltMap *oj.OJsonMap) (*ei.ABI, *ei.ABIEndpoint, error) {
+	var abiPath, function string
+	for _, kvp := range bltMap.OrderedKV {
+		str, isStr := kvp.Value.(*oj.OJsonString)
+		if !isStr {
+			continue
+		}
+		switch kvp.Key {
+		case "abi":
+			abiPath = str.Value
+		case "function":
+			function = str.Value
+		}
+	}
+	if len(abiPath) == 0 {
+		return nil, nil, nil
+	}
+
+	abi, err := p.ExprInterpreter.LoadABI(abiPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot load abi: %w", err)
+	}
+	var endpoint *ei.ABIEndpoint
+	switch txType {
+	case mj.ScDeploy:
+		endpoint = abi.Constructor
+		function = "constructor"
+	case mj.ScUpgrade:
+		endpoint = abi.Upgrade
+		function = "upgradeConstructor"
+	default:
+		endpoint = abi.Endpoint(function)
+	}
+	if endpoint == nil {
+		return nil, nil, fmt.Errorf("endpoint %s not found in abi %s", function, abiPath)
+	}
+	return abi, endpoint, nil
+}
@@ -22,41 +22,45 @@ func (p *Parser) processTxExpectedResult(blrRaw oj.OJsonObject) (*mj.Transaction
 		Logs:    mj.LogList{IsUnspecified: true, IsStar: true},
 	}
 	var err error
-	for _, kvp := range blrMap.OrderedKV {
+	err = p.forEachField(txResultSpec, blrMap, func(kvp *oj.OJsonKeyValuePair) error {
 		switch kvp.Key {
 		case "out":
 			blr.Out, err = p.parseCheckValueList(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block result out: %w", err)
+				return fmt.Errorf("invalid block result out: %w", err)
 			}
 		case "status":
 			blr.Status, err = p.processCheckBigInt(kvp.Value, bigIntSignedBytes)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block result status: %w", err)
+				return fmt.Errorf("invalid block result status: %w", err)
 			}
 		case "message":
 			blr.Message, err = p.parseCheckBytes(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block result message: %w", err)
+				return fmt.Errorf("invalid block result message: %w", err)
 			}
 		case "logs":
 			blr.Logs, err = p.processLogList(kvp.Value)
 			if err != nil {
-				return nil, err
+				return err
 			}
 		case "gas":
 			blr.Gas, err = p.processCheckUint64(kvp.Value)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block result gas: %w", err)
+				return fmt.Errorf("invalid block result gas: %w", err)
 			}
 		case "refund":
 			blr.Refund, err = p.processCheckBigInt(kvp.Value, bigIntUnsignedBytes)
 			if err != nil {
-				return nil, fmt.Errorf("invalid block result refund: %w", err)
+				return fmt.Errorf("invalid block result refund: %w", err)
 			}
 		default:
-			return nil, fmt.Errorf("unknown tx result field: %s", kvp.Key)
+			return errFieldNotHandled(kvp.Key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &blr, nil
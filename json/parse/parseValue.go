@@ -146,7 +146,7 @@ func (p *Parser) parseBool(obj oj.OJsonObject) (bool, error) {
 	if !isBool {
 		return false, errors.New("not a bool value")
 	}
-	return bool(*value), nil
+	return value.Value, nil
 }
 
 // IsStar returns whether check object is othe form "*".
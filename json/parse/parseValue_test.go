@@ -42,12 +42,12 @@ func TestBigInt(t *testing.T) {
 func TestParseBool(t *testing.T) {
 	p := Parser{}
 
-	objBool := oj.OJsonBool(false)
+	objBool := oj.OJsonBool{Value: false}
 	valueBool, err := p.parseBool(&objBool)
 	require.Nil(t, err)
 	require.Equal(t, false, valueBool)
 
-	objBool = true
+	objBool.Value = true
 	valueBool, err = p.parseBool(&objBool)
 	require.Nil(t, err)
 	require.Equal(t, true, valueBool)
@@ -1,8 +1,11 @@
 package scenjsonparse
 
 import (
+	"io"
+
 	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
 	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
 )
 
 // Parser performs parsing of both json tests (older) and scenarios (new).
@@ -12,6 +15,54 @@ type Parser struct {
 	AllowDctLegacySetSyntax          bool
 	AllowDctLegacyCheckSyntax        bool
 	AllowSingleValueInCheckValueList bool
+
+	// AllowJSONC enables JSON with comments and trailing commas.
+	// Parsed scenarios then keep their source tree, so that they can be written back with minimal changes.
+	AllowJSONC bool
+
+	// DeriveNewAddresses computes the addresses of deployed contracts as the protocol does, see "sc-derived:".
+	// The newAddress of newAddresses entries can then be left out,
+	// and scDeploy steps that no entry covers are preceded by a derived setState step, registering the address.
+	// Creator nonces are followed through the setState steps and transactions of the file.
+	// Deploys by accounts whose nonce is unknown, e.g. set in an external steps file, get no address.
+	DeriveNewAddresses bool
+
+	// SourcePath is the path of the file being parsed, only used in error messages.
+	SourcePath string
+
+	deploys          *deployTracker
+	keyPath          []keyPathElem
+	collectingErrors bool
+	diagnostics      []*Diagnostic
+}
+
+func (p *Parser) parseOrderedJSON(jsonString []byte) (oj.OJsonObject, error) {
+	if p.AllowJSONC {
+		return oj.ParseOrderedJSONC(jsonString)
+	}
+	return oj.ParseOrderedJSON(jsonString)
+}
+
+func (p *Parser) newDecoder(reader io.Reader) *oj.Decoder {
+	if p.AllowJSONC {
+		return oj.NewJSONCDecoder(reader)
+	}
+	return oj.NewDecoder(reader)
+}
+
+// Clone creates a parser with the same settings and a cloned FileResolver.
+// The clone can be used concurrently with the original.
+func (p *Parser) Clone() Parser {
+	return Parser{
+		ExprInterpreter:                  p.ExprInterpreter.Clone(),
+		AllowDctTxLegacySyntax:           p.AllowDctTxLegacySyntax,
+		AllowDctLegacySetSyntax:          p.AllowDctLegacySetSyntax,
+		AllowDctLegacyCheckSyntax:        p.AllowDctLegacyCheckSyntax,
+		AllowSingleValueInCheckValueList: p.AllowSingleValueInCheckValueList,
+		AllowJSONC:                       p.AllowJSONC,
+		DeriveNewAddresses:               p.DeriveNewAddresses,
+		SourcePath:                       p.SourcePath,
+	}
 }
 
 // NewParser provides a new Parser instance.
@@ -0,0 +1,3 @@
+package scenjsonschema
+
+//go:generate go run ../../cmd/scenschema -out .
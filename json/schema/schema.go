@@ -0,0 +1,309 @@
+package scenjsonschema
+
+import (
+	"regexp"
+	"strings"
+
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+)
+
+const draft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+const (
+	expressionDefName     = "valueExpression"
+	expressionTreeDefName = "valueExpressionTree"
+)
+
+// ScenarioSchema yields the JSON Schema of .scen.json files.
+// Expressions can call the functions registered with the interpreter.
+func ScenarioSchema(interpreter *ei.ExprInterpreter) oj.OJsonObject {
+	return newGenerator(interpreter).document(
+		"Scenario",
+		"A blockchain scenario, run step by step.",
+		&mjparse.ValueSpec{Kind: mjparse.ValueObject, Object: mjparse.ScenarioSpec()})
+}
+
+// StepsSchema yields the JSON Schema of .steps.json files, which are included in scenarios via externalSteps.
+func StepsSchema(interpreter *ei.ExprInterpreter) oj.OJsonObject {
+	return newGenerator(interpreter).document(
+		"Scenario steps",
+		"Steps included in other scenarios, via externalSteps.",
+		&mjparse.ValueSpec{Kind: mjparse.ValueObject, Object: mjparse.ScenarioSpec()})
+}
+
+// TestSchema yields the JSON Schema of .test.json files.
+func TestSchema(interpreter *ei.ExprInterpreter) oj.OJsonObject {
+	return newGenerator(interpreter).document(
+		"Tests",
+		"Tests in the older format, by name.",
+		mjparse.TestFileSpec())
+}
+
+// ExpressionPattern yields a regular expression matching value expressions.
+// It only checks the prefixes, the arguments are only checked when the value is interpreted.
+// Parenthesized groups are not checked either, since regular expressions cannot match nested parentheses,
+// nor are values referencing constants as "${name}", which can expand to anything.
+// The prefixes of the functions registered with the interpreter are accepted too:
+// their expression arguments only match if they contain no "|", or are the last part, in parentheses.
+func ExpressionPattern(interpreter *ei.ExprInterpreter) string {
+	var wholeValuePrefixes []string
+	for _, prefix := range ei.WholeValuePrefixes() {
+		wholeValuePrefixes = append(wholeValuePrefixes, regexp.QuoteMeta(prefix))
+	}
+	var stringPrefixes []string
+	for _, prefix := range ei.StringPrefixes() {
+		stringPrefixes = append(stringPrefixes, regexp.QuoteMeta(prefix))
+	}
+	var partPrefixes []string
+	for _, prefix := range ei.Prefixes() {
+		if !containsString(ei.WholeValuePrefixes(), prefix) && !containsString(ei.StringPrefixes(), prefix) {
+			partPrefixes = append(partPrefixes, regexp.QuoteMeta(prefix))
+		}
+	}
+	var functionPrefixes []string
+	for _, prefix := range interpreter.FunctionPrefixes() {
+		functionPrefixes = append(functionPrefixes, regexp.QuoteMeta(prefix))
+	}
+	functionPart, functionGroup := "", ""
+	if len(functionPrefixes) > 0 {
+		functions := `(?:` + strings.Join(functionPrefixes, "|") + `)`
+		functionPart = `|` + functions + `[^|]*`
+		functionGroup = `|` + functions + `[^|]*\(.*`
+	}
+
+	// arithmetic starts with a number, a parenthesis, min, max or a denomination,
+	// then only contains operators, digits and the letters of the function and denomination names
+	arithmeticStarts := []string{`[0-9]`, `(?:min|max)\(`}
+	arithmeticLetters := "minax"
+	for _, denomination := range ei.Denominations() {
+		arithmeticStarts = append(arithmeticStarts, regexp.QuoteMeta(denomination.Prefix()))
+		arithmeticLetters += denomination.Name
+	}
+	arithmetic := `[-+( ]*(?:` + strings.Join(arithmeticStarts, "|") + `)[-+*/%(), 0-9a-fA-FxXbB_.:` + arithmeticLetters + `]*`
+
+	part := `(?:(?:` + strings.Join(stringPrefixes, "|") + `)(?:[^\\|]|\\.?)*` +
+		`|(?:` + strings.Join(partPrefixes, "|") + `)[^|]*` +
+		functionPart +
+		`|[+-]?[0-9][0-9_,]*(?:\.[0-9_,]*)?(?:[eE][+-]?[0-9]+)?` +
+		`|` + arithmetic +
+		`|0[xX][0-9a-fA-F_,]*` +
+		`|0[bB][01_,]*` +
+		`|true|false` +
+		`|)`
+	// whole value arguments and groups extend to the end of the expression
+	last := `(?:(?:` + strings.Join(wholeValuePrefixes, "|") + `).*|(?:nested:)*\(.*` + functionGroup + `|` + part + `)`
+	return `^(?:.*\$\{.*|(?:` + part + `\|)*` + last + `)$`
+}
+
+func containsString(list []string, str string) bool {
+	for _, elem := range list {
+		if elem == str {
+			return true
+		}
+	}
+	return false
+}
+
+// generator collects the definitions of objects while converting specs to schemas.
+type generator struct {
+	interpreter *ei.ExprInterpreter
+	defs        *oj.OJsonMap
+	generated   map[*mjparse.ObjectSpec]bool
+}
+
+func newGenerator(interpreter *ei.ExprInterpreter) *generator {
+	return &generator{
+		interpreter: interpreter,
+		defs:        oj.NewMap(),
+		generated:   make(map[*mjparse.ObjectSpec]bool),
+	}
+}
+
+func (g *generator) document(title string, description string, root *mjparse.ValueSpec) oj.OJsonObject {
+	rootSchema := g.valueSchema(root)
+
+	doc := oj.NewMap()
+	doc.Put("$schema", str(draft202012))
+	doc.Put("title", str(title))
+	doc.Put("description", str(description))
+	if rootMap, isMap := rootSchema.(*oj.OJsonMap); isMap {
+		for _, kvp := range rootMap.OrderedKV {
+			doc.Put(kvp.Key, kvp.Value)
+		}
+	}
+
+	g.putExpressionDefs()
+	doc.Put("$defs", g.defs)
+	return doc
+}
+
+func (g *generator) putExpressionDefs() {
+	expression := oj.NewMap()
+	expression.Put("description", str("A value expression, e.g. \"str:abc\", \"1,000\", \"address:owner\" or \"u32:5|u8:1\"."))
+	expression.Put("type", str("string"))
+	expression.Put("pattern", str(ExpressionPattern(g.interpreter)))
+	g.defs.Put(expressionDefName, expression)
+
+	tree := oj.NewMap()
+	tree.Put("description", str("A value expression, or a list or map of them, which get concatenated. Map keys are ignored."))
+	list := oj.NewMap()
+	list.Put("type", str("array"))
+	list.Put("items", ref(expressionTreeDefName))
+	object := oj.NewMap()
+	object.Put("type", str("object"))
+	object.Put("additionalProperties", ref(expressionTreeDefName))
+	tree.Put("anyOf", oj.NewList([]oj.OJsonObject{ref(expressionDefName), list, object}))
+	g.defs.Put(expressionTreeDefName, tree)
+}
+
+func (g *generator) valueSchema(spec *mjparse.ValueSpec) oj.OJsonObject {
+	schema := g.baseValueSchema(spec)
+	if spec.Star {
+		star := oj.NewMap()
+		star.Put("const", str("*"))
+		withStar := oj.NewMap()
+		withStar.Put("anyOf", oj.NewList([]oj.OJsonObject{star, schema}))
+		schema = withStar
+	}
+	if spec.Deprecated {
+		deprecated := oj.NewMap()
+		deprecated.Put("deprecated", oj.NewBool(true))
+		deprecated.Put("allOf", oj.NewList([]oj.OJsonObject{schema}))
+		schema = deprecated
+	}
+	return schema
+}
+
+func (g *generator) baseValueSchema(spec *mjparse.ValueSpec) oj.OJsonObject {
+	schema := oj.NewMap()
+	switch spec.Kind {
+	case mjparse.ValueString:
+		schema.Put("type", str("string"))
+	case mjparse.ValueBool:
+		schema.Put("type", str("boolean"))
+	case mjparse.ValueEnum:
+		if len(spec.Enum) == 1 {
+			schema.Put("const", str(spec.Enum[0]))
+		} else {
+			var values []oj.OJsonObject
+			for _, value := range spec.Enum {
+				values = append(values, str(value))
+			}
+			schema.Put("enum", oj.NewList(values))
+		}
+	case mjparse.ValueExpression:
+		return ref(expressionDefName)
+	case mjparse.ValueExpressionTree:
+		return ref(expressionTreeDefName)
+	case mjparse.ValueList:
+		schema.Put("type", str("array"))
+		items := g.valueSchema(spec.Items)
+		if spec.MoreAllowed {
+			items = anyOfWithPlus(items)
+		}
+		schema.Put("items", items)
+	case mjparse.ValueObject:
+		g.putObjectDef(spec.Object)
+		return ref(spec.Object.Name)
+	case mjparse.ValueMap:
+		schema.Put("type", str("object"))
+		if spec.Keys != nil {
+			keys := g.valueSchema(spec.Keys)
+			if spec.MoreAllowed {
+				keys = anyOfWithPlus(keys)
+			}
+			schema.Put("propertyNames", keys)
+		}
+		if spec.MoreAllowed {
+			properties := oj.NewMap()
+			properties.Put("+", oj.NewMap())
+			schema.Put("properties", properties)
+		}
+		schema.Put("additionalProperties", g.valueSchema(spec.Items))
+	case mjparse.ValueAnyOf:
+		var alternatives []oj.OJsonObject
+		for _, alternative := range spec.Alternatives {
+			alternatives = append(alternatives, g.valueSchema(alternative))
+		}
+		schema.Put("anyOf", oj.NewList(alternatives))
+	}
+	return schema
+}
+
+func (g *generator) putObjectDef(spec *mjparse.ObjectSpec) {
+	if g.generated[spec] {
+		return
+	}
+	g.generated[spec] = true
+
+	def := oj.NewMap()
+	// reserve the position, so that definitions appear in the order in which they are first referenced
+	g.defs.Put(spec.Name, def)
+
+	if len(spec.Description) > 0 {
+		def.Put("description", str(spec.Description))
+	}
+	def.Put("type", str("object"))
+	properties := oj.NewMap()
+	for _, field := range spec.Fields {
+		properties.Put(field.Name, g.fieldSchema(field))
+	}
+	def.Put("properties", properties)
+	def.Put("additionalProperties", oj.NewBool(false))
+}
+
+func (g *generator) fieldSchema(field *mjparse.FieldSpec) oj.OJsonObject {
+	valueSchema := g.valueSchema(field.Value)
+	if len(field.Description) == 0 && !field.Deprecated {
+		return valueSchema
+	}
+
+	schema := oj.NewMap()
+	if len(field.Description) > 0 {
+		schema.Put("description", str(field.Description))
+	}
+	if field.Deprecated {
+		schema.Put("deprecated", oj.NewBool(true))
+	}
+	if valueMap, isMap := valueSchema.(*oj.OJsonMap); isMap && valueMap.Size() == 1 && valueMap.OrderedKV[0].Key == "$ref" {
+		// siblings of $ref are allowed since draft 2019-09
+		schema.Put("$ref", valueMap.OrderedKV[0].Value)
+	} else {
+		schema.Put("allOf", oj.NewList([]oj.OJsonObject{valueSchema}))
+	}
+	return schema
+}
+
+func anyOfWithPlus(schema oj.OJsonObject) oj.OJsonObject {
+	plus := oj.NewMap()
+	plus.Put("const", str("+"))
+	result := oj.NewMap()
+	result.Put("anyOf", oj.NewList([]oj.OJsonObject{schema, plus}))
+	return result
+}
+
+func ref(defName string) oj.OJsonObject {
+	schema := oj.NewMap()
+	schema.Put("$ref", str("#/$defs/"+defName))
+	return schema
+}
+
+func str(value string) oj.OJsonObject {
+	return &oj.OJsonString{Value: value}
+}
+
+// SchemaJSONString formats a generated schema.
+func SchemaJSONString(schema oj.OJsonObject) string {
+	return oj.JSONString(schema) + "\n"
+}
+
+// SchemaFiles yields the contents of all schema files, by file name.
+func SchemaFiles(interpreter *ei.ExprInterpreter) map[string]string {
+	return map[string]string{
+		"scenario.schema.json": SchemaJSONString(ScenarioSchema(interpreter)),
+		"steps.schema.json":    SchemaJSONString(StepsSchema(interpreter)),
+		"test.schema.json":     SchemaJSONString(TestSchema(interpreter)),
+	}
+}
@@ -0,0 +1,87 @@
+package scenjsonschema
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFilesUpToDate(t *testing.T) {
+	for fileName, contents := range SchemaFiles(&ei.ExprInterpreter{}) {
+		committed, err := os.ReadFile(fileName)
+		require.Nil(t, err)
+		require.Equal(t, contents, string(committed), "%s is out of date, run go generate", fileName)
+	}
+}
+
+func TestExpressionPattern(t *testing.T) {
+	pattern := regexp.MustCompile(ExpressionPattern(&ei.ExprInterpreter{}))
+	for _, valid := range []string{
+		"", "0", "1,000,000", "-5", "+5", "0x1234", "0b101", "1.5", "true", "false",
+		"str:abc", "``abc", "''a b c", "address:owner", "sc:adder", "u32:5|u8:1|str:x",
+		"nested:str:abc", "biguint:0x01", "keccak256:str:a|str:b", "file:../output/adder.wasm",
+		"mxsc:adder.mxsc.json", `u8:1|str:a\|b|u8:2`, "str:abc|u32:5", "keccak256:(str:a)|u32:1", "u8:1|nested:(u8:1|u8:2)",
+		"1,000 - 5", "moax:1.5", "2 * moax:0.5 - 1", "(1+2)*3|u8:1", "u64:2**10", "min(1, 2)|max(3, 4)",
+		"${amount}", "u8:1|${owner}", "var:owner|u32:1",
+	} {
+		require.True(t, pattern.MatchString(valid), valid)
+	}
+	for _, invalid := range []string{
+		"abc", "str", ":5", "-abc:1", "0xzz", "u32:5|abc", "u8:1|str:a|b",
+		// typos of built-in prefixes
+		"adress:owner", "u46:5", "u128:5", "u8:1|nestd:u8:1",
+		// functions that are not registered
+		"dctid:TICK", "wrap:(u8:1|u8:2)",
+	} {
+		require.False(t, pattern.MatchString(invalid), invalid)
+	}
+}
+
+func TestExpressionPatternFunctions(t *testing.T) {
+	interpreter := &ei.ExprInterpreter{}
+	call := func(args [][]byte) ([]byte, error) { return nil, nil }
+	require.Nil(t, interpreter.RegisterFunction(&ei.Function{Name: "dctid", Args: []ei.FunctionArgKind{ei.StringArg}, Call: call}))
+	require.Nil(t, interpreter.RegisterFunction(&ei.Function{Name: "token.v2", Args: []ei.FunctionArgKind{ei.StringArg, ei.NumberArg}, Call: call}))
+	require.Nil(t, interpreter.RegisterFunction(&ei.Function{Name: "wrap", Args: []ei.FunctionArgKind{ei.ExpressionArg}, Call: call}))
+
+	pattern := regexp.MustCompile(ExpressionPattern(interpreter))
+	for _, valid := range []string{
+		"str:abc", "u32:5|u8:1",
+		"dctid:TICK", "u8:1|dctid:TICK|u8:2", "token.v2:TICK:5", "wrap:(u8:1|u8:2)", "u8:1|wrap:(u8:1|u8:2)",
+	} {
+		require.True(t, pattern.MatchString(valid), valid)
+	}
+	for _, invalid := range []string{
+		"adress:owner", "u46:5", "dctd:TICK", "token:TICK:5", "wrap:u8:1|u8:2|abc",
+	} {
+		require.False(t, pattern.MatchString(invalid), invalid)
+	}
+}
+
+func TestSchemaCoversRegistry(t *testing.T) {
+	parsed, err := oj.ParseOrderedJSON([]byte(SchemaJSONString(ScenarioSchema(&ei.ExprInterpreter{}))))
+	require.Nil(t, err)
+	defs := findKey(parsed.(*oj.OJsonMap), "$defs").(*oj.OJsonMap)
+
+	account := findKey(defs, "account").(*oj.OJsonMap)
+	properties := findKey(account, "properties").(*oj.OJsonMap)
+	accountSpec := mjparse.ScenarioSpec().Field("steps").Value.Items.Alternatives[1].Object.Field("accounts").Value.Items.Object
+	require.Equal(t, len(accountSpec.Fields), properties.Size())
+	for _, field := range accountSpec.Fields {
+		require.NotNil(t, findKey(properties, field.Name), field.Name)
+	}
+}
+
+func findKey(m *oj.OJsonMap, key string) oj.OJsonObject {
+	for _, kvp := range m.OrderedKV {
+		if kvp.Key == key {
+			return kvp.Value
+		}
+	}
+	return nil
+}
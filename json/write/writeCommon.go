@@ -61,7 +61,7 @@ func logsToOJ(logEntries mj.LogList) oj.OJsonObject {
 	if logEntries.MoreAllowedAtEnd {
 		logList = append(logList, stringToOJ("+"))
 	}
-	logOJList := oj.OJsonList(logList)
+	logOJList := oj.OJsonList{Items: logList}
 	return &logOJList
 }
 
@@ -73,9 +73,17 @@ func checkBigIntToOJ(i mj.JSONCheckBigInt) oj.OJsonObject {
 	return &oj.OJsonString{Value: i.Original}
 }
 
+// hexString writes values that have no original representation, e.g. in generated scenarios.
+func hexString(value []byte) string {
+	if len(value) == 0 {
+		return ""
+	}
+	return "0x" + hex.EncodeToString(value)
+}
+
 func bytesFromStringToString(bytes mj.JSONBytesFromString) string {
 	if len(bytes.Original) == 0 && len(bytes.Value) > 0 {
-		bytes.Original = hex.EncodeToString(bytes.Value)
+		bytes.Original = hexString(bytes.Value)
 	}
 	return bytes.Original
 }
@@ -85,15 +93,15 @@ func bytesFromStringToOJ(bytes mj.JSONBytesFromString) oj.OJsonObject {
 }
 
 func bytesFromTreeToOJ(bytes mj.JSONBytesFromTree) oj.OJsonObject {
-	if bytes.OriginalEmpty() {
-		bytes.Original = &oj.OJsonString{Value: hex.EncodeToString(bytes.Value)}
+	if bytes.Original == nil || bytes.OriginalEmpty() {
+		bytes.Original = &oj.OJsonString{Value: hexString(bytes.Value)}
 	}
 	return bytes.Original
 }
 
 func checkBytesToOJ(checkBytes mj.JSONCheckBytes) oj.OJsonObject {
 	if checkBytes.OriginalEmpty() && len(checkBytes.Value) > 0 {
-		checkBytes.Original = &oj.OJsonString{Value: hex.EncodeToString(checkBytes.Value)}
+		checkBytes.Original = &oj.OJsonString{Value: hexString(checkBytes.Value)}
 	}
 	return checkBytes.Original
 }
@@ -103,7 +111,7 @@ func valueListToOJ(jsonBytesList mj.JSONValueList) oj.OJsonObject {
 	for _, blh := range jsonBytesList.Values {
 		valuesList = append(valuesList, bytesFromStringToOJ(blh))
 	}
-	ojList := oj.OJsonList(valuesList)
+	ojList := oj.OJsonList{Items: valuesList}
 	return &ojList
 }
 
@@ -116,7 +124,7 @@ func checkValueListToOJ(jcbl mj.JSONCheckValueList) oj.OJsonObject {
 	for _, jcb := range jcbl.Values {
 		valuesList = append(valuesList, checkBytesToOJ(jcb))
 	}
-	ojList := oj.OJsonList(valuesList)
+	ojList := oj.OJsonList{Items: valuesList}
 	return &ojList
 }
 
@@ -133,6 +141,5 @@ func stringToOJ(str string) oj.OJsonObject {
 }
 
 func boolToOJ(val bool) oj.OJsonObject {
-	obj := oj.OJsonBool(val)
-	return &obj
+	return oj.NewBool(val)
 }
@@ -9,7 +9,7 @@ func dctTxDataToOJ(dctItems []*mj.DCTTxData) oj.OJsonObject {
 	dctItemList := oj.OJsonList{}
 	for _, dctItemRaw := range dctItems {
 		dctItemOJ := dctTxRawEntryToOJ(dctItemRaw)
-		dctItemList = append(dctItemList, dctItemOJ)
+		dctItemList.Items = append(dctItemList.Items, dctItemOJ)
 	}
 
 	return &dctItemList
@@ -55,7 +55,7 @@ func dctItemToOJ(dctItem *mj.DCTData) oj.OJsonObject {
 			appendDCTInstanceToOJ(dctInstance, dctInstanceOJ)
 			convertedList = append(convertedList, dctInstanceOJ)
 		}
-		instancesOJList := oj.OJsonList(convertedList)
+		instancesOJList := oj.OJsonList{Items: convertedList}
 		dctItemOJ.Put("instances", &instancesOJList)
 	}
 
@@ -69,7 +69,7 @@ func dctItemToOJ(dctItem *mj.DCTData) oj.OJsonObject {
 		for _, roleStr := range dctItem.Roles {
 			convertedList = append(convertedList, &oj.OJsonString{Value: roleStr})
 		}
-		rolesOJList := oj.OJsonList(convertedList)
+		rolesOJList := oj.OJsonList{Items: convertedList}
 		dctItemOJ.Put("roles", &rolesOJList)
 	}
 	if len(dctItem.Frozen.Original) > 0 {
@@ -31,7 +31,7 @@ func checkDCTItemToOJ(dctItem *mj.CheckDCTData) oj.OJsonObject {
 			appendCheckDCTInstanceToOJ(dctInstance, dctInstanceOJ)
 			convertedList = append(convertedList, dctInstanceOJ)
 		}
-		instancesOJList := oj.OJsonList(convertedList)
+		instancesOJList := oj.OJsonList{Items: convertedList}
 		dctItemOJ.Put("instances", &instancesOJList)
 	}
 
@@ -45,7 +45,7 @@ func checkDCTItemToOJ(dctItem *mj.CheckDCTData) oj.OJsonObject {
 		for _, roleStr := range dctItem.Roles {
 			convertedList = append(convertedList, &oj.OJsonString{Value: roleStr})
 		}
-		rolesOJList := oj.OJsonList(convertedList)
+		rolesOJList := oj.OJsonList{Items: convertedList}
 		dctItemOJ.Put("roles", &rolesOJList)
 	}
 	if len(dctItem.Frozen.Original) > 0 {
@@ -0,0 +1,197 @@
+package scenjsonwrite
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	er "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+)
+
+// FieldHints tells how to write the values of generated scenarios, which only have their bytes.
+// Keys are key paths, as in parse errors, e.g. `steps[2].tx.arguments[0]` or `steps[0].accounts["sc:adder"].balance`.
+// In a key path, "*" matches any map key, and "[*]" any list index or map key.
+// The hints of a field are those of the segments of a concatenation, usually only one.
+type FieldHints map[string][]er.ExprReconstructorHint
+
+// ScenarioToJSONStringWithHints is ScenarioToJSONString, but values written in hex are reconstructed
+// according to the hints of their fields. Values whose reconstruction would yield other bytes stay in hex.
+func ScenarioToJSONStringWithHints(scenario *mj.Scenario, hints FieldHints) (string, error) {
+	jobj := ScenarioToOrderedJSON(scenario)
+	err := applyFieldHints(jobj, hints)
+	if err != nil {
+		return "", err
+	}
+	return scenarioJSONString(scenario, jobj), nil
+}
+
+type keyPathSegment struct {
+	text  string
+	index bool
+}
+
+type fieldHint struct {
+	pattern []keyPathSegment
+	hints   []er.ExprReconstructorHint
+}
+
+var identifierKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func applyFieldHints(jobj oj.OJsonObject, hints FieldHints) error {
+	// the first matching key path applies, in alphabetical order
+	keyPaths := make([]string, 0, len(hints))
+	for keyPath := range hints {
+		keyPaths = append(keyPaths, keyPath)
+	}
+	sort.Strings(keyPaths)
+	var fieldHints []*fieldHint
+	for _, keyPath := range keyPaths {
+		fieldHintList := hints[keyPath]
+		pattern, err := parseKeyPathPattern(keyPath)
+		if err != nil {
+			return err
+		}
+		fieldHints = append(fieldHints, &fieldHint{pattern: pattern, hints: fieldHintList})
+	}
+	hinter := &valueHinter{fieldHints: fieldHints}
+	hinter.walk(jobj, nil)
+	return nil
+}
+
+type valueHinter struct {
+	fieldHints    []*fieldHint
+	reconstructor er.ExprReconstructor
+	interpreter   ei.ExprInterpreter
+}
+
+func (vh *valueHinter) walk(jobj oj.OJsonObject, path []keyPathSegment) {
+	switch j := jobj.(type) {
+	case *oj.OJsonMap:
+		for _, kv := range j.OrderedKV {
+			vh.walk(kv.Value, append(path, mapKeySegment(kv.Key)))
+		}
+	case *oj.OJsonList:
+		for i, item := range j.Items {
+			vh.walk(item, append(path, keyPathSegment{text: fmt.Sprintf("[%d]", i), index: true}))
+		}
+	case *oj.OJsonString:
+		for _, hint := range vh.fieldHints {
+			if matchKeyPath(hint.pattern, path) {
+				j.Value = vh.reconstruct(j.Value, hint.hints)
+				return
+			}
+		}
+	}
+}
+
+// reconstruct rewrites a hex value, if the hinted expression yields the same bytes.
+func (vh *valueHinter) reconstruct(str string, hints []er.ExprReconstructorHint) string {
+	if !strings.HasPrefix(str, "0x") || len(hints) == 0 {
+		return str
+	}
+	value, err := hex.DecodeString(str[2:])
+	if err != nil {
+		return str
+	}
+	reconstructed := vh.reconstructor.ReconstructConcat(value, hints...)
+	reencoded, err := vh.interpreter.InterpretString(reconstructed)
+	if err != nil || !bytes.Equal(reencoded, value) {
+		return str
+	}
+	return reconstructed
+}
+
+func mapKeySegment(key string) keyPathSegment {
+	if identifierKeyRegex.MatchString(key) {
+		return keyPathSegment{text: key}
+	}
+	return keyPathSegment{text: fmt.Sprintf("[%q]", key)}
+}
+
+func matchKeyPath(pattern []keyPathSegment, path []keyPathSegment) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i, segment := range pattern {
+		switch {
+		case segment.text == "[*]":
+		case segment.text == "*":
+			if path[i].index {
+				return false
+			}
+		case segment.text != path[i].text:
+			return false
+		}
+	}
+	return true
+}
+
+// parseKeyPathPattern splits a key path into segments, formatted as in the path of the written values.
+func parseKeyPathPattern(keyPath string) ([]keyPathSegment, error) {
+	var segments []keyPathSegment
+	rest := keyPath
+	for len(rest) > 0 {
+		switch {
+		case rest[0] == '.' && len(segments) > 0:
+			rest = rest[1:]
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if strings.HasPrefix(rest, `["`) {
+				quoted, err := strconv.QuotedPrefix(rest[1:])
+				if err != nil {
+					return nil, fmt.Errorf("invalid key path %s: unterminated key", keyPath)
+				}
+				end = 1 + len(quoted)
+			}
+			if end < 0 || end >= len(rest) || rest[end] != ']' {
+				return nil, fmt.Errorf("invalid key path %s: missing ]", keyPath)
+			}
+			segment, err := bracketSegment(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid key path %s: %w", keyPath, err)
+			}
+			segments = append(segments, segment)
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key != "*" && !identifierKeyRegex.MatchString(key) {
+				return nil, fmt.Errorf("invalid key path %s: invalid key %q", keyPath, key)
+			}
+			segments = append(segments, keyPathSegment{text: key})
+			rest = rest[end:]
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid key path: %q", keyPath)
+	}
+	return segments, nil
+}
+
+func bracketSegment(contents string) (keyPathSegment, error) {
+	if contents == "*" {
+		return keyPathSegment{text: "[*]"}, nil
+	}
+	if strings.HasPrefix(contents, `"`) {
+		key, err := strconv.Unquote(contents)
+		if err != nil {
+			return keyPathSegment{}, err
+		}
+		return mapKeySegment(key), nil
+	}
+	index, err := strconv.Atoi(contents)
+	if err != nil || index < 0 {
+		return keyPathSegment{}, fmt.Errorf("invalid index %s", contents)
+	}
+	return keyPathSegment{text: fmt.Sprintf("[%d]", index), index: true}, nil
+}
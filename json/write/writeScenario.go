@@ -6,8 +6,16 @@ import (
 )
 
 // ScenarioToJSONString converts a scenario object to its JSON representation.
+// Scenarios parsed in JSONC mode keep their comments and original formatting.
 func ScenarioToJSONString(scenario *mj.Scenario) string {
-	jobj := ScenarioToOrderedJSON(scenario)
+	return scenarioJSONString(scenario, ScenarioToOrderedJSON(scenario))
+}
+
+func scenarioJSONString(scenario *mj.Scenario, jobj oj.OJsonObject) string {
+	if scenario.Source != nil {
+		oj.TransferTrivia(scenario.Source, jobj)
+		return oj.LosslessJSONString(jobj)
+	}
 	return oj.JSONString(jobj) + "\n"
 }
 
@@ -23,23 +31,40 @@ func ScenarioToOrderedJSON(scenario *mj.Scenario) oj.OJsonObject {
 		scenarioOJ.Put("comment", stringToOJ(scenario.Comment))
 	}
 
+	if len(scenario.Tags) > 0 {
+		var tagList []oj.OJsonObject
+		for _, tag := range scenario.Tags {
+			tagList = append(tagList, stringToOJ(tag))
+		}
+		scenarioOJ.Put("tags", &oj.OJsonList{Items: tagList})
+	}
+
 	if !scenario.CheckGas {
-		ojFalse := oj.OJsonBool(false)
-		scenarioOJ.Put("checkGas", &ojFalse)
+		scenarioOJ.Put("checkGas", boolToOJ(false))
 	}
 
 	if scenario.TraceGas {
-		ojTrue := oj.OJsonBool(true)
-		scenarioOJ.Put("traceGas", &ojTrue)
+		scenarioOJ.Put("traceGas", boolToOJ(true))
 	}
 
 	if scenario.GasSchedule != mj.GasScheduleDefault {
 		scenarioOJ.Put("gasSchedule", gasScheduleToOJ(scenario.GasSchedule))
 	}
 
+	if len(scenario.Constants) > 0 {
+		constantsOJ := oj.NewMap()
+		for _, constant := range scenario.Constants {
+			constantsOJ.Put(constant.Name, stringToOJ(constant.Value))
+		}
+		scenarioOJ.Put("constants", constantsOJ)
+	}
+
 	var stepOJList []oj.OJsonObject
 
 	for _, generalStep := range scenario.Steps {
+		if setStateStep, isSetState := generalStep.(*mj.SetStateStep); isSetState && setStateStep.Derived {
+			continue
+		}
 		stepOJ := oj.NewMap()
 		stepOJ.Put("step", stringToOJ(generalStep.StepTypeName()))
 		switch step := generalStep.(type) {
@@ -101,7 +126,7 @@ func ScenarioToOrderedJSON(scenario *mj.Scenario) oj.OJsonObject {
 		stepOJList = append(stepOJList, stepOJ)
 	}
 
-	stepsOJ := oj.OJsonList(stepOJList)
+	stepsOJ := oj.OJsonList{Items: stepOJList}
 	scenarioOJ.Put("steps", &stepsOJ)
 
 	return scenarioOJ
@@ -125,6 +150,9 @@ func transactionToScenarioOJ(tx *mj.Transaction) oj.OJsonObject {
 	if tx.Type.HasFunction() {
 		transactionOJ.Put("function", stringToOJ(tx.Function))
 	}
+	if len(tx.ABIPath) > 0 {
+		transactionOJ.Put("abi", stringToOJ(tx.ABIPath))
+	}
 	if tx.Type == mj.ScDeploy || tx.Type == mj.ScUpgrade {
 		transactionOJ.Put("contractCode", bytesFromStringToOJ(tx.Code))
 	}
@@ -134,7 +162,7 @@ func transactionToScenarioOJ(tx *mj.Transaction) oj.OJsonObject {
 		for _, arg := range tx.Arguments {
 			argList = append(argList, bytesFromTreeToOJ(arg))
 		}
-		argOJ := oj.OJsonList(argList)
+		argOJ := oj.OJsonList{Items: argList}
 		transactionOJ.Put("arguments", &argOJ)
 	}
 
@@ -155,10 +183,12 @@ func newAddressMocksToOJ(newAddressMocks []*mj.NewAddressMock) oj.OJsonObject {
 		namOJ := oj.NewMap()
 		namOJ.Put("creatorAddress", bytesFromStringToOJ(namEntry.CreatorAddress))
 		namOJ.Put("creatorNonce", uint64ToOJ(namEntry.CreatorNonce))
-		namOJ.Put("newAddress", bytesFromStringToOJ(namEntry.NewAddress))
+		if !namEntry.Derived {
+			namOJ.Put("newAddress", bytesFromStringToOJ(namEntry.NewAddress))
+		}
 		namList = append(namList, namOJ)
 	}
-	namOJList := oj.OJsonList(namList)
+	namOJList := oj.OJsonList{Items: namList}
 	return &namOJList
 }
 
@@ -25,8 +25,7 @@ func testToOJ(test *mj.Test) oj.OJsonObject {
 	testOJ := oj.NewMap()
 
 	if !test.CheckGas {
-		ojFalse := oj.OJsonBool(false)
-		testOJ.Put("checkGas", &ojFalse)
+		testOJ.Put("checkGas", boolToOJ(false))
 	}
 
 	testOJ.Put("pre", AccountsToOJ(test.Pre))
@@ -35,7 +34,7 @@ func testToOJ(test *mj.Test) oj.OJsonObject {
 	for _, block := range test.Blocks {
 		blockList = append(blockList, blockToOJ(block))
 	}
-	blocksOJ := oj.OJsonList(blockList)
+	blocksOJ := oj.OJsonList{Items: blockList}
 	testOJ.Put("blocks", &blocksOJ)
 	testOJ.Put("network", stringToOJ(test.Network))
 	testOJ.Put("blockHashes", valueListToOJ(test.BlockHashes))
@@ -55,7 +54,7 @@ func transactionToTestOJ(tx *mj.Transaction) oj.OJsonObject {
 	for _, arg := range tx.Arguments {
 		argList = append(argList, bytesFromTreeToOJ(arg))
 	}
-	argOJ := oj.OJsonList(argList)
+	argOJ := oj.OJsonList{Items: argList}
 	transactionOJ.Put("arguments", &argOJ)
 
 	if len(tx.Code.Original) > 0 {
@@ -74,14 +73,14 @@ func blockToOJ(block *mj.Block) oj.OJsonObject {
 	for _, blr := range block.Results {
 		resultList = append(resultList, resultToOJ(blr))
 	}
-	resultsOJ := oj.OJsonList(resultList)
+	resultsOJ := oj.OJsonList{Items: resultList}
 	blockOJ.Put("results", &resultsOJ)
 
 	var txList []oj.OJsonObject
 	for _, tx := range block.Transactions {
 		txList = append(txList, transactionToTestOJ(tx))
 	}
-	txsOJ := oj.OJsonList(txList)
+	txsOJ := oj.OJsonList{Items: txList}
 	blockOJ.Put("transactions", &txsOJ)
 
 	blockHeaderOJ := oj.NewMap()
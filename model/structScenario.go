@@ -1,14 +1,28 @@
 package scenjsonmodel
 
+import oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+
 // Scenario is a json object representing a test scenario with steps.
 type Scenario struct {
 	Name        string
 	Comment     string
+	Tags        []string
 	CheckGas    bool
 	TraceGas    bool
 	IsNewTest   bool
 	GasSchedule GasSchedule
+	Constants   []*Constant
 	Steps       []Step
+
+	// Source is the parsed JSON, including comments and formatting.
+	// Only retained when parsing in JSONC mode, it allows writing the scenario back with minimal changes.
+	Source oj.OJsonObject
+}
+
+// Constant is a named value expression, referenced in the scenario as "${name}" or "var:name".
+type Constant struct {
+	Name  string
+	Value string
 }
 
 // Step is the basic block of a scenario.
@@ -21,6 +35,10 @@ type NewAddressMock struct {
 	CreatorAddress JSONBytesFromString
 	CreatorNonce   JSONUint64
 	NewAddress     JSONBytesFromString
+
+	// Derived is set when the parser derived the new address, because the entry left it out.
+	// The new address is then also left out when writing the scenario back.
+	Derived bool
 }
 
 // BlockInfo contains data for the block info hooks
@@ -47,6 +65,9 @@ type ExternalStepsStep struct {
 	Comment  string
 	TraceGas TraceGasStatus
 	Path     string
+
+	// Constants are the constants in scope at the step, by name, which the steps file inherits.
+	Constants map[string]string
 }
 
 // SetStateStep is a step where data is saved to the blockchain mock.
@@ -58,6 +79,10 @@ type SetStateStep struct {
 	CurrentBlockInfo  *BlockInfo
 	BlockHashes       JSONValueList
 	NewAddressMocks   []*NewAddressMock
+
+	// Derived is set on the steps that the parser inserts before scDeploy steps, to register the derived address
+	// of the deployed contract, see "sc-derived:". They are left out when writing the scenario back.
+	Derived bool
 }
 
 // CheckStateStep is a step where the state of the blockchain mock is verified.
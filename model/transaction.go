@@ -74,6 +74,7 @@ type Transaction struct {
 	From         JSONBytesFromString
 	To           JSONBytesFromString
 	Function     string
+	ABIPath      string
 	Code         JSONBytesFromString
 	CodeMetadata JSONBytesFromString
 	Arguments    []JSONBytesFromTree
@@ -0,0 +1,280 @@
+package orderedjson
+
+import (
+	"fmt"
+	"io"
+)
+
+// Decoder parses ordered JSON from a stream.
+//
+// Besides decoding entire values, it can iterate over maps and lists entry by entry,
+// so that large inputs can be processed without building the whole tree in memory.
+type Decoder struct {
+	tokenizer *tokenizer
+	peeked    token
+	hasPeeked bool
+}
+
+// NewDecoder creates a Decoder that reads from the given reader.
+func NewDecoder(reader io.Reader) *Decoder {
+	return &Decoder{
+		tokenizer: newTokenizer(reader),
+	}
+}
+
+// newBytesDecoder creates a Decoder that reads directly from a byte slice, without copying.
+func newBytesDecoder(input []byte) *Decoder {
+	return &Decoder{
+		tokenizer: newBytesTokenizer(input),
+	}
+}
+
+func (d *Decoder) peekToken() (token, error) {
+	if !d.hasPeeked {
+		tok, err := d.tokenizer.next()
+		if err != nil {
+			return token{}, err
+		}
+		d.peeked = tok
+		d.hasPeeked = true
+	}
+	return d.peeked, nil
+}
+
+func (d *Decoder) nextToken() (token, error) {
+	tok, err := d.peekToken()
+	d.hasPeeked = false
+	return tok, err
+}
+
+func (d *Decoder) expectToken(kind tokenKind, context string) (token, error) {
+	tok, err := d.nextToken()
+	if err != nil {
+		return token{}, err
+	}
+	if tok.kind != kind {
+		return token{}, newSyntaxError(tok.span.Start, fmt.Sprintf("%s expected %s, got %s", context, kind, tok.kind))
+	}
+	return tok, nil
+}
+
+// Decode reads the next complete JSON value from the input.
+func (d *Decoder) Decode() (OJsonObject, error) {
+	if d.isJSONC() {
+		return d.decodeValueJSONC()
+	}
+
+	tok, err := d.peekToken()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok.kind {
+	case tokenBeginMap:
+		result := NewMap()
+		result.Span, err = d.DecodeMapStream(func(key string, keySpan Span) error {
+			value, err := d.Decode()
+			if err != nil {
+				return err
+			}
+			result.putWithKeySpan(key, keySpan, value)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	case tokenBeginList:
+		result := &OJsonList{}
+		result.Span, err = d.DecodeListStream(func(_ int) error {
+			item, err := d.Decode()
+			if err != nil {
+				return err
+			}
+			result.Items = append(result.Items, item)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	return d.decodeScalar()
+}
+
+func (d *Decoder) decodeScalar() (OJsonObject, error) {
+	tok, err := d.nextToken()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.kind {
+	case tokenString:
+		result := &OJsonString{Value: tok.text, Span: tok.span}
+		if len(tok.raw) > 0 {
+			result.raw = rawLiteral{text: tok.raw, value: tok.text}
+		}
+		return result, nil
+	case tokenNumber:
+		return &OJsonNumber{Value: tok.text, Span: tok.span}, nil
+	case tokenTrue:
+		return &OJsonBool{Value: true, Span: tok.span}, nil
+	case tokenFalse:
+		return &OJsonBool{Value: false, Span: tok.span}, nil
+	case tokenNull:
+		return &OJsonNull{Span: tok.span}, nil
+	case tokenEOF:
+		return nil, newSyntaxError(tok.span.Start, "unexpected end of input")
+	default:
+		return nil, newSyntaxError(tok.span.Start, fmt.Sprintf("misplaced character %s", tok.kind))
+	}
+}
+
+// DecodeMapStream reads a map entry by entry.
+// For each entry, handleEntry is called after the key was read.
+// The handler must consume the value, using Decode or one of the stream methods.
+// Yields the span of the entire map.
+func (d *Decoder) DecodeMapStream(handleEntry func(key string, keySpan Span) error) (Span, error) {
+	begin, err := d.expectToken(tokenBeginMap, "map")
+	if err != nil {
+		return Span{}, err
+	}
+
+	for first := true; ; first = false {
+		tok, err := d.nextToken()
+		if err != nil {
+			return Span{}, err
+		}
+		if tok.kind == tokenEndMap && first {
+			return Span{Start: begin.span.Start, End: tok.span.End}, nil
+		}
+		if !first {
+			if tok.kind == tokenEndMap {
+				return Span{Start: begin.span.Start, End: tok.span.End}, nil
+			}
+			if tok.kind != tokenComma {
+				return Span{}, newSyntaxError(tok.span.Start, fmt.Sprintf("invalid map state, expected ',' or '}', got %s", tok.kind))
+			}
+			if end, closed, err := d.skipTrailingComma(tokenEndMap); err != nil || closed {
+				return Span{Start: begin.span.Start, End: end.span.End}, err
+			}
+			tok, err = d.nextToken()
+			if err != nil {
+				return Span{}, err
+			}
+		}
+		if tok.kind != tokenString {
+			return Span{}, newSyntaxError(tok.span.Start, "map key must start with a quote")
+		}
+		_, err = d.expectToken(tokenColon, "invalid character in map definition, colon")
+		if err != nil {
+			return Span{}, err
+		}
+		err = handleEntry(tok.text, tok.span)
+		if err != nil {
+			return Span{}, err
+		}
+	}
+}
+
+// DecodeListStream reads a list item by item.
+// For each item, handleItem is called with the item index.
+// The handler must consume the item, using Decode or one of the stream methods.
+// Yields the span of the entire list.
+func (d *Decoder) DecodeListStream(handleItem func(index int) error) (Span, error) {
+	begin, err := d.expectToken(tokenBeginList, "list")
+	if err != nil {
+		return Span{}, err
+	}
+
+	for index := 0; ; index++ {
+		tok, err := d.peekToken()
+		if err != nil {
+			return Span{}, err
+		}
+		if index == 0 && tok.kind == tokenEndList {
+			d.hasPeeked = false
+			return Span{Start: begin.span.Start, End: tok.span.End}, nil
+		}
+		if index > 0 {
+			d.hasPeeked = false
+			if tok.kind == tokenEndList {
+				return Span{Start: begin.span.Start, End: tok.span.End}, nil
+			}
+			if tok.kind != tokenComma {
+				return Span{}, newSyntaxError(tok.span.Start, fmt.Sprintf("invalid character in list, expected ',' or ']', got %s", tok.kind))
+			}
+			if end, closed, err := d.skipTrailingComma(tokenEndList); err != nil || closed {
+				return Span{Start: begin.span.Start, End: end.span.End}, err
+			}
+		}
+		err = handleItem(index)
+		if err != nil {
+			return Span{}, err
+		}
+	}
+}
+
+// ExpectEnd checks that nothing but whitespace is left in the input.
+func (d *Decoder) ExpectEnd() error {
+	tok, err := d.peekToken()
+	if err != nil {
+		return err
+	}
+	if tok.kind != tokenEOF {
+		return newSyntaxError(tok.span.Start, "unexpected characters at the end")
+	}
+	return nil
+}
+
+// IsNextMap returns true if the next value in the input is a map.
+func (d *Decoder) IsNextMap() (bool, error) {
+	tok, err := d.peekToken()
+	if err != nil {
+		return false, err
+	}
+	return tok.kind == tokenBeginMap, nil
+}
+
+// IsNextList returns true if the next value in the input is a list.
+func (d *Decoder) IsNextList() (bool, error) {
+	tok, err := d.peekToken()
+	if err != nil {
+		return false, err
+	}
+	return tok.kind == tokenBeginList, nil
+}
+
+// DecodeAll parses a complete JSON document from a reader.
+func DecodeAll(reader io.Reader) (OJsonObject, error) {
+	return decodeDocument(NewDecoder(reader))
+}
+
+func decodeDocument(decoder *Decoder) (OJsonObject, error) {
+	var leading string
+	if decoder.isJSONC() {
+		tok, err := decoder.peekToken()
+		if err != nil {
+			return nil, err
+		}
+		leading = tok.leading
+	}
+	result, err := decoder.Decode()
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.ExpectEnd()
+	if err != nil {
+		return nil, err
+	}
+	if decoder.isJSONC() {
+		trivia := result.getTrivia()
+		if trivia == nil {
+			trivia = &Trivia{}
+			result.setTrivia(trivia)
+		}
+		trivia.Leading = leading
+		trivia.Trailing = decoder.peeked.leading
+	}
+	return result, nil
+}
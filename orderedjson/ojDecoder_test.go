@@ -0,0 +1,168 @@
+package orderedjson
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoderMatchesByteByByteParser(t *testing.T) {
+	gen := &randomJSONGenerator{rnd: rand.New(rand.NewSource(7))}
+	for i := 0; i < 1000; i++ {
+		input := gen.document()
+
+		expected, err := parseOrderedJSONByteByByte(input)
+		require.Nil(t, err, "input: %s", input)
+
+		fromBytes, err := ParseOrderedJSON(input)
+		require.Nil(t, err, "input: %s", input)
+		require.Equal(t, expected, fromBytes, "input: %s", input)
+
+		// one byte at a time, to exercise buffer refills mid-token
+		fromReader, err := DecodeAll(iotest.OneByteReader(bytes.NewReader(input)))
+		require.Nil(t, err, "input: %s", input)
+		require.Equal(t, expected, fromReader, "input: %s", input)
+	}
+}
+
+func TestDecoderStream(t *testing.T) {
+	input := `{
+    "name": "stream",
+    "steps": [
+        { "step": "a" },
+        { "step": "b" }
+    ]
+}`
+	decoder := NewDecoder(strings.NewReader(input))
+	var visited []string
+	_, err := decoder.DecodeMapStream(func(key string, _ Span) error {
+		if key != "steps" {
+			_, err := decoder.Decode()
+			return err
+		}
+		_, err := decoder.DecodeListStream(func(index int) error {
+			step, err := decoder.Decode()
+			if err != nil {
+				return err
+			}
+			visited = append(visited, fmt.Sprintf("%d:%s", index, JSONString(step)))
+			return nil
+		})
+		return err
+	})
+	require.Nil(t, err)
+	require.Nil(t, decoder.ExpectEnd())
+	require.Equal(t, []string{
+		`0:{
+    "step": "a"
+}`,
+		`1:{
+    "step": "b"
+}`,
+	}, visited)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`{`,
+		`{"a" 1}`,
+		`{"a": 1,}`,
+		`[1 2]`,
+		`[1,]`,
+		`{"a": tru}`,
+		`"abc`,
+		`{} {}`,
+		`{"a": 01}`,
+	} {
+		_, err := DecodeAll(strings.NewReader(input))
+		require.NotNil(t, err, "input: %s", input)
+		_, isSyntaxError := err.(*SyntaxError)
+		require.True(t, isSyntaxError, "input: %s", input)
+	}
+}
+
+// largeScenarioLikeJSON generates a document resembling a big state dump.
+func largeScenarioLikeJSON(numSteps int) []byte {
+	var sb strings.Builder
+	sb.WriteString("{\n    \"name\": \"benchmark\",\n    \"steps\": [\n")
+	for i := 0; i < numSteps; i++ {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		fmt.Fprintf(&sb, `        {
+            "step": "setState",
+            "accounts": {
+                "address:account%d": {
+                    "nonce": "%d",
+                    "balance": "1,000,000,000",
+                    "storage": {
+                        "str:key\t%d": "0x%064x",
+                        "str:other": ["u32:1", "u64:2", "nested:str:abc"]
+                    },
+                    "code": "file:contract.wasm",
+                    "active": true,
+                    "limit": null,
+                    "ratio": 1.5e-3
+                }
+            }
+        }`, i, i, i, i)
+	}
+	sb.WriteString("\n    ]\n}\n")
+	return []byte(sb.String())
+}
+
+func BenchmarkParseByteByByte(b *testing.B) {
+	input := largeScenarioLikeJSON(1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := parseOrderedJSONByteByByte(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseDecoder(b *testing.B) {
+	input := largeScenarioLikeJSON(1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ParseOrderedJSON(input)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeStream(b *testing.B) {
+	input := largeScenarioLikeJSON(1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		decoder := NewDecoder(bytes.NewReader(input))
+		_, err := decoder.DecodeMapStream(func(key string, _ Span) error {
+			if key != "steps" {
+				_, err := decoder.Decode()
+				return err
+			}
+			_, err := decoder.DecodeListStream(func(_ int) error {
+				_, err := decoder.Decode()
+				return err
+			})
+			return err
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package orderedjson
+
+import (
+	"fmt"
+	"io"
+)
+
+// ParseOrderedJSONC parses JSON with comments (JSONC), preserving order in maps.
+//
+// Besides standard JSON, it accepts // and /* */ comments, as well as trailing commas in maps and lists.
+// All whitespace and comments are kept as trivia attached to the nodes,
+// so that LosslessJSONString can reproduce the input exactly.
+func ParseOrderedJSONC(input []byte) (OJsonObject, error) {
+	decoder := newBytesDecoder(input)
+	decoder.tokenizer.jsonc = true
+	return decodeDocument(decoder)
+}
+
+// NewJSONCDecoder creates a Decoder that accepts JSON with comments and trailing commas.
+// Values returned by Decode carry trivia, the stream methods discard it.
+func NewJSONCDecoder(reader io.Reader) *Decoder {
+	decoder := NewDecoder(reader)
+	decoder.tokenizer.jsonc = true
+	return decoder
+}
+
+func (d *Decoder) isJSONC() bool {
+	return d.tokenizer.jsonc
+}
+
+// skipTrailingComma consumes the closing bracket following a trailing comma, if there is one.
+func (d *Decoder) skipTrailingComma(closeKind tokenKind) (token, bool, error) {
+	if !d.isJSONC() {
+		return token{}, false, nil
+	}
+	tok, err := d.peekToken()
+	if err != nil {
+		return token{}, false, err
+	}
+	if tok.kind != closeKind {
+		return token{}, false, nil
+	}
+	d.hasPeeked = false
+	return tok, true, nil
+}
+
+// decodeValueJSONC decodes a value, attaching trivia to it and to its contents.
+// The leading trivia of the value itself is left to the caller.
+func (d *Decoder) decodeValueJSONC() (OJsonObject, error) {
+	tok, err := d.peekToken()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.kind {
+	case tokenBeginMap:
+		return d.decodeMapJSONC()
+	case tokenBeginList:
+		return d.decodeListJSONC()
+	default:
+		return d.decodeScalar()
+	}
+}
+
+func (d *Decoder) decodeMapJSONC() (OJsonObject, error) {
+	begin, err := d.nextToken()
+	if err != nil {
+		return nil, err
+	}
+	result := NewMap()
+	result.Trivia = &Trivia{}
+
+	for {
+		tok, err := d.nextToken()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokenEndMap {
+			result.Trivia.BeforeClose = tok.leading
+			result.Span = Span{Start: begin.span.Start, End: tok.span.End}
+			return result, nil
+		}
+		if tok.kind != tokenString {
+			return nil, newSyntaxError(tok.span.Start, "map key must start with a quote")
+		}
+
+		kvp := &OJsonKeyValuePair{
+			Key:     tok.text,
+			KeySpan: tok.span,
+			Trivia:  &Trivia{Leading: tok.leading},
+			rawKey:  rawLiteral{text: tok.raw, value: tok.text},
+		}
+		colon, err := d.expectToken(tokenColon, "invalid character in map definition, colon")
+		if err != nil {
+			return nil, err
+		}
+		kvp.Trivia.AfterKey = colon.leading
+		valueTok, err := d.peekToken()
+		if err != nil {
+			return nil, err
+		}
+		kvp.Trivia.AfterColon = valueTok.leading
+		kvp.Value, err = d.decodeValueJSONC()
+		if err != nil {
+			return nil, err
+		}
+		if !result.KeySet[kvp.Key] {
+			result.KeySet[kvp.Key] = true
+			result.OrderedKV = append(result.OrderedKV, kvp)
+		}
+
+		end, closed, err := d.decodeSeparatorJSONC(kvp.Trivia, result.Trivia, tokenEndMap)
+		if err != nil {
+			return nil, err
+		}
+		if closed {
+			result.Span = Span{Start: begin.span.Start, End: end}
+			return result, nil
+		}
+	}
+}
+
+func (d *Decoder) decodeListJSONC() (OJsonObject, error) {
+	begin, err := d.nextToken()
+	if err != nil {
+		return nil, err
+	}
+	result := &OJsonList{Trivia: &Trivia{}}
+
+	for {
+		tok, err := d.peekToken()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokenEndList {
+			d.hasPeeked = false
+			result.Trivia.BeforeClose = tok.leading
+			result.Span = Span{Start: begin.span.Start, End: tok.span.End}
+			return result, nil
+		}
+
+		item, err := d.decodeValueJSONC()
+		if err != nil {
+			return nil, err
+		}
+		itemTrivia := &Trivia{Leading: tok.leading}
+		if itemContainerTrivia := item.getTrivia(); itemContainerTrivia != nil {
+			itemTrivia.BeforeClose = itemContainerTrivia.BeforeClose
+			itemTrivia.TrailingComma = itemContainerTrivia.TrailingComma
+		}
+		item.setTrivia(itemTrivia)
+		result.Items = append(result.Items, item)
+
+		end, closed, err := d.decodeSeparatorJSONC(itemTrivia, result.Trivia, tokenEndList)
+		if err != nil {
+			return nil, err
+		}
+		if closed {
+			result.Span = Span{Start: begin.span.Start, End: end}
+			return result, nil
+		}
+	}
+}
+
+// decodeSeparatorJSONC reads what follows an element: either a comma or the closing bracket.
+// Trivia on the same line as the element goes to the element, the rest to whatever follows.
+func (d *Decoder) decodeSeparatorJSONC(elemTrivia *Trivia, containerTrivia *Trivia, closeKind tokenKind) (Position, bool, error) {
+	sep, err := d.nextToken()
+	if err != nil {
+		return Position{}, false, err
+	}
+	switch sep.kind {
+	case closeKind:
+		elemTrivia.Trailing, containerTrivia.BeforeClose = splitTrivia(sep.leading)
+		return sep.span.End, true, nil
+	case tokenComma:
+		elemTrivia.Trailing = sep.leading
+		next, err := d.peekToken()
+		if err != nil {
+			return Position{}, false, err
+		}
+		elemTrivia.AfterComma, d.peeked.leading = splitTrivia(next.leading)
+		if next.kind == closeKind {
+			d.hasPeeked = false
+			containerTrivia.TrailingComma = true
+			containerTrivia.BeforeClose = d.peeked.leading
+			return next.span.End, true, nil
+		}
+		return Position{}, false, nil
+	default:
+		return Position{}, false, newSyntaxError(sep.span.Start,
+			fmt.Sprintf("expected ',' or %s, got %s", closeKind, sep.kind))
+	}
+}
@@ -0,0 +1,118 @@
+package orderedjson
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const exampleJSONC = `// scenario with comments
+{
+    "name": "jsonc", // trailing comment
+    "steps": [
+        /* block comment */
+        {
+            "step": "setState",
+
+            "accounts": {
+                "address:a": { "nonce": "0", "balance": "1,000" }
+            }
+        },
+        {
+            "step"  :"scCall", "txId": "1", // two on a line
+        },
+    ],
+    "extra": []
+}
+`
+
+func TestJSONCRoundTrip(t *testing.T) {
+	parsed, err := ParseOrderedJSONC([]byte(exampleJSONC))
+	require.Nil(t, err)
+	require.Equal(t, exampleJSONC, LosslessJSONString(parsed))
+
+	// the regular writer ignores trivia
+	standard, err := ParseOrderedJSON([]byte(JSONString(parsed)))
+	require.Nil(t, err)
+	require.Equal(t, JSONString(parsed), JSONString(standard))
+}
+
+func TestJSONCRoundTripProperty(t *testing.T) {
+	gen := &randomJSONGenerator{rnd: rand.New(rand.NewSource(11)), jsonc: true}
+	for i := 0; i < 2000; i++ {
+		input := gen.document()
+		parsed, err := ParseOrderedJSONC(input)
+		require.Nil(t, err, string(input))
+		require.Equal(t, string(input), LosslessJSONString(parsed))
+	}
+}
+
+func TestJSONCNotAcceptedByDefault(t *testing.T) {
+	_, err := ParseOrderedJSON([]byte(exampleJSONC))
+	require.NotNil(t, err)
+	_, err = ParseOrderedJSON([]byte(`[1, 2,]`))
+	require.NotNil(t, err)
+	_, err = ParseOrderedJSONC([]byte(`[1 /* unterminated ]`))
+	require.NotNil(t, err)
+}
+
+func TestJSONCEdits(t *testing.T) {
+	parsed, err := ParseOrderedJSONC([]byte(exampleJSONC))
+	require.Nil(t, err)
+	topMap := parsed.(*OJsonMap)
+
+	// change a value in place
+	topMap.OrderedKV[0].Value.(*OJsonString).Value = "edited"
+	// add entries, with no trivia
+	topMap.Put("added", &OJsonString{Value: "new"})
+	extra := topMap.OrderedKV[2].Value.(*OJsonList)
+	extra.Items = append(extra.Items, NewBool(true))
+	// remove a step
+	steps := topMap.OrderedKV[1].Value.(*OJsonList)
+	steps.Items = steps.Items[:1]
+
+	require.Equal(t, `// scenario with comments
+{
+    "name": "edited", // trailing comment
+    "steps": [
+        /* block comment */
+        {
+            "step": "setState",
+
+            "accounts": {
+                "address:a": { "nonce": "0", "balance": "1,000" }
+            }
+        },
+    ],
+    "extra": [
+        true
+    ],
+    "added": "new"
+}
+`, LosslessJSONString(parsed))
+}
+
+func TestJSONCTerminatesLineComments(t *testing.T) {
+	parsed, err := ParseOrderedJSONC([]byte(`[1, // one
+2]`))
+	require.Nil(t, err)
+	list := parsed.(*OJsonList)
+	list.Items = list.Items[:1]
+	written := LosslessJSONString(parsed)
+	require.Equal(t, "[1 // one\n]", written)
+
+	_, err = ParseOrderedJSONC([]byte(written))
+	require.Nil(t, err)
+}
+
+func TestTransferTrivia(t *testing.T) {
+	parsed, err := ParseOrderedJSONC([]byte(exampleJSONC))
+	require.Nil(t, err)
+
+	// rebuild the same structure from scratch, as a writer would do from a model
+	regenerated, err := ParseOrderedJSON([]byte(JSONString(parsed)))
+	require.Nil(t, err)
+	TransferTrivia(parsed, regenerated)
+	require.Equal(t, exampleJSONC, LosslessJSONString(regenerated))
+}
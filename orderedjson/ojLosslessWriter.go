@@ -0,0 +1,188 @@
+package orderedjson
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LosslessJSONString returns the JSON representation of an ordered JSON tree,
+// putting back the comments and formatting recorded by ParseOrderedJSONC.
+// Unchanged input is reproduced exactly.
+// Parts of the tree without trivia, e.g. added after parsing, are formatted the same way as in JSONString,
+// but following the layout of the elements around them.
+func LosslessJSONString(j OJsonObject) string {
+	w := &losslessWriter{}
+	trivia := j.getTrivia()
+	if trivia != nil {
+		w.writeTrivia(trivia.Leading)
+	}
+	w.writeValue(j, "")
+	if trivia != nil {
+		w.writeTrivia(trivia.Trailing)
+	}
+	return w.sb.String()
+}
+
+type losslessWriter struct {
+	sb strings.Builder
+
+	// inLineComment is set while the output ends in a line comment,
+	// which needs to be terminated before writing anything else
+	inLineComment bool
+}
+
+func (w *losslessWriter) writeTrivia(trivia string) {
+	if len(trivia) == 0 {
+		return
+	}
+	if w.inLineComment && trivia[0] != '\n' && !strings.HasPrefix(trivia, "\r\n") {
+		w.sb.WriteString("\n")
+	}
+	w.sb.WriteString(trivia)
+	w.inLineComment = endsInLineComment(trivia)
+}
+
+func (w *losslessWriter) writeToken(str string) {
+	if w.inLineComment {
+		w.sb.WriteString("\n")
+		w.inLineComment = false
+	}
+	w.sb.WriteString(str)
+}
+
+// elementLayout describes how to lay out new elements, by imitating their siblings.
+type elementLayout struct {
+	leading   string
+	indent    string
+	multiline bool
+}
+
+func newElementLayout(siblings []*Trivia, indent string) elementLayout {
+	for i := len(siblings) - 1; i >= 0; i-- {
+		if siblings[i] == nil {
+			continue
+		}
+		leading := siblings[i].Leading
+		if strings.IndexByte(leading, '\n') < 0 {
+			return elementLayout{leading: " ", indent: indent}
+		}
+		childIndent := lineIndent(leading, indent+"    ")
+		return elementLayout{leading: "\n" + childIndent, indent: childIndent, multiline: true}
+	}
+	return elementLayout{leading: "\n" + indent + "    ", indent: indent + "    ", multiline: true}
+}
+
+// lineIndent yields the indentation of the last line of some trivia.
+func lineIndent(trivia string, fallback string) string {
+	idx := strings.LastIndexByte(trivia, '\n')
+	if idx < 0 {
+		return fallback
+	}
+	lastLine := trivia[idx+1:]
+	return lastLine[:len(lastLine)-len(strings.TrimLeft(lastLine, " \t"))]
+}
+
+func (w *losslessWriter) writeValue(j OJsonObject, indent string) {
+	switch value := j.(type) {
+	case *OJsonMap:
+		w.writeMap(value, indent)
+	case *OJsonList:
+		w.writeList(value, indent)
+	case *OJsonString:
+		if value.raw.validFor(value.Value) {
+			w.writeToken(value.raw.text)
+		} else {
+			var sb strings.Builder
+			writeEscapedJSONString(&sb, value.Value)
+			w.writeToken(sb.String())
+		}
+	case *OJsonBool:
+		w.writeToken(fmt.Sprintf("%v", value.Value))
+	case *OJsonNumber:
+		w.writeToken(value.Value)
+	case *OJsonNull:
+		w.writeToken("null")
+	}
+}
+
+func (w *losslessWriter) writeMap(j *OJsonMap, indent string) {
+	if len(j.OrderedKV) == 0 && j.Trivia == nil {
+		w.writeToken("{}")
+		return
+	}
+
+	siblings := make([]*Trivia, len(j.OrderedKV))
+	for i, kvp := range j.OrderedKV {
+		siblings[i] = kvp.Trivia
+	}
+	layout := newElementLayout(siblings, indent)
+
+	w.writeToken("{")
+	for i, kvp := range j.OrderedKV {
+		trivia := kvp.Trivia
+		if trivia == nil {
+			trivia = &Trivia{Leading: layout.leading, AfterColon: " "}
+		}
+		w.writeTrivia(trivia.Leading)
+		if kvp.rawKey.validFor(kvp.Key) {
+			w.writeToken(kvp.rawKey.text)
+		} else {
+			var sb strings.Builder
+			writeEscapedJSONString(&sb, kvp.Key)
+			w.writeToken(sb.String())
+		}
+		w.writeTrivia(trivia.AfterKey)
+		w.writeToken(":")
+		w.writeTrivia(trivia.AfterColon)
+		if kvp.Value != nil {
+			w.writeValue(kvp.Value, lineIndent(trivia.Leading, indent))
+		}
+		w.writeSeparator(trivia, j.Trivia, i == len(j.OrderedKV)-1)
+	}
+	w.writeClose(j.Trivia, len(siblings) > 0 && siblings[len(siblings)-1] == nil, layout, indent, "}")
+}
+
+func (w *losslessWriter) writeList(j *OJsonList, indent string) {
+	if len(j.Items) == 0 && j.Trivia == nil {
+		w.writeToken("[]")
+		return
+	}
+
+	siblings := make([]*Trivia, len(j.Items))
+	for i, item := range j.Items {
+		siblings[i] = item.getTrivia()
+	}
+	layout := newElementLayout(siblings, indent)
+
+	w.writeToken("[")
+	for i, item := range j.Items {
+		trivia := item.getTrivia()
+		if trivia == nil {
+			trivia = &Trivia{Leading: layout.leading}
+		}
+		w.writeTrivia(trivia.Leading)
+		w.writeValue(item, lineIndent(trivia.Leading, indent))
+		w.writeSeparator(trivia, j.Trivia, i == len(j.Items)-1)
+	}
+	w.writeClose(j.Trivia, len(siblings) > 0 && siblings[len(siblings)-1] == nil, layout, indent, "]")
+}
+
+func (w *losslessWriter) writeSeparator(elemTrivia *Trivia, containerTrivia *Trivia, isLast bool) {
+	w.writeTrivia(elemTrivia.Trailing)
+	if !isLast || (containerTrivia != nil && containerTrivia.TrailingComma) {
+		w.writeToken(",")
+	}
+	w.writeTrivia(elemTrivia.AfterComma)
+}
+
+func (w *losslessWriter) writeClose(trivia *Trivia, lastIsNew bool, layout elementLayout, indent string, closeBracket string) {
+	switch {
+	case trivia == nil:
+		w.writeTrivia("\n" + indent)
+	case lastIsNew && layout.multiline && strings.IndexByte(trivia.BeforeClose, '\n') < 0:
+		w.writeTrivia("\n" + indent)
+	default:
+		w.writeTrivia(trivia.BeforeClose)
+	}
+	w.writeToken(closeBracket)
+}
@@ -8,31 +8,76 @@ import (
 // OJsonObject is an ordered JSON tree object interface.
 type OJsonObject interface {
 	writeJSON(sb *strings.Builder, indent int)
+
+	// SourceSpan yields the location of the object in the parsed source.
+	// Objects created in code have a zero span.
+	SourceSpan() Span
+
+	getTrivia() *Trivia
+	setTrivia(trivia *Trivia)
 }
 
 // OJsonKeyValuePair is a key-value pair in a JSON map.
 // Since this is ordered JSON, maps are really ordered lists of key value pairs.
 type OJsonKeyValuePair struct {
-	Key   string
-	Value OJsonObject
+	Key     string
+	KeySpan Span
+	Value   OJsonObject
+	Trivia  *Trivia
+
+	// rawKey is the key literal from the source, in JSONC mode
+	rawKey rawLiteral
 }
 
 // OJsonMap is an ordered map, actually a list of key value pairs.
 type OJsonMap struct {
 	KeySet    map[string]bool
 	OrderedKV []*OJsonKeyValuePair
+	Span      Span
+	Trivia    *Trivia
 }
 
 // OJsonList is a JSON list.
-type OJsonList []OJsonObject
+// It is a struct, not a slice, so that it can hold its span and trivia: this is a breaking change, see the README.
+// NewList and AsList convert from and to slices.
+type OJsonList struct {
+	Items  []OJsonObject
+	Span   Span
+	Trivia *Trivia
+}
 
 // OJsonString is a JSON string value.
 type OJsonString struct {
-	Value string
+	Value  string
+	Span   Span
+	Trivia *Trivia
+
+	// raw is the literal from the source, in JSONC mode
+	raw rawLiteral
 }
 
 // OJsonBool is a JSON bool value.
-type OJsonBool bool
+// It is a struct, not a bool, so that it can hold its span and trivia: this is a breaking change, see the README.
+// NewBool and Value convert from and to bools.
+type OJsonBool struct {
+	Value  bool
+	Span   Span
+	Trivia *Trivia
+}
+
+// OJsonNumber is a JSON number value.
+// The number is kept in its original textual form, to avoid any loss of precision.
+type OJsonNumber struct {
+	Value  string
+	Span   Span
+	Trivia *Trivia
+}
+
+// OJsonNull is the JSON null value.
+type OJsonNull struct {
+	Span   Span
+	Trivia *Trivia
+}
 
 // NewMap is a create new ordered "map" instance.
 func NewMap() *OJsonMap {
@@ -42,10 +87,14 @@ func NewMap() *OJsonMap {
 
 // Put puts into map. Does nothing if key exists in map.
 func (j *OJsonMap) Put(key string, value OJsonObject) {
+	j.putWithKeySpan(key, Span{}, value)
+}
+
+func (j *OJsonMap) putWithKeySpan(key string, keySpan Span, value OJsonObject) {
 	_, alreadyInserted := j.KeySet[key]
 	if !alreadyInserted {
 		j.KeySet[key] = true
-		keyValuePair := &OJsonKeyValuePair{Key: key, Value: value}
+		keyValuePair := &OJsonKeyValuePair{Key: key, KeySpan: keySpan, Value: value}
 		j.OrderedKV = append(j.OrderedKV, keyValuePair)
 	}
 }
@@ -71,7 +120,47 @@ func (j *OJsonMap) KeyValuePairsSortedByKey() []*OJsonKeyValuePair {
 	return sortedKVP
 }
 
+// NewList creates a new list instance, containing the given items.
+func NewList(items []OJsonObject) *OJsonList {
+	return &OJsonList{Items: items}
+}
+
 // AsList converts a JSON list to a slice of objects.
 func (j *OJsonList) AsList() []OJsonObject {
-	return *j
+	return j.Items
+}
+
+// NewBool creates a new JSON bool value.
+func NewBool(value bool) *OJsonBool {
+	return &OJsonBool{Value: value}
+}
+
+// SourceSpan yields the location of the map in the parsed source.
+func (j *OJsonMap) SourceSpan() Span {
+	return j.Span
+}
+
+// SourceSpan yields the location of the list in the parsed source.
+func (j *OJsonList) SourceSpan() Span {
+	return j.Span
+}
+
+// SourceSpan yields the location of the string in the parsed source, quotes included.
+func (j *OJsonString) SourceSpan() Span {
+	return j.Span
+}
+
+// SourceSpan yields the location of the bool in the parsed source.
+func (j *OJsonBool) SourceSpan() Span {
+	return j.Span
+}
+
+// SourceSpan yields the location of the number in the parsed source.
+func (j *OJsonNumber) SourceSpan() Span {
+	return j.Span
+}
+
+// SourceSpan yields the location of the null in the parsed source.
+func (j *OJsonNull) SourceSpan() Span {
+	return j.Span
 }
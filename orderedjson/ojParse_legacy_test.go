@@ -0,0 +1,287 @@
+package orderedjson
+
+import (
+	"bytes"
+	"strings"
+)
+
+type jsonParserState interface {
+}
+
+type jsonParserStateAnyObjPlaceholder struct {
+}
+
+type jsonParserStateSingleValue struct {
+	buffer       bytes.Buffer
+	stringEscape bool
+	escapeNext   bool
+	start        Position
+}
+
+type jsonParserStateMap struct {
+	currentMap *OJsonMap
+}
+
+type jsonStateMapKeyValue struct {
+	keyBuffer  bytes.Buffer
+	keySpan    Span
+	escapeNext bool
+	state      int // 0=key, 1=':', 2=value
+}
+
+type jsonParserStateList struct {
+	list OJsonList
+}
+
+// positionAfter yields the position following a character that is not a newline.
+func positionAfter(pos Position) Position {
+	return Position{Offset: pos.Offset + 1, Line: pos.Line, Column: pos.Column + 1}
+}
+
+// parseOrderedJSONByteByByte is the original state machine parser.
+// It is only kept as a reference implementation, for differential tests and benchmarks.
+func parseOrderedJSONByteByByte(input []byte) (OJsonObject, error) {
+	stateStack := &jsonParserStateStack{}
+	stateStack.push(&jsonParserStateAnyObjPlaceholder{})
+	var pendingResult OJsonObject
+	tracker := newPositionTracker()
+
+	for _, c := range input {
+		pos := tracker.current()
+		done := false
+		for !done {
+			done = true
+
+			if stateStack.size() == 0 {
+				if isWhitespace(c) {
+					continue
+				} else {
+					return nil, newSyntaxError(pos, "unexpected characters at the end")
+				}
+			}
+
+			state := stateStack.peek()
+			switch specificState := state.(type) {
+			case *jsonParserStateAnyObjPlaceholder:
+				if pendingResult != nil {
+					return nil, newSyntaxError(pos, "invalid state")
+				}
+				if isWhitespace(c) {
+					// leading whitespace, ignore
+				} else if c == '{' {
+					// replace with map state
+					newMap := NewMap()
+					newMap.Span.Start = pos
+					stateStack.replaceTop(&jsonParserStateMap{currentMap: newMap})
+				} else if c == '[' {
+					// replace with list state
+					stateStack.replaceTop(&jsonParserStateList{list: OJsonList{Span: Span{Start: pos}}})
+				} else if c == ']' || c == '}' || c == ',' {
+					return nil, newSyntaxError(pos, "misplaced character")
+				} else {
+					// replace with single value
+					stateStack.replaceTop(&jsonParserStateSingleValue{start: pos})
+					done = false
+				}
+			case *jsonParserStateSingleValue:
+				if specificState.buffer.Len() == 0 {
+					specificState.stringEscape = c == '"'
+					specificState.buffer.WriteByte(c)
+				} else {
+					if specificState.stringEscape {
+						specificState.buffer.WriteByte(c)
+						if specificState.escapeNext {
+							specificState.escapeNext = false
+						} else if c == '\\' {
+							specificState.escapeNext = true
+						} else if c == '"' {
+							stateStack.pop()
+							var err error
+							pendingResult, err = specificState.finalize(positionAfter(pos))
+							if err != nil {
+								return nil, err
+							}
+						}
+					} else {
+						if c == ']' || c == '}' || c == ',' || isWhitespace(c) {
+							stateStack.pop()
+							var err error
+							pendingResult, err = specificState.finalize(pos)
+							if err != nil {
+								return nil, err
+							}
+							done = false
+						} else {
+							specificState.buffer.WriteByte(c)
+						}
+					}
+				}
+			case *jsonParserStateList:
+				if pendingResult != nil {
+					specificState.list.Items = append(specificState.list.Items, pendingResult)
+					pendingResult = nil
+				}
+				if isWhitespace(c) {
+					// ignore
+				} else {
+					if c == ']' {
+						specificState.list.Span.End = positionAfter(pos)
+						pendingResult = &specificState.list
+						stateStack.pop()
+					} else if len(specificState.list.Items) == 0 {
+						// new empty list
+						stateStack.push(&jsonParserStateAnyObjPlaceholder{})
+						done = false
+					} else if c == ',' {
+						stateStack.push(&jsonParserStateAnyObjPlaceholder{})
+					} else {
+						return nil, newSyntaxError(pos, "invalid character in list, comma expected")
+					}
+				}
+			case *jsonParserStateMap:
+				if isWhitespace(c) {
+					// ignore
+				} else if c == '}' {
+					specificState.currentMap.Span.End = positionAfter(pos)
+					pendingResult = specificState.currentMap
+					stateStack.pop()
+				} else if c == ',' {
+					stateStack.push(&jsonStateMapKeyValue{})
+				} else if specificState.currentMap.Size() == 0 {
+					stateStack.push(&jsonStateMapKeyValue{})
+					done = false
+				} else {
+					return nil, newSyntaxError(pos, "invalid map state")
+				}
+			case *jsonStateMapKeyValue:
+				switch specificState.state {
+				case 0: // key
+					if specificState.keyBuffer.Len() == 0 {
+						if isWhitespace(c) {
+							// ignore
+						} else {
+							if c != '"' {
+								return nil, newSyntaxError(pos, "map key must start with a quote")
+							}
+							specificState.keySpan.Start = pos
+							specificState.keyBuffer.WriteByte(c)
+						}
+					} else {
+						specificState.keyBuffer.WriteByte(c)
+						if specificState.escapeNext {
+							specificState.escapeNext = false
+						} else if c == '\\' {
+							specificState.escapeNext = true
+						} else if c == '"' {
+							specificState.keySpan.End = positionAfter(pos)
+							specificState.state = 1
+						}
+					}
+				case 1: // ':'
+					if isWhitespace(c) {
+						// ignore
+					} else if c == ':' {
+						specificState.state = 2
+						stateStack.push(&jsonParserStateAnyObjPlaceholder{})
+					} else {
+						return nil, newSyntaxError(pos, "invalid character in map definition, colon expected")
+					}
+				case 2: // value
+					if pendingResult == nil {
+						return nil, newSyntaxError(pos, "missing value in map")
+					}
+					key := specificState.keyBuffer.String()
+					if !strings.HasPrefix(key, "\"") || !strings.HasSuffix(key, "\"") {
+						return nil, newSyntaxError(specificState.keySpan.Start, "map key should be a string enclosed in quotes")
+					}
+					key, err := unescapeJSONString(key[1 : len(key)-1])
+					if err != nil {
+						return nil, newSyntaxError(specificState.keySpan.Start, err.Error())
+					}
+					stateStack.pop()
+					mapState, isMap := stateStack.peek().(*jsonParserStateMap)
+					if !isMap {
+						return nil, newSyntaxError(pos, "map key value state, but no map state underneath")
+					}
+					mapState.currentMap.putWithKeySpan(key, specificState.keySpan, pendingResult)
+					pendingResult = nil
+					done = false
+				default:
+					return nil, newSyntaxError(pos, "unknown jsonStateMapKeyValue state")
+				}
+			default:
+				return nil, newSyntaxError(pos, "invalid parser state")
+			}
+		}
+		tracker.advance(c)
+	}
+
+	if stateStack.size() == 1 {
+		// a top-level value that is not a string has no terminating character
+		if singleValue, isSingleValue := stateStack.peek().(*jsonParserStateSingleValue); isSingleValue && !singleValue.stringEscape {
+			stateStack.pop()
+			var err error
+			pendingResult, err = singleValue.finalize(tracker.current())
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if stateStack.size() != 0 {
+		return nil, newSyntaxError(tracker.current(), "state stack should be empty at the end")
+	}
+
+	return pendingResult, nil
+}
+
+func (s *jsonParserStateSingleValue) finalize(end Position) (OJsonObject, error) {
+	span := Span{Start: s.start, End: end}
+	str := s.buffer.String()
+	if strings.HasPrefix(str, "\"") && strings.HasSuffix(str, "\"") {
+		value, err := unescapeJSONString(str[1 : len(str)-1])
+		if err != nil {
+			return nil, newSyntaxError(s.start, err.Error())
+		}
+		return &OJsonString{Value: value, Span: span}, nil
+	}
+	if str == "true" {
+		return &OJsonBool{Value: true, Span: span}, nil
+	}
+	if str == "false" {
+		return &OJsonBool{Value: false, Span: span}, nil
+	}
+	if str == "null" {
+		return &OJsonNull{Span: span}, nil
+	}
+	if isValidJSONNumber(str) {
+		return &OJsonNumber{Value: str, Span: span}, nil
+	}
+	return nil, newSyntaxError(s.start, "Invalid value: "+str)
+}
+
+type jsonParserStateStack struct {
+	stack []jsonParserState
+}
+
+func (s *jsonParserStateStack) push(state jsonParserState) {
+	s.stack = append(s.stack, state)
+}
+
+func (s *jsonParserStateStack) replaceTop(state jsonParserState) {
+	s.stack[len(s.stack)-1] = state
+}
+
+func (s *jsonParserStateStack) peek() jsonParserState {
+	return s.stack[len(s.stack)-1]
+}
+
+func (s *jsonParserStateStack) pop() jsonParserState {
+	top := s.stack[len(s.stack)-1]
+	s.stack = s.stack[0 : len(s.stack)-1]
+	return top
+}
+
+func (s *jsonParserStateStack) size() int {
+	return len(s.stack)
+}
@@ -0,0 +1,88 @@
+package orderedjson
+
+import "fmt"
+
+// Position is a location in a JSON source.
+// Lines and columns start at 1, columns are counted in bytes.
+// The offset is the 0-based byte index in the source.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// IsValid returns true if the position points somewhere in a source.
+// Objects not created by the parser have invalid (zero) positions.
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+// String yields the "line:column" representation of the position.
+func (pos Position) String() string {
+	if !pos.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// Span is the region of the source occupied by a JSON object.
+// Start points to the first character, End just after the last one.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// IsValid returns true if the span was produced by the parser.
+func (span Span) IsValid() bool {
+	return span.Start.IsValid()
+}
+
+// String yields the "line:column-line:column" representation of the span.
+func (span Span) String() string {
+	return fmt.Sprintf("%s-%s", span.Start, span.End)
+}
+
+// positionTracker computes line and column numbers while the input is being consumed.
+type positionTracker struct {
+	offset int
+	line   int
+	column int
+}
+
+func newPositionTracker() positionTracker {
+	return positionTracker{offset: 0, line: 1, column: 1}
+}
+
+func (pt *positionTracker) current() Position {
+	return Position{Offset: pt.offset, Line: pt.line, Column: pt.column}
+}
+
+func (pt *positionTracker) advance(c byte) {
+	pt.offset++
+	if c == '\n' {
+		pt.line++
+		pt.column = 1
+	} else {
+		pt.column++
+	}
+}
+
+// advanceColumns consumes n characters known not to contain newlines.
+func (pt *positionTracker) advanceColumns(n int) {
+	pt.offset += n
+	pt.column += n
+}
+
+// SyntaxError is returned when the JSON input is malformed.
+type SyntaxError struct {
+	Pos Position
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+func newSyntaxError(pos Position, msg string) *SyntaxError {
+	return &SyntaxError{Pos: pos, Msg: msg}
+}
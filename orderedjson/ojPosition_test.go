@@ -0,0 +1,44 @@
+package orderedjson
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourcePositions(t *testing.T) {
+	input := `{
+    "a": "str",
+    "b": [
+        true,
+        false
+    ]
+}`
+	jobj, err := ParseOrderedJSON([]byte(input))
+	require.Nil(t, err)
+
+	topMap := jobj.(*OJsonMap)
+	require.Equal(t, Position{Offset: 0, Line: 1, Column: 1}, topMap.Span.Start)
+	require.Equal(t, Position{Offset: len(input), Line: 7, Column: 2}, topMap.Span.End)
+
+	kvpA := topMap.OrderedKV[0]
+	require.Equal(t, Position{Offset: 6, Line: 2, Column: 5}, kvpA.KeySpan.Start)
+	require.Equal(t, Position{Offset: 9, Line: 2, Column: 8}, kvpA.KeySpan.End)
+	require.Equal(t, "2:10-2:15", kvpA.Value.SourceSpan().String())
+
+	list := topMap.OrderedKV[1].Value.(*OJsonList)
+	require.Equal(t, "3:10-6:6", list.Span.String())
+	require.Equal(t, "4:9-4:13", list.Items[0].SourceSpan().String())
+	require.Equal(t, "5:9-5:14", list.Items[1].SourceSpan().String())
+}
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	_, err := ParseOrderedJSON([]byte("{\n  \"a\": \"x\",\n  ]\n}"))
+	require.NotNil(t, err)
+
+	var syntaxErr *SyntaxError
+	require.True(t, errors.As(err, &syntaxErr))
+	require.Equal(t, 3, syntaxErr.Pos.Line)
+	require.Equal(t, 3, syntaxErr.Pos.Column)
+}
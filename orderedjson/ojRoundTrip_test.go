@@ -0,0 +1,217 @@
+package orderedjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
+)
+
+// randomJSONGenerator produces arbitrary valid JSON texts,
+// with random formatting and random choices of string escapes.
+// With jsonc set, it also produces comments and trailing commas.
+type randomJSONGenerator struct {
+	rnd   *rand.Rand
+	sb    strings.Builder
+	jsonc bool
+}
+
+var randomRunePool = []rune{
+	'a', 'z', 'A', '0', ' ', '"', '\\', '/', '\b', '\f', '\n', '\r', '\t',
+	0x00, 0x1f, 0x7f, 'é', '€', '中', 0x1F600, 0x10FFFF,
+}
+
+func (g *randomJSONGenerator) whitespace() {
+	for i := g.rnd.Intn(3); i > 0; i-- {
+		g.sb.WriteByte(" \t\r\n"[g.rnd.Intn(4)])
+		if g.jsonc && g.rnd.Intn(4) == 0 {
+			g.sb.WriteString([]string{"// line, comment\n", "/* block, comment */", "/*\n * multi\n */"}[g.rnd.Intn(3)])
+		}
+	}
+}
+
+func (g *randomJSONGenerator) trailingComma(n int) {
+	if g.jsonc && n > 0 && g.rnd.Intn(3) == 0 {
+		g.sb.WriteByte(',')
+		g.whitespace()
+	}
+}
+
+func (g *randomJSONGenerator) stringLiteral(str string) {
+	g.sb.WriteByte('"')
+	for _, r := range str {
+		switch {
+		case r == '"':
+			g.sb.WriteString(`\"`)
+		case r == '\\':
+			g.sb.WriteString(`\\`)
+		case r == '/' && g.rnd.Intn(2) == 0:
+			g.sb.WriteString(`\/`)
+		case r == '\n' && g.rnd.Intn(2) == 0:
+			g.sb.WriteString(`\n`)
+		case r < 0x20 || g.rnd.Intn(5) == 0:
+			if r > 0xffff {
+				r1, r2 := utf16.EncodeRune(r)
+				g.sb.WriteString(fmt.Sprintf(`\u%04x\u%04X`, r1, r2))
+			} else {
+				g.sb.WriteString(fmt.Sprintf(`\u%04x`, r))
+			}
+		default:
+			g.sb.WriteRune(r)
+		}
+	}
+	g.sb.WriteByte('"')
+}
+
+func (g *randomJSONGenerator) randomString() string {
+	var sb strings.Builder
+	for i := g.rnd.Intn(8); i > 0; i-- {
+		sb.WriteRune(randomRunePool[g.rnd.Intn(len(randomRunePool))])
+	}
+	return sb.String()
+}
+
+func (g *randomJSONGenerator) number() {
+	if g.rnd.Intn(2) == 0 {
+		g.sb.WriteByte('-')
+	}
+	if g.rnd.Intn(4) == 0 {
+		g.sb.WriteByte('0')
+	} else {
+		g.sb.WriteString(fmt.Sprintf("%d", 1+g.rnd.Int63()))
+	}
+	if g.rnd.Intn(2) == 0 {
+		g.sb.WriteString(fmt.Sprintf(".%d", g.rnd.Intn(100000)))
+	}
+	if g.rnd.Intn(3) == 0 {
+		g.sb.WriteString([]string{"e", "E", "e+", "E-"}[g.rnd.Intn(4)])
+		g.sb.WriteString(fmt.Sprintf("%d", g.rnd.Intn(300)))
+	}
+}
+
+func (g *randomJSONGenerator) value(depth int) {
+	kind := g.rnd.Intn(7)
+	if depth > 3 && kind >= 5 {
+		kind = g.rnd.Intn(5)
+	}
+	switch kind {
+	case 0:
+		g.stringLiteral(g.randomString())
+	case 1:
+		g.number()
+	case 2:
+		g.sb.WriteString([]string{"true", "false"}[g.rnd.Intn(2)])
+	case 3:
+		g.sb.WriteString("null")
+	case 4:
+		g.stringLiteral(g.randomString())
+	case 5:
+		g.sb.WriteByte('[')
+		g.whitespace()
+		n := g.rnd.Intn(4)
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				g.sb.WriteByte(',')
+			}
+			g.whitespace()
+			g.value(depth + 1)
+			g.whitespace()
+		}
+		g.trailingComma(n)
+		g.sb.WriteByte(']')
+	case 6:
+		g.sb.WriteByte('{')
+		g.whitespace()
+		n := g.rnd.Intn(4)
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				g.sb.WriteByte(',')
+			}
+			g.whitespace()
+			// keys are kept unique, ordered JSON maps ignore duplicate keys
+			g.stringLiteral(fmt.Sprintf("%d%s", i, g.randomString()))
+			g.whitespace()
+			g.sb.WriteByte(':')
+			g.whitespace()
+			g.value(depth + 1)
+			g.whitespace()
+		}
+		g.trailingComma(n)
+		g.sb.WriteByte('}')
+	}
+}
+
+func (g *randomJSONGenerator) document() []byte {
+	g.sb.Reset()
+	g.whitespace()
+	g.value(0)
+	g.whitespace()
+	return []byte(g.sb.String())
+}
+
+// decodeStandard decodes using the standard library, as reference for semantic equality.
+func decodeStandard(t *testing.T, input []byte) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+	var result interface{}
+	require.Nil(t, decoder.Decode(&result), string(input))
+	return result
+}
+
+func TestParseWriteRoundTripProperty(t *testing.T) {
+	gen := &randomJSONGenerator{rnd: rand.New(rand.NewSource(42))}
+	for i := 0; i < 2000; i++ {
+		input := gen.document()
+		require.True(t, json.Valid(input), string(input))
+
+		parsed, err := ParseOrderedJSON(input)
+		require.Nil(t, err, string(input))
+		written := JSONString(parsed)
+
+		// same value as the input
+		require.True(t, json.Valid([]byte(written)), written)
+		require.Equal(t, decodeStandard(t, input), decodeStandard(t, []byte(written)), string(input))
+
+		// writing the output of the writer yields exactly the same text
+		reparsed, err := ParseOrderedJSON([]byte(written))
+		require.Nil(t, err, written)
+		require.Equal(t, written, JSONString(reparsed))
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	parsed, err := ParseOrderedJSON([]byte(`["a\"b", "a\\", "é😀", "\/\b\f\n\r\t", "\ud800x"]`))
+	require.Nil(t, err)
+	items := parsed.(*OJsonList).Items
+	require.Equal(t, `a"b`, items[0].(*OJsonString).Value)
+	require.Equal(t, `a\`, items[1].(*OJsonString).Value)
+	require.Equal(t, "é😀", items[2].(*OJsonString).Value)
+	require.Equal(t, "/\b\f\n\r\t", items[3].(*OJsonString).Value)
+	require.Equal(t, "�x", items[4].(*OJsonString).Value)
+
+	require.Equal(t, `"a\"b\\c\n\u0001é"`, JSONString(&OJsonString{Value: "a\"b\\c\n\x01é"}))
+
+	_, err = ParseOrderedJSON([]byte(`"\x"`))
+	require.NotNil(t, err)
+	_, err = ParseOrderedJSON([]byte("\"a\nb\""))
+	require.NotNil(t, err)
+}
+
+func TestNumbersAndNull(t *testing.T) {
+	parsed, err := ParseOrderedJSON([]byte(`{"a": -12.5e+10, "b": null, "c": 0}`))
+	require.Nil(t, err)
+	topMap := parsed.(*OJsonMap)
+	require.Equal(t, "-12.5e+10", topMap.OrderedKV[0].Value.(*OJsonNumber).Value)
+	require.IsType(t, &OJsonNull{}, topMap.OrderedKV[1].Value)
+	require.Equal(t, "0", topMap.OrderedKV[2].Value.(*OJsonNumber).Value)
+
+	for _, invalid := range []string{"01", "1.", ".5", "-", "1e", "+1", "0x10", "nul"} {
+		_, err = ParseOrderedJSON([]byte(invalid))
+		require.NotNil(t, err, invalid)
+	}
+}
@@ -0,0 +1,184 @@
+package orderedjson
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// unescapeJSONString converts the contents of a JSON string literal, without the quotes,
+// to the string value it represents, as specified in RFC 8259.
+func unescapeJSONString(raw string) (string, error) {
+	if strings.IndexByte(raw, '\\') < 0 {
+		for i := 0; i < len(raw); i++ {
+			if raw[i] < 0x20 {
+				return "", fmt.Errorf("control character 0x%02x not allowed in string", raw[i])
+			}
+		}
+		return raw, nil
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(raw))
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if c < 0x20 {
+			return "", fmt.Errorf("control character 0x%02x not allowed in string", c)
+		}
+		if c != '\\' {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i >= len(raw) {
+			return "", errors.New("unterminated escape sequence in string")
+		}
+		switch raw[i] {
+		case '"', '\\', '/':
+			sb.WriteByte(raw[i])
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'u':
+			r, err := parseHex4(raw, i+1)
+			if err != nil {
+				return "", err
+			}
+			i += 4
+			if utf16.IsSurrogate(r) {
+				// a high surrogate should be followed by an escaped low surrogate,
+				// unpaired surrogates are replaced by U+FFFD, same as encoding/json does
+				decoded := utf8.RuneError
+				if i+2 < len(raw) && raw[i+1] == '\\' && raw[i+2] == 'u' {
+					low, err := parseHex4(raw, i+3)
+					if err != nil {
+						return "", err
+					}
+					decoded = utf16.DecodeRune(r, low)
+					if decoded != utf8.RuneError {
+						i += 6
+					}
+				}
+				r = decoded
+			}
+			sb.WriteRune(r)
+		default:
+			return "", fmt.Errorf("invalid escape sequence \\%c in string", raw[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+func parseHex4(raw string, start int) (rune, error) {
+	if start+4 > len(raw) {
+		return 0, errors.New("incomplete \\u escape sequence in string")
+	}
+	value, err := strconv.ParseUint(raw[start:start+4], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid \\u escape sequence in string: %s", raw[start:start+4])
+	}
+	return rune(value), nil
+}
+
+// writeEscapedJSONString writes a string as a quoted JSON string literal.
+// Only the characters that JSON requires are escaped, everything else is written as-is.
+func writeEscapedJSONString(sb *strings.Builder, str string) {
+	sb.WriteByte('"')
+	start := 0
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c >= 0x20 && c != '"' && c != '\\' {
+			continue
+		}
+		sb.WriteString(str[start:i])
+		switch c {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteString(`\u00`)
+			sb.WriteByte(hexDigits[c>>4])
+			sb.WriteByte(hexDigits[c&0xf])
+		}
+		start = i + 1
+	}
+	sb.WriteString(str[start:])
+	sb.WriteByte('"')
+}
+
+// isValidJSONNumber checks a number literal against the RFC 8259 grammar:
+// [ minus ] int [ frac ] [ exp ]
+func isValidJSONNumber(str string) bool {
+	i := 0
+	if i < len(str) && str[i] == '-' {
+		i++
+	}
+
+	// int
+	switch {
+	case i < len(str) && str[i] == '0':
+		i++
+	case i < len(str) && str[i] >= '1' && str[i] <= '9':
+		i = skipDigits(str, i)
+	default:
+		return false
+	}
+
+	// frac
+	if i < len(str) && str[i] == '.' {
+		i++
+		if i >= len(str) || !isDigit(str[i]) {
+			return false
+		}
+		i = skipDigits(str, i)
+	}
+
+	// exp
+	if i < len(str) && (str[i] == 'e' || str[i] == 'E') {
+		i++
+		if i < len(str) && (str[i] == '+' || str[i] == '-') {
+			i++
+		}
+		if i >= len(str) || !isDigit(str[i]) {
+			return false
+		}
+		i = skipDigits(str, i)
+	}
+
+	return i == len(str)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func skipDigits(str string, i int) int {
+	for i < len(str) && isDigit(str[i]) {
+		i++
+	}
+	return i
+}
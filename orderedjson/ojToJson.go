@@ -28,9 +28,8 @@ func (j *OJsonMap) writeJSON(sb *strings.Builder, indent int) {
 	for i, child := range j.OrderedKV {
 		sb.WriteString("\n")
 		addIndent(sb, indent+1)
-		sb.WriteString("\"")
-		sb.WriteString(child.Key)
-		sb.WriteString("\": ")
+		writeEscapedJSONString(sb, child.Key)
+		sb.WriteString(": ")
 		if child.Value != nil {
 			child.Value.writeJSON(sb, indent+1)
 		}
@@ -65,9 +64,17 @@ func (j *OJsonList) writeJSON(sb *strings.Builder, indent int) {
 }
 
 func (j *OJsonString) writeJSON(sb *strings.Builder, _ int) {
-	sb.WriteString(fmt.Sprintf("\"%s\"", j.Value))
+	writeEscapedJSONString(sb, j.Value)
 }
 
 func (j *OJsonBool) writeJSON(sb *strings.Builder, _ int) {
-	sb.WriteString(fmt.Sprintf("%v", bool(*j)))
+	sb.WriteString(fmt.Sprintf("%v", j.Value))
+}
+
+func (j *OJsonNumber) writeJSON(sb *strings.Builder, _ int) {
+	sb.WriteString(j.Value)
+}
+
+func (j *OJsonNull) writeJSON(sb *strings.Builder, _ int) {
+	sb.WriteString("null")
 }
@@ -0,0 +1,344 @@
+package orderedjson
+
+import (
+	"fmt"
+	"io"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenBeginMap
+	tokenEndMap
+	tokenBeginList
+	tokenEndList
+	tokenColon
+	tokenComma
+	tokenString
+	tokenNumber
+	tokenTrue
+	tokenFalse
+	tokenNull
+)
+
+func (kind tokenKind) String() string {
+	switch kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenBeginMap:
+		return "'{'"
+	case tokenEndMap:
+		return "'}'"
+	case tokenBeginList:
+		return "'['"
+	case tokenEndList:
+		return "']'"
+	case tokenColon:
+		return "':'"
+	case tokenComma:
+		return "','"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number"
+	case tokenTrue, tokenFalse:
+		return "bool"
+	case tokenNull:
+		return "null"
+	default:
+		return "unknown token"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	// text holds the unescaped value of strings and the literal of numbers
+	text string
+	span Span
+	// leading holds the whitespace and comments before the token, only recorded in JSONC mode
+	leading string
+	// raw holds the string literal exactly as it appears in the source, only recorded in JSONC mode
+	raw string
+}
+
+const tokenizerBufferSize = 64 * 1024
+
+// tokenizer splits a JSON input stream into tokens.
+// It reads the input in chunks, so the whole input never needs to be in memory.
+type tokenizer struct {
+	reader  io.Reader
+	buf     []byte
+	start   int
+	end     int
+	readErr error
+	tracker positionTracker
+	scratch []byte
+
+	// jsonc enables comments, and the recording of trivia and raw string literals
+	jsonc  bool
+	trivia []byte
+}
+
+func newTokenizer(reader io.Reader) *tokenizer {
+	return &tokenizer{
+		reader:  reader,
+		buf:     make([]byte, tokenizerBufferSize),
+		tracker: newPositionTracker(),
+	}
+}
+
+func newBytesTokenizer(input []byte) *tokenizer {
+	return &tokenizer{
+		buf:     input,
+		end:     len(input),
+		readErr: io.EOF,
+		tracker: newPositionTracker(),
+	}
+}
+
+// fill makes sure there is at least one unread byte in the buffer, if the input is not exhausted.
+func (t *tokenizer) fill() bool {
+	for t.start >= t.end {
+		if t.readErr != nil {
+			return false
+		}
+		var n int
+		n, t.readErr = t.reader.Read(t.buf)
+		t.start = 0
+		t.end = n
+	}
+	return true
+}
+
+func (t *tokenizer) peekByte() (byte, bool) {
+	if !t.fill() {
+		return 0, false
+	}
+	return t.buf[t.start], true
+}
+
+func (t *tokenizer) readByte() (byte, bool) {
+	if !t.fill() {
+		return 0, false
+	}
+	c := t.buf[t.start]
+	t.start++
+	t.tracker.advance(c)
+	return c, true
+}
+
+// inputError yields the read error, if reading failed for reasons other than reaching the end.
+func (t *tokenizer) inputError() error {
+	if t.readErr != nil && t.readErr != io.EOF {
+		return t.readErr
+	}
+	return nil
+}
+
+func (t *tokenizer) skipWhitespace() {
+	for t.fill() {
+		for t.start < t.end {
+			c := t.buf[t.start]
+			if !isWhitespace(c) {
+				return
+			}
+			t.start++
+			t.tracker.advance(c)
+		}
+	}
+}
+
+func (t *tokenizer) next() (token, error) {
+	if !t.jsonc {
+		t.skipWhitespace()
+		return t.nextAfterTrivia()
+	}
+
+	err := t.readTrivia()
+	if err != nil {
+		return token{}, err
+	}
+	leading := string(t.trivia)
+	tok, err := t.nextAfterTrivia()
+	tok.leading = leading
+	return tok, err
+}
+
+// readTrivia consumes whitespace and comments, and stores them in the trivia buffer.
+func (t *tokenizer) readTrivia() error {
+	t.trivia = t.trivia[:0]
+	for {
+		c, ok := t.peekByte()
+		if !ok {
+			return t.inputError()
+		}
+		if isWhitespace(c) {
+			t.readByte()
+			t.trivia = append(t.trivia, c)
+			continue
+		}
+		if c != '/' {
+			return nil
+		}
+
+		start := t.tracker.current()
+		t.readByte()
+		t.trivia = append(t.trivia, c)
+		c, ok = t.readByte()
+		switch {
+		case ok && c == '/':
+			t.trivia = append(t.trivia, c)
+			for {
+				c, ok = t.peekByte()
+				if !ok || c == '\n' {
+					break
+				}
+				t.readByte()
+				t.trivia = append(t.trivia, c)
+			}
+		case ok && c == '*':
+			t.trivia = append(t.trivia, c)
+			prevStar := false
+			for {
+				c, ok = t.readByte()
+				if !ok {
+					if err := t.inputError(); err != nil {
+						return err
+					}
+					return newSyntaxError(start, "unterminated comment")
+				}
+				t.trivia = append(t.trivia, c)
+				if prevStar && c == '/' {
+					break
+				}
+				prevStar = c == '*'
+			}
+		default:
+			return newSyntaxError(start, "unexpected character '/'")
+		}
+	}
+}
+
+func (t *tokenizer) nextAfterTrivia() (token, error) {
+	start := t.tracker.current()
+	c, ok := t.peekByte()
+	if !ok {
+		if err := t.inputError(); err != nil {
+			return token{}, err
+		}
+		return token{kind: tokenEOF, span: Span{Start: start, End: start}}, nil
+	}
+
+	var kind tokenKind
+	switch c {
+	case '{':
+		kind = tokenBeginMap
+	case '}':
+		kind = tokenEndMap
+	case '[':
+		kind = tokenBeginList
+	case ']':
+		kind = tokenEndList
+	case ':':
+		kind = tokenColon
+	case ',':
+		kind = tokenComma
+	case '"':
+		return t.nextString(start)
+	default:
+		return t.nextLiteral(start)
+	}
+	t.readByte()
+	return token{kind: kind, span: Span{Start: start, End: t.tracker.current()}}, nil
+}
+
+func (t *tokenizer) nextString(start Position) (token, error) {
+	t.readByte() // opening quote
+	t.scratch = t.scratch[:0]
+	escapeNext := false
+	for {
+		if !t.fill() {
+			if err := t.inputError(); err != nil {
+				return token{}, err
+			}
+			return token{}, newSyntaxError(start, "unterminated string")
+		}
+
+		// fast path: copy everything up to the next special character in one go
+		chunk := t.buf[t.start:t.end]
+		plain := 0
+		if !escapeNext {
+			for plain < len(chunk) && chunk[plain] != '"' && chunk[plain] != '\\' && chunk[plain] >= 0x20 {
+				plain++
+			}
+			t.scratch = append(t.scratch, chunk[:plain]...)
+			t.start += plain
+			t.tracker.advanceColumns(plain)
+			if plain == len(chunk) {
+				continue
+			}
+		}
+
+		c, _ := t.readByte()
+		if escapeNext {
+			escapeNext = false
+		} else if c == '\\' {
+			escapeNext = true
+		} else if c == '"' {
+			break
+		}
+		t.scratch = append(t.scratch, c)
+	}
+
+	value, err := unescapeJSONString(string(t.scratch))
+	if err != nil {
+		return token{}, newSyntaxError(start, err.Error())
+	}
+	tok := token{
+		kind: tokenString,
+		text: value,
+		span: Span{Start: start, End: t.tracker.current()},
+	}
+	if t.jsonc {
+		tok.raw = `"` + string(t.scratch) + `"`
+	}
+	return tok, nil
+}
+
+func isLiteralByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || isDigit(c) || c == '-' || c == '+' || c == '.'
+}
+
+func (t *tokenizer) nextLiteral(start Position) (token, error) {
+	t.scratch = t.scratch[:0]
+	for {
+		c, ok := t.peekByte()
+		if !ok || !isLiteralByte(c) {
+			break
+		}
+		t.readByte()
+		t.scratch = append(t.scratch, c)
+	}
+	span := Span{Start: start, End: t.tracker.current()}
+
+	if len(t.scratch) == 0 {
+		c, _ := t.readByte()
+		return token{}, newSyntaxError(start, fmt.Sprintf("unexpected character '%c'", c))
+	}
+
+	switch string(t.scratch) {
+	case "true":
+		return token{kind: tokenTrue, span: span}, nil
+	case "false":
+		return token{kind: tokenFalse, span: span}, nil
+	case "null":
+		return token{kind: tokenNull, span: span}, nil
+	}
+
+	literal := string(t.scratch)
+	if !isValidJSONNumber(literal) {
+		return token{}, newSyntaxError(start, "Invalid value: "+literal)
+	}
+	return token{kind: tokenNumber, text: literal, span: span}, nil
+}
@@ -0,0 +1,192 @@
+package orderedjson
+
+import "strings"
+
+// Trivia holds the whitespace and comments around a JSON element, exactly as they appear in the source.
+// It is only recorded when parsing in JSONC mode, and is used by the lossless writer.
+//
+// For map entries, the element trivia is held by the key-value pair.
+// For list items and for the top-level object, it is held by the object itself.
+type Trivia struct {
+	// Leading is found between the previous element, or the opening bracket, and this element.
+	Leading string
+
+	// AfterKey is found between a map key and the colon.
+	AfterKey string
+
+	// AfterColon is found between the colon and the map value.
+	AfterColon string
+
+	// Trailing is found between the element and the separating comma,
+	// or the end of the line, if there is no comma.
+	// For the top-level object, it holds everything up to the end of the input.
+	Trailing string
+
+	// AfterComma is found between the separating comma and the end of the line.
+	AfterComma string
+
+	// BeforeClose is found before the closing bracket of a map or list, after the last element.
+	BeforeClose string
+
+	// TrailingComma indicates that the last element of a map or list is followed by a comma.
+	TrailingComma bool
+}
+
+// rawLiteral is a string literal from the source.
+// It is only written back as long as the value it was decoded to remains unchanged.
+type rawLiteral struct {
+	text  string
+	value string
+}
+
+func (raw rawLiteral) validFor(value string) bool {
+	return len(raw.text) > 0 && raw.value == value
+}
+
+func (j *OJsonMap) getTrivia() *Trivia {
+	return j.Trivia
+}
+
+func (j *OJsonMap) setTrivia(trivia *Trivia) {
+	j.Trivia = trivia
+}
+
+func (j *OJsonList) getTrivia() *Trivia {
+	return j.Trivia
+}
+
+func (j *OJsonList) setTrivia(trivia *Trivia) {
+	j.Trivia = trivia
+}
+
+func (j *OJsonString) getTrivia() *Trivia {
+	return j.Trivia
+}
+
+func (j *OJsonString) setTrivia(trivia *Trivia) {
+	j.Trivia = trivia
+}
+
+func (j *OJsonBool) getTrivia() *Trivia {
+	return j.Trivia
+}
+
+func (j *OJsonBool) setTrivia(trivia *Trivia) {
+	j.Trivia = trivia
+}
+
+func (j *OJsonNumber) getTrivia() *Trivia {
+	return j.Trivia
+}
+
+func (j *OJsonNumber) setTrivia(trivia *Trivia) {
+	j.Trivia = trivia
+}
+
+func (j *OJsonNull) getTrivia() *Trivia {
+	return j.Trivia
+}
+
+func (j *OJsonNull) setTrivia(trivia *Trivia) {
+	j.Trivia = trivia
+}
+
+// splitTrivia splits trivia at the first line break that is not part of a comment.
+// The line break goes into the second part.
+func splitTrivia(trivia string) (sameLine string, rest string) {
+	for i := 0; i < len(trivia); i++ {
+		switch {
+		case trivia[i] == '\n':
+			return trivia[:i], trivia[i:]
+		case strings.HasPrefix(trivia[i:], "//"):
+			end := strings.IndexByte(trivia[i:], '\n')
+			if end < 0 {
+				return trivia, ""
+			}
+			return trivia[:i+end], trivia[i+end:]
+		case strings.HasPrefix(trivia[i:], "/*"):
+			end := strings.Index(trivia[i+2:], "*/")
+			if end < 0 {
+				return trivia, ""
+			}
+			i += end + 3
+		}
+	}
+	return trivia, ""
+}
+
+// endsInLineComment returns true if anything written right after the trivia would be commented out.
+func endsInLineComment(trivia string) bool {
+	for i := 0; i < len(trivia); i++ {
+		switch {
+		case strings.HasPrefix(trivia[i:], "//"):
+			end := strings.IndexByte(trivia[i:], '\n')
+			if end < 0 {
+				return true
+			}
+			i += end
+		case strings.HasPrefix(trivia[i:], "/*"):
+			end := strings.Index(trivia[i+2:], "*/")
+			if end < 0 {
+				return false
+			}
+			i += end + 3
+		}
+	}
+	return false
+}
+
+// TransferTrivia copies comments, formatting and original literals from a tree parsed in JSONC mode
+// onto an equivalent tree, typically one regenerated from a model after some edits.
+// Map entries are matched by key, list items by position.
+// Objects that are already present in both trees are left as they are.
+func TransferTrivia(from OJsonObject, to OJsonObject) {
+	if from == nil || to == nil || from == to {
+		return
+	}
+	if to.getTrivia() == nil && from.getTrivia() != nil {
+		trivia := *from.getTrivia()
+		to.setTrivia(&trivia)
+	}
+
+	switch toValue := to.(type) {
+	case *OJsonMap:
+		fromMap, isMap := from.(*OJsonMap)
+		if !isMap {
+			return
+		}
+		fromEntries := make(map[string]*OJsonKeyValuePair, len(fromMap.OrderedKV))
+		for _, kvp := range fromMap.OrderedKV {
+			fromEntries[kvp.Key] = kvp
+		}
+		for _, kvp := range toValue.OrderedKV {
+			fromKvp, found := fromEntries[kvp.Key]
+			if !found {
+				continue
+			}
+			if kvp.Trivia == nil && fromKvp.Trivia != nil {
+				trivia := *fromKvp.Trivia
+				kvp.Trivia = &trivia
+			}
+			if !kvp.rawKey.validFor(kvp.Key) {
+				kvp.rawKey = fromKvp.rawKey
+			}
+			TransferTrivia(fromKvp.Value, kvp.Value)
+		}
+	case *OJsonList:
+		fromList, isList := from.(*OJsonList)
+		if !isList {
+			return
+		}
+		for i, item := range toValue.Items {
+			if i < len(fromList.Items) {
+				TransferTrivia(fromList.Items[i], item)
+			}
+		}
+	case *OJsonString:
+		fromString, isString := from.(*OJsonString)
+		if isString && !toValue.raw.validFor(toValue.Value) {
+			toValue.raw = fromString.raw
+		}
+	}
+}
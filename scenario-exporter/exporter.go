@@ -6,6 +6,7 @@ import (
 
 	mc "github.com/bhagyaraj1208117/andes-scenario-go/controller"
 	"github.com/bhagyaraj1208117/andes-scenario-go/dctconvert"
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
 	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
 )
 
@@ -50,27 +51,33 @@ func getInvalidScenarioWithBenchmark() ScenarioWithBenchmark {
 
 // GetAccountsAndTransactionsFromScenarios will retrieve the ScenarioWithBenchmark component
 func GetAccountsAndTransactionsFromScenarios(testPath string) (stateAndBenchmarkInfo ScenarioWithBenchmark, err error) {
-	scenario, err := getScenario(testPath)
+	parser := mjparse.NewParser(mc.NewDefaultFileResolver())
+	scenario, err := mc.ParseScenariosScenario(parser, testPath)
 	if err != nil {
 		return getInvalidScenarioWithBenchmark(), err
 	}
 	steps := scenario.Steps
-	stateAndBenchmarkInfo, err = getAccountsAndTransactionsFromSteps(steps)
+	stateAndBenchmarkInfo, err = getAccountsAndTransactionsFromSteps(parser, steps)
 	if err != nil {
 		return getInvalidScenarioWithBenchmark(), err
 	}
 	return stateAndBenchmarkInfo, nil
 }
 
-func getScenario(testPath string) (scenario *mj.Scenario, err error) {
-	scenario, err = mc.ParseScenariosScenarioDefaultParser(testPath)
+// getAccountsAndTransactionsFromExternalSteps loads the steps file the same way the scenario runner does:
+// relative to the file that references it, with the constants in scope at the step.
+func getAccountsAndTransactionsFromExternalSteps(parser mjparse.Parser, step *mj.ExternalStepsStep) (ScenarioWithBenchmark, error) {
+	externalScenario, err := mc.ParseExternalSteps(parser, step)
 	if err != nil {
-		return nil, err
+		return getInvalidScenarioWithBenchmark(), err
 	}
-	return scenario, err
+	externalParser := parser
+	externalParser.ExprInterpreter.FileResolver = parser.ExprInterpreter.FileResolver.Clone()
+	externalParser.ExprInterpreter.FileResolver.SetContext(parser.ExprInterpreter.FileResolver.ResolveAbsolutePath(step.Path))
+	return getAccountsAndTransactionsFromSteps(externalParser, externalScenario.Steps)
 }
 
-func getAccountsAndTransactionsFromSteps(steps []mj.Step) (stateAndBenchmarkInfo ScenarioWithBenchmark, err error) {
+func getAccountsAndTransactionsFromSteps(parser mjparse.Parser, steps []mj.Step) (stateAndBenchmarkInfo ScenarioWithBenchmark, err error) {
 	stateAndBenchmarkInfo.BenchmarkTxPos = InvalidBenchmarkTxPos
 
 	if len(steps) == 0 {
@@ -147,7 +154,7 @@ func getAccountsAndTransactionsFromSteps(steps []mj.Step) (stateAndBenchmarkInfo
 				}
 			}
 		case *mj.ExternalStepsStep:
-			externalStateAndBenchmarkInfo, err := GetAccountsAndTransactionsFromScenarios(step.Path)
+			externalStateAndBenchmarkInfo, err := getAccountsAndTransactionsFromExternalSteps(parser, step)
 			if err != nil {
 				return getInvalidScenarioWithBenchmark(), err
 			}
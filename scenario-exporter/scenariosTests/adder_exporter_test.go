@@ -75,3 +75,16 @@ func TestGetAccountsAndTransactionsFrom_AdderWithExternalSteps(t *testing.T) {
 	require.Equal(t, expectedTxs, sbi.Txs)
 	require.Equal(t, expectedDeployTxs, sbi.DeployTxs)
 }
+
+func TestGetAccountsAndTransactionsFrom_AdderWithExternalStepsConstants(t *testing.T) {
+	sbi, err := mge.GetAccountsAndTransactionsFromScenarios("adder_with_external_steps_constants.scen.json")
+	require.Nil(t, err)
+
+	bobAccount := mge.SetNewAccount(3, addressBob, big.NewInt(11), make(map[string][]byte), make([]byte, 0), make([]byte, 0))
+	scAccount := mge.SetNewAccount(0, append(mge.ScAddressPrefix, addressAdder[mge.ScAddressPrefixLength:]...), big.NewInt(0), make(map[string][]byte), util.GetSCCode("adder.wasm"), addressBob)
+	require.Equal(t, []*mge.TestAccount{bobAccount, scAccount}, sbi.Accs)
+
+	transaction := mge.CreateTransaction("add", [][]byte{{3}}, 0, big.NewInt(0), make([]*mj.DCTTxData, 0), sbi.Accs[0].GetAddress(), sbi.Accs[1].GetAddress(), 5000000, 1)
+	require.Equal(t, 0, sbi.BenchmarkTxPos)
+	require.Equal(t, []*mge.Transaction{transaction}, sbi.Txs)
+}
@@ -0,0 +1,151 @@
+package scenario_expr
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	er "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+)
+
+// OutputFormat is a way of printing encoded values.
+type OutputFormat int
+
+const (
+	// HexOutput prints values as "0x..."
+	HexOutput OutputFormat = iota
+
+	// Base64Output prints values in standard base64.
+	Base64Output
+
+	// ByteArrayOutput prints values as a list of decimal bytes, e.g. "[1, 2, 3]".
+	ByteArrayOutput
+)
+
+// ParseOutputFormat reads an output format name: "hex", "base64" or "bytes".
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	switch name {
+	case "hex":
+		return HexOutput, nil
+	case "base64":
+		return Base64Output, nil
+	case "bytes":
+		return ByteArrayOutput, nil
+	default:
+		return HexOutput, fmt.Errorf("unknown output format: %s, expected hex, base64 or bytes", name)
+	}
+}
+
+// ParseHint reads a decoding hint name: "address", "number", "str", or "" for none.
+func ParseHint(name string) (er.ExprReconstructorHint, error) {
+	switch name {
+	case "":
+		return er.NoHint, nil
+	case "address":
+		return er.AddressHint, nil
+	case "number":
+		return er.NumberHint, nil
+	case "str":
+		return er.StrHint, nil
+	default:
+		return er.NoHint, fmt.Errorf("unknown hint: %s, expected address, number or str", name)
+	}
+}
+
+// Tool encodes value expressions to bytes, and decodes bytes to value expressions.
+type Tool struct {
+	Interpreter   ei.ExprInterpreter
+	Reconstructor er.ExprReconstructor
+	Output        OutputFormat
+}
+
+// NewTool creates a Tool that resolves "file:" and "mxsc:" paths relative to the given directory.
+func NewTool(dir string) *Tool {
+	fileResolver := fr.NewDefaultFileResolver()
+	// the context is a file, paths are relative to its directory
+	fileResolver.SetContext(filepath.Join(dir, "expression"))
+	return &Tool{
+		Interpreter: ei.ExprInterpreter{
+			FileResolver: fileResolver,
+		},
+	}
+}
+
+// Encode yields the bytes of a value expression, in the output format.
+func (t *Tool) Encode(expression string) (string, error) {
+	value, err := t.Interpreter.InterpretString(expression)
+	if err != nil {
+		return "", err
+	}
+	return t.FormatBytes(value), nil
+}
+
+// Decode yields a value expression for bytes written as hex, with or without "0x", as a byte array or in base64.
+func (t *Tool) Decode(input string, hint er.ExprReconstructorHint) (string, error) {
+	value, err := ParseBytes(input)
+	if err != nil {
+		return "", err
+	}
+	return t.Reconstructor.Reconstruct(value, hint), nil
+}
+
+// FormatBytes prints a value in the output format.
+func (t *Tool) FormatBytes(value []byte) string {
+	switch t.Output {
+	case Base64Output:
+		return base64.StdEncoding.EncodeToString(value)
+	case ByteArrayOutput:
+		items := make([]string, len(value))
+		for i, b := range value {
+			items[i] = strconv.Itoa(int(b))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return "0x" + hex.EncodeToString(value)
+	}
+}
+
+// ParseBytes reads bytes written in any of the output formats.
+// Inputs that are both valid hex and base64 are read as hex.
+func ParseBytes(input string) ([]byte, error) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "[") {
+		return parseByteArray(input)
+	}
+	hexInput := strings.TrimPrefix(strings.TrimPrefix(input, "0x"), "0X")
+	if value, err := hex.DecodeString(hexInput); err == nil {
+		return value, nil
+	}
+	if strings.HasPrefix(input, "0x") || strings.HasPrefix(input, "0X") {
+		return nil, fmt.Errorf("invalid hex: %s", input)
+	}
+	value, err := base64.StdEncoding.DecodeString(input)
+	if err != nil {
+		return nil, fmt.Errorf("not hex, base64 or a byte array: %s", input)
+	}
+	return value, nil
+}
+
+func parseByteArray(input string) ([]byte, error) {
+	if !strings.HasSuffix(input, "]") {
+		return nil, fmt.Errorf("unclosed byte array: %s", input)
+	}
+	contents := strings.TrimSpace(input[1 : len(input)-1])
+	if len(contents) == 0 {
+		return []byte{}, nil
+	}
+	var value []byte
+	for _, item := range strings.Split(contents, ",") {
+		b, err := strconv.ParseUint(strings.TrimSpace(item), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte %q in byte array", strings.TrimSpace(item))
+		}
+		value = append(value, byte(b))
+	}
+	return value, nil
+}
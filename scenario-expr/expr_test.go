@@ -0,0 +1,91 @@
+package scenario_expr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	er "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "code.wasm"), []byte{0x00, 0x61, 0x73, 0x6d}, 0644))
+	tool := NewTool(dir)
+
+	for output, expected := range map[OutputFormat]string{
+		HexOutput:       "0x000000036162630000000105",
+		Base64Output:    "AAAAA2FiYwAAAAEF",
+		ByteArrayOutput: "[0, 0, 0, 3, 97, 98, 99, 0, 0, 0, 1, 5]",
+	} {
+		tool.Output = output
+		result, err := tool.Encode("nested:(str:abc)|biguint:5")
+		require.Nil(t, err)
+		require.Equal(t, expected, result)
+	}
+
+	tool.Output = HexOutput
+	result, err := tool.Encode("file:code.wasm")
+	require.Nil(t, err)
+	require.Equal(t, "0x0061736d", result)
+
+	_, err = tool.Encode("file:missing.wasm")
+	require.NotNil(t, err)
+}
+
+func TestDecode(t *testing.T) {
+	tool := NewTool(".")
+	for _, input := range []string{"0x616263", "616263", "YWJj", "[97, 98, 99]"} {
+		result, err := tool.Decode(input, er.StrHint)
+		require.Nil(t, err, input)
+		require.Equal(t, "str:abc", result, input)
+	}
+
+	result, err := tool.Decode("0x0100", er.NumberHint)
+	require.Nil(t, err)
+	require.Equal(t, "256", result)
+
+	owner, err := tool.Encode("address:owner")
+	require.Nil(t, err)
+	result, err = tool.Decode(owner, er.AddressHint)
+	require.Nil(t, err)
+	require.Equal(t, "address:owner", result)
+
+	for _, invalid := range []string{"0xabc", "[1, 256]", "[1, 2", "not base64!"} {
+		_, err = tool.Decode(invalid, er.NoHint)
+		require.NotNil(t, err, invalid)
+	}
+
+	_, err = ParseHint("code")
+	require.NotNil(t, err)
+	_, err = ParseOutputFormat("binary")
+	require.NotNil(t, err)
+}
+
+func TestREPL(t *testing.T) {
+	tool := NewTool(".")
+	input := strings.Join([]string{
+		"u16:5",
+		":format bytes",
+		"u16:5",
+		":decode str 0x616263",
+		":decode 0x05",
+		"u8:256",
+		":unknown",
+		":quit",
+		"u8:1",
+	}, "\n")
+	var out strings.Builder
+	require.Nil(t, tool.RunREPL(strings.NewReader(input), &out))
+	require.Equal(t, strings.Join([]string{
+		"> 0x0005",
+		"> > [0, 5]",
+		"> str:abc",
+		"> 0x05 (5)",
+		"> error: representation of 256 does not fit in 1 bytes (at offset 0)",
+		"> error: unknown command :unknown, type :help for the commands",
+		"> ",
+	}, "\n"), out.String())
+}
@@ -0,0 +1,80 @@
+package scenario_expr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	er "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
+)
+
+const replHelp = `<expression>                        print the bytes of a value expression
+:decode [address|number|str] <bytes>  print bytes as a value expression
+:format hex|base64|bytes            change the output format
+:help                               print this help
+:quit                               exit
+`
+
+// RunREPL reads commands line by line, until the end of the input or ":quit".
+// Errors are printed, and do not stop the loop.
+func (t *Tool) RunREPL(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		_, err := fmt.Fprint(out, "> ")
+		if err != nil {
+			return err
+		}
+		if !scanner.Scan() {
+			_, err = fmt.Fprintln(out)
+			if err != nil {
+				return err
+			}
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == ":quit" {
+			return nil
+		}
+		result, err := t.runCommand(line)
+		if err != nil {
+			result = "error: " + err.Error()
+		}
+		if len(result) > 0 {
+			_, err = fmt.Fprintln(out, strings.TrimSuffix(result, "\n"))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (t *Tool) runCommand(line string) (string, error) {
+	if !strings.HasPrefix(line, ":") {
+		return t.Encode(line)
+	}
+
+	command, args, _ := strings.Cut(line, " ")
+	args = strings.TrimSpace(args)
+	switch command {
+	case ":help":
+		return replHelp, nil
+	case ":format":
+		format, err := ParseOutputFormat(args)
+		if err != nil {
+			return "", err
+		}
+		t.Output = format
+		return "", nil
+	case ":decode":
+		hint := er.NoHint
+		hintName, input, hasInput := strings.Cut(args, " ")
+		if parsed, err := ParseHint(hintName); err == nil && hasInput {
+			hint = parsed
+			args = strings.TrimSpace(input)
+		}
+		return t.Decode(args, hint)
+	default:
+		return "", fmt.Errorf("unknown command %s, type :help for the commands", command)
+	}
+}
@@ -0,0 +1,132 @@
+package scenario_format
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	mjwrite "github.com/bhagyaraj1208117/andes-scenario-go/json/write"
+)
+
+// Format yields the canonical form of a scenario or steps file:
+// fixed key order per step type, standard indentation and the compact DCT syntax wherever possible.
+// The file path is only used in error messages.
+func Format(filePath string, source []byte) ([]byte, error) {
+	parser := mjparse.NewParser(newPlaceholderFileResolver())
+	parser.ExprInterpreter.FileResolver.SetContext(filePath)
+	parser.SourcePath = filePath
+	scenario, err := parser.ParseScenarioFile(source)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(mjwrite.ScenarioToJSONString(scenario)), nil
+}
+
+// Result is the outcome of formatting one file.
+type Result struct {
+	FilePath  string
+	Original  []byte
+	Formatted []byte
+}
+
+// Changed indicates that the file is not in canonical form.
+func (r *Result) Changed() bool {
+	return string(r.Original) != string(r.Formatted)
+}
+
+// Diff yields a unified diff from the original to the formatted contents.
+func (r *Result) Diff() string {
+	return UnifiedDiff(r.FilePath, r.FilePath+".formatted", r.Original, r.Formatted)
+}
+
+// FormatFile reads and formats a scenario file, without writing it back.
+func FormatFile(filePath string) (*Result, error) {
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := Format(filePath, source)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		FilePath:  filePath,
+		Original:  source,
+		Formatted: formatted,
+	}, nil
+}
+
+// IsScenarioFile indicates whether a path is handled by the formatter, based on its extension.
+func IsScenarioFile(path string) bool {
+	return strings.HasSuffix(path, ".scen.json") || strings.HasSuffix(path, ".steps.json")
+}
+
+// CollectFiles expands directories to the scenario files they contain, recursively.
+// Files given explicitly are kept, whatever their extension.
+func CollectFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.Walk(path, func(walkPath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && IsScenarioFile(walkPath) {
+				files = append(files, walkPath)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot list %s: %w", path, err)
+		}
+	}
+	return files, nil
+}
+
+var _ fr.FileResolver = (*placeholderFileResolver)(nil)
+
+// placeholderFileResolver does not read any files, except contract ABIs, which determine how arguments are encoded.
+// Formatting only needs the original expressions, so referenced contracts do not need to be built.
+type placeholderFileResolver struct {
+	abiResolver fr.FileResolver
+}
+
+func newPlaceholderFileResolver() *placeholderFileResolver {
+	return &placeholderFileResolver{
+		abiResolver: fr.NewDefaultFileResolver(),
+	}
+}
+
+// placeholderFileContents is also accepted as an mxsc.json file.
+var placeholderFileContents = []byte(`{"code":""}`)
+
+func (pfr *placeholderFileResolver) Clone() fr.FileResolver {
+	return &placeholderFileResolver{
+		abiResolver: pfr.abiResolver.Clone(),
+	}
+}
+
+func (pfr *placeholderFileResolver) SetContext(contextPath string) {
+	pfr.abiResolver.SetContext(contextPath)
+}
+
+func (pfr *placeholderFileResolver) ResolveAbsolutePath(value string) string {
+	return value
+}
+
+func (pfr *placeholderFileResolver) ResolveFileValue(value string) ([]byte, error) {
+	if strings.HasSuffix(value, ".abi.json") {
+		return pfr.abiResolver.ResolveFileValue(value)
+	}
+	return placeholderFileContents, nil
+}
@@ -0,0 +1,96 @@
+package scenario_format
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatIsIdempotent(t *testing.T) {
+	for _, path := range []string{
+		"../json/integrationTests/example.scen.json",
+		"../scenario-exporter/scenariosTests/adder.scen.json",
+		"../scenario-exporter/scenariosTests/adder_with_external_steps.scen.json",
+	} {
+		source, err := os.ReadFile(path)
+		require.Nil(t, err)
+		formatted, err := Format(path, source)
+		require.Nil(t, err, path)
+		reformatted, err := Format(path, formatted)
+		require.Nil(t, err, path)
+		require.Equal(t, string(formatted), string(reformatted), path)
+	}
+
+	// the example file is already formatted
+	result, err := FormatFile("../json/integrationTests/example.scen.json")
+	require.Nil(t, err)
+	require.False(t, result.Changed())
+	require.Equal(t, "", result.Diff())
+}
+
+func TestFormatCanonicalForm(t *testing.T) {
+	source := `{"steps":[{"tx":{"to":"sc:b","from":"address:a","value":"0","function":"f","arguments":[],"gasLimit":"5","gasPrice":"0"},"step":"scCall"},
+{"step":"setState","accounts":{"address:a":{"dct":{"str:TOK-123456":{"instances":[{"balance":"10"}]}}}}}]}`
+	formatted, err := Format("unformatted.scen.json", []byte(source))
+	require.Nil(t, err)
+	require.Equal(t, `{
+    "steps": [
+        {
+            "step": "scCall",
+            "tx": {
+                "from": "address:a",
+                "to": "sc:b",
+                "function": "f",
+                "arguments": [],
+                "gasLimit": "5",
+                "gasPrice": "0"
+            }
+        },
+        {
+            "step": "setState",
+            "accounts": {
+                "address:a": {
+                    "dct": {
+                        "str:TOK-123456": "10"
+                    }
+                }
+            }
+        }
+    ]
+}
+`, string(formatted))
+
+	_, err = Format("invalid.scen.json", []byte(`{"steps": [{"step": "noSuchStep"}]}`))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "invalid.scen.json")
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	newText := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn"
+	require.Equal(t, `--- old
++++ new
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -11,3 +11,4 @@
+ k
+ l
+ m
++n
+\ No newline at end of file
+`, UnifiedDiff("old", "new", []byte(oldText), []byte(newText)))
+
+	require.Equal(t, "", UnifiedDiff("old", "new", []byte(oldText), []byte(oldText)))
+
+	require.Equal(t, `--- old
++++ new
+@@ -0,0 +1,1 @@
++x
+`, UnifiedDiff("old", "new", nil, []byte("x\n")))
+}
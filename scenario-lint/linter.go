@@ -0,0 +1,182 @@
+package scenario_lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	mc "github.com/bhagyaraj1208117/andes-scenario-go/controller"
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+)
+
+// ParseErrorRuleID is the rule ID of findings coming from the parser. They cannot be suppressed.
+const ParseErrorRuleID = "parse-error"
+
+// Finding is a problem reported by a lint rule.
+type Finding struct {
+	RuleID   string
+	Severity mjparse.Severity
+	FilePath string
+	Pos      oj.Position
+	KeyPath  string
+	Message  string
+}
+
+// String yields a message of the form "file:line:column: severity: [rule] keyPath: message".
+func (f *Finding) String() string {
+	var sb strings.Builder
+	if len(f.FilePath) > 0 {
+		sb.WriteString(f.FilePath)
+		sb.WriteString(":")
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s: [%s] ", f.Pos, f.Severity, f.RuleID))
+	if len(f.KeyPath) > 0 {
+		sb.WriteString(f.KeyPath)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(f.Message)
+	return sb.String()
+}
+
+// Linter runs a set of rules over scenario files.
+type Linter struct {
+	Rules  []Rule
+	Parser mjparse.Parser
+}
+
+// NewLinter creates a linter with all the default rules.
+// Its parser accepts comments, which are needed for suppressions.
+func NewLinter() *Linter {
+	parser := mjparse.NewParser(mc.NewDefaultFileResolver())
+	parser.AllowJSONC = true
+	return &Linter{
+		Rules:  DefaultRules(),
+		Parser: parser,
+	}
+}
+
+// DisableRules removes rules by ID.
+func (l *Linter) DisableRules(ruleIDs ...string) {
+	var remaining []Rule
+	for _, rule := range l.Rules {
+		if !containsString(ruleIDs, rule.ID()) {
+			remaining = append(remaining, rule)
+		}
+	}
+	l.Rules = remaining
+}
+
+// LintFile reads and checks a scenario file.
+func (l *Linter) LintFile(filePath string) ([]*Finding, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	source, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, err
+	}
+	return l.LintSource(absPath, source), nil
+}
+
+// LintSource checks the contents of a scenario file.
+// The file path is used to resolve relative paths and in the findings.
+func (l *Linter) LintSource(filePath string, source []byte) []*Finding {
+	parser := l.Parser
+	parser.ExprInterpreter.FileResolver = parser.ExprInterpreter.FileResolver.Clone()
+	parser.ExprInterpreter.FileResolver.SetContext(filePath)
+	parser.SourcePath = filePath
+	scenario, diagnostics := parser.ParseScenarioFileDiagnostics(source)
+
+	ctx := &Context{
+		FilePath: filePath,
+		Scenario: scenario,
+		parser:   parser,
+	}
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity != mjparse.SeverityError {
+			// warnings are covered by rules
+			continue
+		}
+		ctx.findings = append(ctx.findings, &Finding{
+			RuleID:   ParseErrorRuleID,
+			Severity: diagnostic.Severity,
+			FilePath: diagnostic.FilePath,
+			Pos:      diagnostic.Pos,
+			KeyPath:  diagnostic.KeyPath,
+			Message:  diagnostic.Message,
+		})
+	}
+	if scenario == nil {
+		return ctx.findings
+	}
+
+	ctx.Source = scenario.Source
+	ctx.duplicateKeys = findDuplicateKeys(source)
+	for _, rule := range l.Rules {
+		ctx.rule = rule
+		rule.Check(ctx)
+	}
+
+	findings := newSuppressions(source).filter(ctx.findings)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Pos.Offset < findings[j].Pos.Offset
+	})
+	return findings
+}
+
+// Context is what rules get to inspect, and where they report findings.
+type Context struct {
+	FilePath string
+	Scenario *mj.Scenario
+
+	// Source is the parsed JSON tree of the scenario.
+	Source oj.OJsonObject
+
+	parser        mjparse.Parser
+	rule          Rule
+	findings      []*Finding
+	duplicateKeys []duplicateKey
+}
+
+// Report adds a finding for the current rule.
+// The path points to the problematic element in the source, e.g. ("steps", 2, "tx", "to").
+// Path elements are either strings, for map keys, or ints, for list indexes.
+func (ctx *Context) Report(message string, path ...interface{}) {
+	ctx.ReportAt(locate(ctx.Source, path), message, path...)
+}
+
+// ReportAt adds a finding for the current rule, at an explicit position.
+func (ctx *Context) ReportAt(pos oj.Position, message string, path ...interface{}) {
+	ctx.findings = append(ctx.findings, &Finding{
+		RuleID:   ctx.rule.ID(),
+		Severity: ctx.rule.Severity(),
+		FilePath: ctx.FilePath,
+		Pos:      pos,
+		KeyPath:  formatKeyPath(path),
+		Message:  message,
+	})
+}
+
+// Lookup yields the source object at the given path, or nil if there is none.
+func (ctx *Context) Lookup(path ...interface{}) oj.OJsonObject {
+	return lookup(ctx.Source, path)
+}
+
+// LoadExternalSteps parses the file referenced by an externalSteps step.
+func (ctx *Context) LoadExternalSteps(step *mj.ExternalStepsStep) (*mj.Scenario, error) {
+	return mc.ParseExternalSteps(ctx.parser, step)
+}
+
+func containsString(list []string, str string) bool {
+	for _, elem := range list {
+		if elem == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package scenario_lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func lintMessages(t *testing.T, source string) []string {
+	linter := NewLinter()
+	var messages []string
+	for _, finding := range linter.LintSource("test.scen.json", []byte(source)) {
+		messages = append(messages, finding.String())
+	}
+	return messages
+}
+
+func TestLintRules(t *testing.T) {
+	scenario := `{
+    "steps": [
+        {
+            "step": "setState",
+            "accounts": {
+                "address:owner": {
+                    "storage": {
+                        "str:ELRONDdcttoken": "1"
+                    },
+                    "dct": {
+                        "str:TOK-123456": {
+                            "balance": "1"
+                        }
+                    }
+                },
+                "sc:known": {}
+            }
+        },
+        {
+            "step": "scCall",
+            "id": "call",
+            "tx": {
+                "from": "address:owner",
+                "to": "sc:unknown",
+                "function": "f",
+                "gasLimit": "1",
+                "gasPrice": "0"
+            },
+            "expect": {
+                "out": []
+            }
+        },
+        {
+            "step": "scCall",
+            "id": "call",
+            "tx": {
+                "from": "address:owner",
+                "to": "sc:known",
+                "function": "f",
+                "gasLimit": "1",
+                "gasPrice": "0"
+            }
+        },
+        {
+            "step": "checkState",
+            "accounts": {
+                "address:owner": {},
+                "address:owner": {},
+                "0x6f776e65725f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f": {}
+            }
+        }
+    ]
+}`
+	require.Equal(t, []string{
+		`test.scen.json:8:25: warning: [protected-storage-key] steps[0].accounts["address:owner"].storage["str:ELRONDdcttoken"]: storage key str:ELRONDdcttoken has a protected DCT prefix`,
+		`test.scen.json:12:29: warning: [legacy-dct-syntax] steps[0].accounts["address:owner"].dct["str:TOK-123456"].balance: instance field balance given directly, it should be moved to "instances"`,
+		`test.scen.json:24:17: warning: [unknown-sc-address] steps[1].tx.to: scCall to sc:unknown, which is not set in any setState step`,
+		`test.scen.json:29:13: warning: [expect-without-status] steps[1].expect: expect block has no status`,
+		`test.scen.json:35:13: warning: [duplicate-tx-id] steps[2].id: txId "call" already used in steps[1]`,
+		`test.scen.json:48:17: error: [duplicate-check-account] steps[3].accounts["address:owner"]: account address:owner listed twice`,
+		`test.scen.json:49:17: error: [duplicate-check-account] steps[3].accounts["0x6f776e65725f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f"]: account 0x6f776e65725f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f is the same as address:owner`,
+	}, lintMessages(t, scenario))
+}
+
+func TestLintSuppressions(t *testing.T) {
+	scenario := `// scenario-lint:ignore-file expect-without-status
+{
+    "steps": [
+        {
+            "step": "setState",
+            "accounts": {
+                "sc:known": {}
+            }
+        },
+        {
+            "step": "scCall",
+            "tx": {
+                "from": "address:owner",
+                // scenario-lint:ignore unknown-sc-address deployed elsewhere
+                "to": "sc:unknown",
+                "function": "f",
+                "gasLimit": "1",
+                "gasPrice": "0"
+            },
+            "expect": {}
+        },
+        {
+            "step": "scCall",
+            "tx": {
+                "from": "address:owner",
+                "to": "sc:other", // scenario-lint:ignore duplicate-tx-id
+                "function": "f",
+                "gasLimit": "1",
+                "gasPrice": "0"
+            }
+        }
+    ]
+}`
+	require.Equal(t, []string{
+		`test.scen.json:26:17: warning: [unknown-sc-address] steps[2].tx.to: scCall to sc:other, which is not set in any setState step`,
+	}, lintMessages(t, scenario))
+}
+
+func TestLintParseErrors(t *testing.T) {
+	scenario := `{
+    "steps": [
+        {
+            "step": "setState",
+            // scenario-lint:ignore parse-error
+            "unknownField": {}
+        }
+    ]
+}`
+	messages := lintMessages(t, scenario)
+	require.Len(t, messages, 1)
+	require.Contains(t, messages[0], `test.scen.json:6:13: error: [parse-error] steps[0].unknownField:`)
+
+	linter := NewLinter()
+	linter.DisableRules(UnknownScAddressRuleID)
+	for _, rule := range linter.Rules {
+		require.NotEqual(t, UnknownScAddressRuleID, rule.ID())
+	}
+}
+
+func TestLintInvalidTx(t *testing.T) {
+	scenario := `{
+    "steps": [
+        {
+            "step": "setState",
+            "accounts": {
+                "sc:contract": {}
+            }
+        },
+        {
+            "step": "scCall",
+            "id": "1",
+            "tx": 5
+        },
+        {
+            "step": "scCall",
+            "id": "1",
+            "tx": {
+                "from": "address:owner",
+                "to": "sc:other",
+                "function": "f",
+                "gasLimit": "1"
+            },
+            "expect": {}
+        }
+    ]
+}`
+	require.Equal(t, []string{
+		`test.scen.json:12:13: error: [parse-error] steps[1].tx: cannot parse tx step transaction: unmarshalled transaction is not a map`,
+		`test.scen.json:19:17: warning: [unknown-sc-address] steps[2].tx.to: scCall to sc:other, which is not set in any setState step`,
+		`test.scen.json:23:13: warning: [expect-without-status] steps[2].expect: expect block has no status`,
+	}, lintMessages(t, scenario))
+}
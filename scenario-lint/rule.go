@@ -0,0 +1,53 @@
+package scenario_lint
+
+import (
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+)
+
+// Rule is a check performed on a scenario.
+type Rule interface {
+	// ID identifies the rule in reports and in suppression comments.
+	ID() string
+
+	// Severity is the severity of the findings of this rule.
+	Severity() mjparse.Severity
+
+	// Description is a short human-readable explanation of what the rule checks.
+	Description() string
+
+	// Check inspects the scenario and reports findings to the context.
+	Check(ctx *Context)
+}
+
+type funcRule struct {
+	id          string
+	severity    mjparse.Severity
+	description string
+	check       func(ctx *Context)
+}
+
+// NewRule creates a rule from a check function.
+func NewRule(id string, severity mjparse.Severity, description string, check func(ctx *Context)) Rule {
+	return &funcRule{
+		id:          id,
+		severity:    severity,
+		description: description,
+		check:       check,
+	}
+}
+
+func (r *funcRule) ID() string {
+	return r.id
+}
+
+func (r *funcRule) Severity() mjparse.Severity {
+	return r.severity
+}
+
+func (r *funcRule) Description() string {
+	return r.description
+}
+
+func (r *funcRule) Check(ctx *Context) {
+	r.check(ctx)
+}
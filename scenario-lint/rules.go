@@ -0,0 +1,269 @@
+package scenario_lint
+
+import (
+	"fmt"
+
+	"github.com/bhagyaraj1208117/andes-scenario-go/dctconvert"
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+)
+
+// IDs of the default rules.
+const (
+	UnknownScAddressRuleID      = "unknown-sc-address"
+	DuplicateTxIDRuleID         = "duplicate-tx-id"
+	ExpectWithoutStatusRuleID   = "expect-without-status"
+	DuplicateCheckAccountRuleID = "duplicate-check-account"
+	DuplicateKeyRuleID          = "duplicate-key"
+	ProtectedStorageKeyRuleID   = "protected-storage-key"
+	LegacyDCTSyntaxRuleID       = "legacy-dct-syntax"
+)
+
+// DefaultRules yields all the rules provided by this package.
+func DefaultRules() []Rule {
+	return []Rule{
+		NewRule(UnknownScAddressRuleID, mjparse.SeverityWarning,
+			"scCall to an address that is never set in a setState step",
+			checkUnknownScAddress),
+		NewRule(DuplicateTxIDRuleID, mjparse.SeverityWarning,
+			"the same txId used by more than one step",
+			checkDuplicateTxID),
+		NewRule(ExpectWithoutStatusRuleID, mjparse.SeverityWarning,
+			"expect block that does not check the status",
+			checkExpectWithoutStatus),
+		NewRule(DuplicateCheckAccountRuleID, mjparse.SeverityError,
+			"account listed twice in the same checkState step",
+			checkDuplicateCheckAccount),
+		NewRule(DuplicateKeyRuleID, mjparse.SeverityError,
+			"key repeated in a map, only the first occurrence is used",
+			checkDuplicateKey),
+		NewRule(ProtectedStorageKeyRuleID, mjparse.SeverityWarning,
+			"storage key with a protected DCT prefix, the dct field should be used instead",
+			checkProtectedStorageKey),
+		NewRule(LegacyDCTSyntaxRuleID, mjparse.SeverityWarning,
+			"deprecated DCT syntax, only accepted for backwards compatibility",
+			checkLegacyDCTSyntax),
+	}
+}
+
+func checkUnknownScAddress(ctx *Context) {
+	knownAddresses := make(map[string]bool)
+	hasSetState, complete := collectSetStateAddresses(ctx, ctx.Scenario, knownAddresses, 0)
+	if !hasSetState || !complete {
+		// not enough information, e.g. for step files that are included by other scenarios
+		return
+	}
+
+	for stepIndex, step := range ctx.Scenario.Steps {
+		txStep, isTx := parsedTxStep(step)
+		if !isTx || txStep.Tx.Type != mj.ScCall {
+			continue
+		}
+		if !knownAddresses[string(txStep.Tx.To.Value)] {
+			ctx.Report(
+				fmt.Sprintf("scCall to %s, which is not set in any setState step", txStep.Tx.To.Original),
+				"steps", stepIndex, "tx", "to")
+		}
+	}
+}
+
+// parsedTxStep yields the step if it is a transaction step whose tx could be parsed.
+// Scenarios with parse errors are also linted, their invalid transactions are left nil.
+func parsedTxStep(step mj.Step) (*mj.TxStep, bool) {
+	txStep, isTx := step.(*mj.TxStep)
+	if !isTx || txStep.Tx == nil {
+		return nil, false
+	}
+	return txStep, true
+}
+
+// maxExternalStepsDepth protects against cyclic externalSteps.
+const maxExternalStepsDepth = 10
+
+// collectSetStateAddresses gathers the addresses of accounts created in setState steps, including in external steps.
+// Also indicates whether there were any setState steps, and whether all external steps could be loaded.
+func collectSetStateAddresses(ctx *Context, scenario *mj.Scenario, addresses map[string]bool, depth int) (bool, bool) {
+	hasSetState := false
+	complete := true
+	for _, step := range scenario.Steps {
+		switch typedStep := step.(type) {
+		case *mj.SetStateStep:
+			hasSetState = true
+			for _, account := range typedStep.Accounts {
+				addresses[string(account.Address.Value)] = true
+			}
+			for _, newAddressMock := range typedStep.NewAddressMocks {
+				addresses[string(newAddressMock.NewAddress.Value)] = true
+			}
+		case *mj.ExternalStepsStep:
+			if depth >= maxExternalStepsDepth {
+				complete = false
+				continue
+			}
+			externalScenario, err := ctx.LoadExternalSteps(typedStep)
+			if err != nil {
+				complete = false
+				continue
+			}
+			externalHasSetState, externalComplete := collectSetStateAddresses(ctx, externalScenario, addresses, depth+1)
+			hasSetState = hasSetState || externalHasSetState
+			complete = complete && externalComplete
+		}
+	}
+	return hasSetState, complete
+}
+
+func checkDuplicateTxID(ctx *Context) {
+	firstUse := make(map[string]int)
+	for stepIndex, step := range ctx.Scenario.Steps {
+		txStep, isTx := parsedTxStep(step)
+		if !isTx || len(txStep.TxIdent) == 0 {
+			continue
+		}
+		if firstIndex, found := firstUse[txStep.TxIdent]; found {
+			idKey := "id"
+			if ctx.Lookup("steps", stepIndex, "txId") != nil {
+				idKey = "txId"
+			}
+			ctx.Report(
+				fmt.Sprintf("txId %q already used in steps[%d]", txStep.TxIdent, firstIndex),
+				"steps", stepIndex, idKey)
+			continue
+		}
+		firstUse[txStep.TxIdent] = stepIndex
+	}
+}
+
+func checkExpectWithoutStatus(ctx *Context) {
+	for stepIndex, step := range ctx.Scenario.Steps {
+		txStep, isTx := parsedTxStep(step)
+		if !isTx || txStep.ExpectedResult == nil {
+			continue
+		}
+		if txStep.ExpectedResult.Status.IsUnspecified() {
+			ctx.Report("expect block has no status", "steps", stepIndex, "expect")
+		}
+	}
+}
+
+func checkDuplicateCheckAccount(ctx *Context) {
+	for stepIndex, step := range ctx.Scenario.Steps {
+		checkStep, isCheck := step.(*mj.CheckStateStep)
+		if !isCheck || checkStep.CheckAccounts == nil {
+			continue
+		}
+
+		// the same account written in different ways
+		firstOriginal := make(map[string]string)
+		for _, account := range checkStep.CheckAccounts.Accounts {
+			address := string(account.Address.Value)
+			if original, found := firstOriginal[address]; found {
+				ctx.Report(
+					fmt.Sprintf("account %s is the same as %s", account.Address.Original, original),
+					"steps", stepIndex, "accounts", account.Address.Original)
+				continue
+			}
+			firstOriginal[address] = account.Address.Original
+		}
+
+		// the same account written identically, which the parser silently ignores
+		for _, duplicate := range ctx.duplicateKeys {
+			if isCheckStateAccountPath(duplicate.path, stepIndex) {
+				ctx.ReportAt(duplicate.pos,
+					fmt.Sprintf("account %s listed twice", duplicate.path[3]),
+					duplicate.path...)
+			}
+		}
+	}
+}
+
+func isCheckStateAccountPath(path []interface{}, stepIndex int) bool {
+	return len(path) == 4 && path[0] == "steps" && path[1] == stepIndex && path[2] == "accounts"
+}
+
+func checkDuplicateKey(ctx *Context) {
+	for _, duplicate := range ctx.duplicateKeys {
+		if len(duplicate.path) == 4 && duplicate.path[0] == "steps" && duplicate.path[2] == "accounts" {
+			stepIndex, _ := duplicate.path[1].(int)
+			if stepIndex < len(ctx.Scenario.Steps) {
+				if _, isCheck := ctx.Scenario.Steps[stepIndex].(*mj.CheckStateStep); isCheck {
+					// reported by the more specific rule
+					continue
+				}
+			}
+		}
+		ctx.ReportAt(duplicate.pos,
+			fmt.Sprintf("duplicate key %q, only the first occurrence is used", duplicate.path[len(duplicate.path)-1]),
+			duplicate.path...)
+	}
+}
+
+func checkProtectedStorageKey(ctx *Context) {
+	for stepIndex, step := range ctx.Scenario.Steps {
+		setStateStep, isSetState := step.(*mj.SetStateStep)
+		if !isSetState {
+			continue
+		}
+		for _, account := range setStateStep.Accounts {
+			for _, storageEntry := range account.Storage {
+				if dctconvert.IsDCTKey(storageEntry.Key.Value) {
+					ctx.Report(
+						fmt.Sprintf("storage key %s has a protected DCT prefix", storageEntry.Key.Original),
+						"steps", stepIndex, "accounts", account.Address.Original, "storage", storageEntry.Key.Original)
+				}
+			}
+		}
+	}
+}
+
+// dctInstanceFields can be given directly in the DCT entry, in the legacy syntax.
+var dctInstanceFields = []string{"nonce", "balance", "creator", "royalties", "hash", "uri", "attributes"}
+
+func checkLegacyDCTSyntax(ctx *Context) {
+	for stepIndex, step := range ctx.Scenario.Steps {
+		switch typedStep := step.(type) {
+		case *mj.SetStateStep:
+			for _, account := range typedStep.Accounts {
+				checkLegacyDCTEntries(ctx, "steps", stepIndex, "accounts", account.Address.Original, "dct")
+			}
+		case *mj.CheckStateStep:
+			if typedStep.CheckAccounts == nil {
+				continue
+			}
+			for _, account := range typedStep.CheckAccounts.Accounts {
+				checkLegacyDCTEntries(ctx, "steps", stepIndex, "accounts", account.Address.Original, "dct")
+			}
+		case *mj.TxStep:
+			if typedStep.Tx == nil {
+				continue
+			}
+			if _, isMap := ctx.Lookup("steps", stepIndex, "tx", "dctValue").(*oj.OJsonMap); isMap {
+				ctx.Report("single DCT transfer given as map, a list of transfers is expected",
+					"steps", stepIndex, "tx", "dctValue")
+			}
+		}
+	}
+}
+
+func checkLegacyDCTEntries(ctx *Context, dctPath ...interface{}) {
+	dctMap, isMap := ctx.Lookup(dctPath...).(*oj.OJsonMap)
+	if !isMap {
+		return
+	}
+	for _, tokenKvp := range dctMap.OrderedKV {
+		tokenMap, isTokenMap := tokenKvp.Value.(*oj.OJsonMap)
+		if !isTokenMap {
+			continue
+		}
+		for _, fieldKvp := range tokenMap.OrderedKV {
+			if containsString(dctInstanceFields, fieldKvp.Key) {
+				path := append(append([]interface{}{}, dctPath...), tokenKvp.Key, fieldKvp.Key)
+				ctx.Report(
+					fmt.Sprintf("instance field %s given directly, it should be moved to \"instances\"", fieldKvp.Key),
+					path...)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package scenario_lint
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	oj "github.com/bhagyaraj1208117/andes-scenario-go/orderedjson"
+)
+
+// lookup walks the source tree along a path of map keys and list indexes.
+func lookup(obj oj.OJsonObject, path []interface{}) oj.OJsonObject {
+	for _, elem := range path {
+		switch key := elem.(type) {
+		case string:
+			m, isMap := obj.(*oj.OJsonMap)
+			if !isMap {
+				return nil
+			}
+			obj = nil
+			for _, kvp := range m.OrderedKV {
+				if kvp.Key == key {
+					obj = kvp.Value
+					break
+				}
+			}
+		case int:
+			list, isList := obj.(*oj.OJsonList)
+			if !isList || key < 0 || key >= len(list.Items) {
+				return nil
+			}
+			obj = list.Items[key]
+		default:
+			return nil
+		}
+		if obj == nil {
+			return nil
+		}
+	}
+	return obj
+}
+
+// locate yields the source position of the element at the given path.
+// Map entries are located at their key. If the path cannot be followed entirely, the closest parent is used.
+func locate(obj oj.OJsonObject, path []interface{}) oj.Position {
+	var pos oj.Position
+	if obj != nil {
+		pos = obj.SourceSpan().Start
+	}
+	for _, elem := range path {
+		switch key := elem.(type) {
+		case string:
+			m, isMap := obj.(*oj.OJsonMap)
+			if !isMap {
+				return pos
+			}
+			var found *oj.OJsonKeyValuePair
+			for _, kvp := range m.OrderedKV {
+				if kvp.Key == key {
+					found = kvp
+					break
+				}
+			}
+			if found == nil {
+				return pos
+			}
+			obj = found.Value
+			pos = found.KeySpan.Start
+		case int:
+			list, isList := obj.(*oj.OJsonList)
+			if !isList || key < 0 || key >= len(list.Items) {
+				return pos
+			}
+			obj = list.Items[key]
+			pos = obj.SourceSpan().Start
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+var identifierKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// formatKeyPath formats a path the same way the parser does, e.g. `steps[12].expect.out[0]`.
+func formatKeyPath(path []interface{}) string {
+	var sb strings.Builder
+	for _, elem := range path {
+		switch key := elem.(type) {
+		case int:
+			sb.WriteString(fmt.Sprintf("[%d]", key))
+		case string:
+			if identifierKeyRegex.MatchString(key) {
+				if sb.Len() > 0 {
+					sb.WriteString(".")
+				}
+				sb.WriteString(key)
+			} else {
+				sb.WriteString(fmt.Sprintf("[%q]", key))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// duplicateKey is a map key that occurs more than once.
+// The parser only keeps the first occurrence, so these are invisible in the parsed tree.
+type duplicateKey struct {
+	path []interface{}
+	pos  oj.Position
+}
+
+func findDuplicateKeys(source []byte) []duplicateKey {
+	var result []duplicateKey
+	decoder := oj.NewJSONCDecoder(bytes.NewReader(source))
+	// the source has already been parsed successfully, errors cannot occur here
+	_ = walkDuplicateKeys(decoder, nil, &result)
+	return result
+}
+
+func walkDuplicateKeys(decoder *oj.Decoder, path []interface{}, result *[]duplicateKey) error {
+	isMap, err := decoder.IsNextMap()
+	if err != nil {
+		return err
+	}
+	if isMap {
+		seen := make(map[string]bool)
+		_, err = decoder.DecodeMapStream(func(key string, keySpan oj.Span) error {
+			childPath := append(append([]interface{}{}, path...), key)
+			if seen[key] {
+				*result = append(*result, duplicateKey{path: childPath, pos: keySpan.Start})
+			}
+			seen[key] = true
+			return walkDuplicateKeys(decoder, childPath, result)
+		})
+		return err
+	}
+
+	isList, err := decoder.IsNextList()
+	if err != nil {
+		return err
+	}
+	if isList {
+		_, err = decoder.DecodeListStream(func(index int) error {
+			childPath := append(append([]interface{}{}, path...), index)
+			return walkDuplicateKeys(decoder, childPath, result)
+		})
+		return err
+	}
+
+	_, err = decoder.Decode()
+	return err
+}
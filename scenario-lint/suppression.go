@@ -0,0 +1,63 @@
+package scenario_lint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Findings can be suppressed with a comment on the same line, or on the line before:
+//
+//	// scenario-lint:ignore rule-id[,other-rule-id] optional reason
+//
+// or for the whole file:
+//
+//	// scenario-lint:ignore-file rule-id[,other-rule-id] optional reason
+var suppressionRegex = regexp.MustCompile(`scenario-lint:(ignore|ignore-file)\s+([\w-]+(?:\s*,\s*[\w-]+)*)`)
+
+type suppressions struct {
+	// byLine maps line numbers to suppressed rule IDs
+	byLine map[int][]string
+	inFile []string
+}
+
+func newSuppressions(source []byte) *suppressions {
+	result := &suppressions{
+		byLine: make(map[int][]string),
+	}
+	for i, line := range strings.Split(string(source), "\n") {
+		for _, match := range suppressionRegex.FindAllStringSubmatch(line, -1) {
+			var ruleIDs []string
+			for _, ruleID := range strings.Split(match[2], ",") {
+				ruleIDs = append(ruleIDs, strings.TrimSpace(ruleID))
+			}
+			if match[1] == "ignore-file" {
+				result.inFile = append(result.inFile, ruleIDs...)
+			} else {
+				result.byLine[i+1] = append(result.byLine[i+1], ruleIDs...)
+			}
+		}
+	}
+	return result
+}
+
+func (s *suppressions) isSuppressed(finding *Finding) bool {
+	if finding.RuleID == ParseErrorRuleID {
+		return false
+	}
+	if containsString(s.inFile, finding.RuleID) {
+		return true
+	}
+	line := finding.Pos.Line
+	return containsString(s.byLine[line], finding.RuleID) ||
+		containsString(s.byLine[line-1], finding.RuleID)
+}
+
+func (s *suppressions) filter(findings []*Finding) []*Finding {
+	var result []*Finding
+	for _, finding := range findings {
+		if !s.isSuppressed(finding) {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+package scenario_lsp
+
+import (
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+)
+
+func (s *Server) completion(doc *document, offset int) *completionList {
+	ctx := doc.contextAt(offset)
+	var items []completionItem
+	if ctx.inKey {
+		items = doc.keyCompletions(ctx)
+	} else {
+		items = doc.valueCompletions(ctx, ctx.spec)
+	}
+	for i := range items {
+		items[i].TextEdit = doc.completionEdit(ctx, &items[i], offset)
+	}
+	return &completionList{Items: append([]completionItem{}, items...)}
+}
+
+// completionEdit replaces the contents of the string under the cursor, or inserts a new string.
+func (doc *document) completionEdit(ctx *cursorContext, item *completionItem, offset int) *textEdit {
+	if ctx.str != nil {
+		end := ctx.str.end
+		if !ctx.str.unterminated {
+			end--
+		}
+		return &textEdit{Range: doc.rangeOf(ctx.str.start+1, end), NewText: item.Label}
+	}
+	cursor := doc.rangeOf(offset, offset)
+	newText := `"` + item.Label + `"`
+	switch {
+	case ctx.inKey:
+		newText += ": "
+	case item.Kind == completionKindKeyword:
+		newText = item.Label
+	}
+	return &textEdit{Range: cursor, NewText: newText}
+}
+
+func (doc *document) keyCompletions(ctx *cursorContext) []completionItem {
+	spec := ctx.objectSpec
+	if spec == nil {
+		return nil
+	}
+	var items []completionItem
+	switch spec.Kind {
+	case mjparse.ValueObject:
+		items = doc.fieldCompletions(ctx, []*mjparse.ObjectSpec{spec.Object})
+	case mjparse.ValueAnyOf:
+		var objects []*mjparse.ObjectSpec
+		for _, alternative := range spec.Alternatives {
+			if alternative.Kind == mjparse.ValueObject {
+				objects = append(objects, alternative.Object)
+			}
+		}
+		items = doc.fieldCompletions(ctx, objects)
+	case mjparse.ValueMap:
+		if containsString(accountMapFields, ctx.objectKey) {
+			items = append(items, doc.addressCompletions()...)
+		}
+		if spec.MoreAllowed {
+			items = append(items, completionItem{Label: "+", Kind: completionKindValue, Detail: "more entries allowed"})
+		}
+	}
+	return items
+}
+
+// fieldCompletions offers the fields of the objects that are not present yet.
+func (doc *document) fieldCompletions(ctx *cursorContext, objects []*mjparse.ObjectSpec) []completionItem {
+	var items []completionItem
+	var offered []string
+	for _, object := range objects {
+		for _, field := range object.Fields {
+			if containsString(offered, field.Name) {
+				continue
+			}
+			if hasKey(ctx.object, field.Name, ctx.str) {
+				continue
+			}
+			offered = append(offered, field.Name)
+			item := completionItem{
+				Label:         field.Name,
+				Kind:          completionKindProperty,
+				Detail:        object.Name,
+				Documentation: field.Description,
+			}
+			if field.Deprecated {
+				item.Tags = []int{completionTagDeprecated}
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// hasKey checks if the object has the key, not counting the key being edited.
+func hasKey(object *node, key string, editing *node) bool {
+	for _, e := range object.entries {
+		if e.key.value == key && e.key != editing {
+			return true
+		}
+	}
+	return false
+}
+
+func (doc *document) valueCompletions(ctx *cursorContext, spec *mjparse.ValueSpec) []completionItem {
+	if spec == nil {
+		return nil
+	}
+	var items []completionItem
+	if spec.Star {
+		items = append(items, completionItem{Label: "*", Kind: completionKindValue, Detail: "not checked"})
+	}
+	switch spec.Kind {
+	case mjparse.ValueEnum:
+		for _, value := range spec.Enum {
+			items = append(items, completionItem{Label: value, Kind: completionKindEnumMember})
+		}
+	case mjparse.ValueBool:
+		if ctx.str == nil {
+			items = append(items,
+				completionItem{Label: "true", Kind: completionKindKeyword},
+				completionItem{Label: "false", Kind: completionKindKeyword})
+		}
+	case mjparse.ValueExpression, mjparse.ValueExpressionTree:
+		if containsString(addressFields, ctx.key) {
+			items = append(items, doc.addressCompletions()...)
+		}
+		for _, prefix := range ei.Prefixes() {
+			items = append(items, completionItem{Label: prefix, Kind: completionKindValue, Detail: "value expression prefix"})
+		}
+	case mjparse.ValueAnyOf:
+		for _, alternative := range spec.Alternatives {
+			for _, item := range doc.valueCompletions(ctx, alternative) {
+				if !containsItem(items, item.Label) {
+					items = append(items, item)
+				}
+			}
+		}
+	}
+	return items
+}
+
+func containsItem(items []completionItem, label string) bool {
+	for _, item := range items {
+		if item.Label == label {
+			return true
+		}
+	}
+	return false
+}
+
+func (doc *document) addressCompletions() []completionItem {
+	var items []completionItem
+	for _, address := range doc.declaredAddresses() {
+		items = append(items, completionItem{Label: address, Kind: completionKindValue, Detail: "declared in setState"})
+	}
+	return items
+}
+
+// declaredAddresses lists the accounts and new contract addresses of all setState steps in the document.
+func (doc *document) declaredAddresses() []string {
+	if doc.outline == nil || doc.outline.kind != nodeObject {
+		return nil
+	}
+	steps := doc.outline.field("steps")
+	if steps == nil {
+		return nil
+	}
+	var addresses []string
+	for _, step := range steps.items {
+		if stepName, _ := step.field("step").stringValue(); stepName != mj.StepNameSetState {
+			continue
+		}
+		if accounts := step.field("accounts"); accounts != nil {
+			for _, e := range accounts.entries {
+				addresses = appendMissing(addresses, e.key.value)
+			}
+		}
+		if newAddresses := step.field("newAddresses"); newAddresses != nil {
+			for _, newAddress := range newAddresses.items {
+				if address, isString := newAddress.field("newAddress").stringValue(); isString {
+					addresses = appendMissing(addresses, address)
+				}
+			}
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,244 @@
+package scenario_lsp
+
+import (
+	"strings"
+
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+)
+
+// cursorContext describes what a cursor in the document points at.
+type cursorContext struct {
+	// inKey is true when the cursor is in key position in object, false when in value position
+	inKey bool
+
+	// object is the map enclosing the cursor, when in key position, or the map holding the value
+	object *node
+
+	// objectSpec describes the object, resolved to a single alternative, if possible
+	objectSpec *mjparse.ValueSpec
+
+	// objectKey is the key under which the object is found in its parent, if any
+	objectKey string
+
+	// key is the key of the entry whose value is under the cursor, or the key being edited
+	key string
+
+	// spec describes the value at the cursor, nil if unknown
+	spec *mjparse.ValueSpec
+
+	// str is the string under the cursor, if any
+	str *node
+}
+
+// rootSpec describes the top level value of a file.
+func rootSpec(path string) *mjparse.ValueSpec {
+	if strings.HasSuffix(path, ".test.json") {
+		return mjparse.TestFileSpec()
+	}
+	return &mjparse.ValueSpec{Kind: mjparse.ValueObject, Object: mjparse.ScenarioSpec()}
+}
+
+func (doc *document) contextAt(offset int) *cursorContext {
+	ctx := &cursorContext{}
+	if doc.outline == nil {
+		ctx.spec = rootSpec(doc.path)
+		return ctx
+	}
+	ctx.descend(doc.outline, rootSpec(doc.path), offset)
+	return ctx
+}
+
+func (ctx *cursorContext) descend(n *node, spec *mjparse.ValueSpec, offset int) {
+	if resolved := selectAlternative(spec, n); resolved != nil || n.kind != nodeObject {
+		// objects that match no alternative, e.g. steps without a type yet, keep all of them
+		spec = resolved
+	}
+	switch n.kind {
+	case nodeObject:
+		ctx.object = n
+		ctx.objectSpec = spec
+		ctx.objectKey = ctx.key
+		ctx.key = ""
+		ctx.spec = nil
+		for _, e := range n.entries {
+			if e.key.contains(offset) {
+				ctx.inKey = true
+				ctx.key = e.key.value
+				ctx.str = e.key
+				ctx.spec = keySpec(spec)
+				return
+			}
+			if e.value != nil && e.value.contains(offset) {
+				ctx.key = e.key.value
+				ctx.descend(e.value, entrySpec(spec, e.key.value), offset)
+				return
+			}
+			if e.colon >= 0 && e.colon < offset && (e.value == nil || offset <= e.value.start) {
+				ctx.key = e.key.value
+				ctx.spec = entrySpec(spec, e.key.value)
+				return
+			}
+		}
+		ctx.inKey = true
+		ctx.spec = keySpec(spec)
+	case nodeArray:
+		var itemSpec *mjparse.ValueSpec
+		if spec != nil {
+			switch spec.Kind {
+			case mjparse.ValueList:
+				itemSpec = spec.Items
+			case mjparse.ValueExpressionTree:
+				itemSpec = spec
+			}
+		}
+		for _, item := range n.items {
+			if item.contains(offset) {
+				ctx.descend(item, itemSpec, offset)
+				return
+			}
+		}
+		ctx.spec = itemSpec
+	case nodeString:
+		ctx.str = n
+		ctx.spec = spec
+	default:
+		ctx.spec = spec
+	}
+}
+
+// keySpec describes the keys of a map.
+func keySpec(spec *mjparse.ValueSpec) *mjparse.ValueSpec {
+	if spec != nil && spec.Kind == mjparse.ValueMap {
+		return spec.Keys
+	}
+	return nil
+}
+
+// entrySpec describes the value of a map entry.
+func entrySpec(spec *mjparse.ValueSpec, key string) *mjparse.ValueSpec {
+	if spec == nil {
+		return nil
+	}
+	switch spec.Kind {
+	case mjparse.ValueObject:
+		if field := spec.Object.Field(key); field != nil {
+			return field.Value
+		}
+	case mjparse.ValueMap:
+		if key != "+" {
+			return spec.Items
+		}
+	case mjparse.ValueExpressionTree:
+		return spec
+	case mjparse.ValueAnyOf:
+		return alternativesEntrySpec(spec, key)
+	}
+	return nil
+}
+
+// alternativesEntrySpec describes a field of an object that could match any of the alternatives.
+// Enum fields, like the step type, accept the values of all alternatives.
+func alternativesEntrySpec(spec *mjparse.ValueSpec, key string) *mjparse.ValueSpec {
+	var result *mjparse.ValueSpec
+	for _, alternative := range spec.Alternatives {
+		if alternative.Kind != mjparse.ValueObject {
+			continue
+		}
+		field := alternative.Object.Field(key)
+		switch {
+		case field == nil:
+		case result == nil:
+			result = field.Value
+		case result.Kind == mjparse.ValueEnum && field.Value.Kind == mjparse.ValueEnum:
+			result = &mjparse.ValueSpec{
+				Kind: mjparse.ValueEnum,
+				Enum: appendMissing(result.Enum, field.Value.Enum...),
+			}
+		}
+	}
+	return result
+}
+
+func appendMissing(list []string, values ...string) []string {
+	result := append([]string{}, list...)
+	for _, value := range values {
+		if !containsString(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func containsString(list []string, str string) bool {
+	for _, elem := range list {
+		if elem == str {
+			return true
+		}
+	}
+	return false
+}
+
+// selectAlternative picks the alternative that describes the node.
+// Objects with a "step" field are matched against the step type.
+// It yields the spec unchanged if it has no alternatives, and nil if none matches.
+func selectAlternative(spec *mjparse.ValueSpec, n *node) *mjparse.ValueSpec {
+	if spec == nil || spec.Kind != mjparse.ValueAnyOf {
+		return spec
+	}
+	for _, alternative := range spec.Alternatives {
+		if alternativeMatches(alternative, n) {
+			return alternative
+		}
+	}
+	return nil
+}
+
+func alternativeMatches(spec *mjparse.ValueSpec, n *node) bool {
+	switch n.kind {
+	case nodeObject:
+		switch spec.Kind {
+		case mjparse.ValueObject:
+			stepField := spec.Object.Field("step")
+			if stepField == nil {
+				return true
+			}
+			stepName, _ := n.field("step").stringValue()
+			return stepField.Value.Enum[0] == stepName
+		case mjparse.ValueMap, mjparse.ValueExpressionTree:
+			return true
+		}
+	case nodeArray:
+		return spec.Kind == mjparse.ValueList || spec.Kind == mjparse.ValueExpressionTree
+	case nodeString:
+		return isStringKind(spec.Kind) || (spec.Star && n.value == "*")
+	case nodeLiteral:
+		return spec.Kind == mjparse.ValueBool
+	}
+	return false
+}
+
+func isStringKind(kind mjparse.ValueKind) bool {
+	switch kind {
+	case mjparse.ValueString, mjparse.ValueEnum, mjparse.ValueExpression, mjparse.ValueExpressionTree:
+		return true
+	}
+	return false
+}
+
+// isExpression checks whether strings in the position described by the spec are value expressions.
+func isExpression(spec *mjparse.ValueSpec) bool {
+	if spec == nil {
+		return false
+	}
+	switch spec.Kind {
+	case mjparse.ValueExpression, mjparse.ValueExpressionTree:
+		return true
+	case mjparse.ValueAnyOf:
+		for _, alternative := range spec.Alternatives {
+			if isExpression(alternative) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package scenario_lsp
+
+import (
+	"os"
+	"strings"
+)
+
+// fileReferencePrefixes introduce expressions that load files.
+var fileReferencePrefixes = []string{"file:", "mxsc:"}
+
+// definition locates the file referenced by externalSteps paths, transaction ABIs and file:/mxsc: expressions.
+func (s *Server) definition(doc *document, offset int) *lspLocation {
+	ctx := doc.contextAt(offset + 1)
+	if ctx.str == nil || ctx.inKey {
+		return nil
+	}
+
+	var reference string
+	if isPathField(ctx, "externalStepsStep", "path") || isPathField(ctx, "transaction", "abi") {
+		reference = ctx.str.value
+	} else {
+		for _, prefix := range fileReferencePrefixes {
+			if strings.HasPrefix(ctx.str.value, prefix) {
+				reference = ctx.str.value[len(prefix):]
+			}
+		}
+	}
+	if len(reference) == 0 {
+		return nil
+	}
+
+	path := s.resolverFor(doc).ResolveAbsolutePath(reference)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	return &lspLocation{URI: pathToURI(path)}
+}
+
+func isPathField(ctx *cursorContext, objectName string, key string) bool {
+	return ctx.key == key && ctx.objectSpec != nil && ctx.objectSpec.Object != nil &&
+		ctx.objectSpec.Object.Name == objectName
+}
@@ -0,0 +1,92 @@
+package scenario_lsp
+
+import (
+	"fmt"
+	"strings"
+
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+)
+
+const diagnosticSource = "scenario"
+
+// hasDiagnostics checks if the file is parsed as a scenario.
+func hasDiagnostics(path string) bool {
+	return strings.HasSuffix(path, ".scen.json") || strings.HasSuffix(path, ".steps.json")
+}
+
+// diagnostics yields the errors and warnings of the scenario parser.
+func (s *Server) diagnostics(doc *document) []lspDiagnostic {
+	result := []lspDiagnostic{}
+	if !hasDiagnostics(doc.path) {
+		return result
+	}
+
+	parser := mjparse.NewParser(s.resolverFor(doc))
+	parser.ExprInterpreter = s.newInterpreter(doc)
+	parser.AllowJSONC = true
+	parser.SourcePath = doc.path
+	var scenario *mj.Scenario
+	parseDiagnostics := recoverDiagnostics(func() []*mjparse.Diagnostic {
+		var parseDiagnostics []*mjparse.Diagnostic
+		scenario, parseDiagnostics = parser.ParseScenarioFileDiagnostics([]byte(doc.text))
+		return parseDiagnostics
+	})
+
+	// the parser drops the scenario's constants when done, hover needs them back in scope
+	interpreter := parser.ExprInterpreter
+	if scenario != nil && len(scenario.Constants) > 0 {
+		interpreter.Constants = make(map[string]string, len(scenario.Constants))
+		for _, constant := range scenario.Constants {
+			interpreter.Constants[constant.Name] = constant.Value
+		}
+	}
+	doc.interpreter = &interpreter
+
+	tokens := tokenize(doc.text)
+	for _, diagnostic := range parseDiagnostics {
+		severity := diagnosticSeverityError
+		if diagnostic.Severity == mjparse.SeverityWarning {
+			severity = diagnosticSeverityWarning
+		}
+		message := diagnostic.Message
+		if len(diagnostic.KeyPath) > 0 {
+			message = diagnostic.KeyPath + ": " + message
+		}
+		result = append(result, lspDiagnostic{
+			Range:    doc.diagnosticRange(tokens, diagnostic),
+			Severity: severity,
+			Source:   diagnosticSource,
+			Message:  message,
+		})
+	}
+	return result
+}
+
+// recoverDiagnostics turns a panic of the parser into a diagnostic, so that a parser bug does not stop the server.
+func recoverDiagnostics(parse func() []*mjparse.Diagnostic) (diagnostics []*mjparse.Diagnostic) {
+	defer func() {
+		if r := recover(); r != nil {
+			diagnostics = []*mjparse.Diagnostic{{
+				Severity: mjparse.SeverityError,
+				Message:  fmt.Sprintf("internal parser error: %v", r),
+			}}
+		}
+	}()
+	return parse()
+}
+
+// diagnosticRange covers the token where the problem was found, or the start of the document if unknown.
+func (doc *document) diagnosticRange(tokens []*token, diagnostic *mjparse.Diagnostic) lspRange {
+	if !diagnostic.Pos.IsValid() {
+		return doc.rangeOf(0, 0)
+	}
+	start := diagnostic.Pos.Offset
+	end := start
+	if tok := tokenAt(tokens, start); tok != nil {
+		end = tok.end
+	} else if start < len(doc.text) {
+		end = start + 1
+	}
+	return doc.rangeOf(start, end)
+}
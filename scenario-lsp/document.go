@@ -0,0 +1,98 @@
+package scenario_lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"sort"
+	"unicode/utf8"
+
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+)
+
+// document is an open text document, as last sent by the client.
+type document struct {
+	uri        string
+	path       string
+	text       string
+	lineStarts []int
+	outline    *node
+
+	// interpreter is the one used when parsing the scenario, with its constants in scope
+	interpreter *ei.ExprInterpreter
+}
+
+func newDocument(uri string, text string) *document {
+	doc := &document{
+		uri:        uri,
+		path:       uriToPath(uri),
+		text:       text,
+		lineStarts: []int{0},
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			doc.lineStarts = append(doc.lineStarts, i+1)
+		}
+	}
+	doc.outline = parseOutline(text)
+	return doc
+}
+
+// offsetAt converts an LSP position, whose character is counted in UTF-16 code units, to a byte offset.
+func (doc *document) offsetAt(pos lspPosition) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(doc.lineStarts) {
+		return len(doc.text)
+	}
+	offset := doc.lineStarts[pos.Line]
+	units := 0
+	for offset < len(doc.text) && doc.text[offset] != '\n' && units < pos.Character {
+		r, size := utf8.DecodeRuneInString(doc.text[offset:])
+		units += utf16Len(r)
+		offset += size
+	}
+	return offset
+}
+
+// positionAt converts a byte offset to an LSP position.
+func (doc *document) positionAt(offset int) lspPosition {
+	if offset > len(doc.text) {
+		offset = len(doc.text)
+	}
+	line := sort.Search(len(doc.lineStarts), func(i int) bool {
+		return doc.lineStarts[i] > offset
+	}) - 1
+	character := 0
+	for _, r := range doc.text[doc.lineStarts[line]:offset] {
+		character += utf16Len(r)
+	}
+	return lspPosition{Line: line, Character: character}
+}
+
+func (doc *document) rangeOf(start int, end int) lspRange {
+	return lspRange{Start: doc.positionAt(start), End: doc.positionAt(end)}
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+func uriToPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(parsed.Path)
+}
+
+func pathToURI(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err == nil {
+		path = absPath
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,141 @@
+package scenario_lsp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	er "github.com/bhagyaraj1208117/andes-scenario-go/expression/reconstructor"
+)
+
+const maxBytesShown = 64
+
+// addressFields hold addresses, so their values are reconstructed as such.
+var addressFields = []string{"from", "to", "creatorAddress", "newAddress", "owner", "address", "creator"}
+
+// accountMapFields hold maps keyed by account address.
+var accountMapFields = []string{"accounts", "pre", "postState"}
+
+// codeFields hold smart contract code.
+var codeFields = []string{"code", "contractCode"}
+
+func (s *Server) hover(doc *document, offset int) *hoverResult {
+	// the hovered character is the one following the offset
+	ctx := doc.contextAt(offset + 1)
+	if ctx.str == nil {
+		return nil
+	}
+
+	var contents string
+	switch {
+	case ctx.inKey && ctx.objectSpec != nil && ctx.objectSpec.Object != nil:
+		contents = fieldHover(ctx)
+	case isExpression(ctx.spec) && ctx.str.value != "*" && ctx.str.value != "+":
+		contents = s.expressionHover(doc, ctx)
+	}
+	if len(contents) == 0 {
+		return nil
+	}
+
+	strRange := doc.rangeOf(ctx.str.start, ctx.str.end)
+	return &hoverResult{
+		Contents: markupContent{Kind: "markdown", Value: contents},
+		Range:    &strRange,
+	}
+}
+
+func fieldHover(ctx *cursorContext) string {
+	field := ctx.objectSpec.Object.Field(ctx.str.value)
+	if field == nil {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**%s** (%s)", field.Name, ctx.objectSpec.Object.Name)
+	if field.Deprecated {
+		sb.WriteString("\n\n*Deprecated.*")
+	}
+	if len(field.Description) > 0 {
+		sb.WriteString("\n\n")
+		sb.WriteString(field.Description)
+	}
+	return sb.String()
+}
+
+func (s *Server) expressionHover(doc *document, ctx *cursorContext) string {
+	value, err := s.interpreterFor(doc).InterpretString(ctx.str.value)
+	if err != nil {
+		return fmt.Sprintf("`%s`\n\ncannot interpret: %s", ctx.str.value, err)
+	}
+
+	reconstructor := er.ExprReconstructor{}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "`%s`\n\n", ctx.str.value)
+	fmt.Fprintf(&sb, "- bytes (%d): `%s`\n", len(value), formatBytes(value))
+	fmt.Fprintf(&sb, "- hex: `0x%s`\n", truncatedHex(value))
+	hint := reconstructHint(ctx)
+	if hint == er.NoHint {
+		hint = expressionHint(ctx.str.value)
+	}
+	fmt.Fprintf(&sb, "- reconstructed: `%s`", truncated(reconstructor.Reconstruct(value, hint)))
+	return sb.String()
+}
+
+// reconstructHint guesses the kind of value from the field holding it.
+func reconstructHint(ctx *cursorContext) er.ExprReconstructorHint {
+	field := ctx.key
+	if ctx.inKey {
+		field = ctx.objectKey
+		if containsString(accountMapFields, field) {
+			return er.AddressHint
+		}
+		return er.NoHint
+	}
+	switch {
+	case containsString(addressFields, field):
+		return er.AddressHint
+	case containsString(codeFields, field):
+		return er.CodeHint
+	}
+	return er.NoHint
+}
+
+// expressionHint guesses the kind of value from the way it is written.
+func expressionHint(expression string) er.ExprReconstructorHint {
+	switch {
+	case len(expression) > 0 && expression[0] >= '0' && expression[0] <= '9' && !strings.HasPrefix(expression, "0x") && !strings.Contains(expression, "|"):
+		return er.NumberHint
+	case strings.HasPrefix(expression, "str:"):
+		return er.StrHint
+	}
+	return er.NoHint
+}
+
+func formatBytes(value []byte) string {
+	shown := value
+	if len(shown) > maxBytesShown {
+		shown = shown[:maxBytesShown]
+	}
+	parts := make([]string, 0, len(shown))
+	for _, b := range shown {
+		parts = append(parts, fmt.Sprint(b))
+	}
+	result := "[" + strings.Join(parts, ", ")
+	if len(shown) < len(value) {
+		result += ", ..."
+	}
+	return result + "]"
+}
+
+func truncatedHex(value []byte) string {
+	if len(value) > maxBytesShown {
+		return hex.EncodeToString(value[:maxBytesShown]) + "..."
+	}
+	return hex.EncodeToString(value)
+}
+
+func truncated(str string) string {
+	if len(str) > 4*maxBytesShown {
+		return str[:4*maxBytesShown] + "..."
+	}
+	return str
+}
@@ -0,0 +1,120 @@
+package scenario_lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// rpcMessage is an incoming request or notification. Notifications have no ID.
+type rpcMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+type rpcResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+type rpcErrorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   *rpcError        `json:"error"`
+}
+
+type rpcNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// rpcConn reads and writes messages framed by a Content-Length header, as in the LSP base protocol.
+type rpcConn struct {
+	reader *bufio.Reader
+	writer io.Writer
+}
+
+func newRPCConn(reader io.Reader, writer io.Writer) *rpcConn {
+	return &rpcConn{
+		reader: bufio.NewReader(reader),
+		writer: writer,
+	}
+}
+
+func (c *rpcConn) readMessage() ([]byte, error) {
+	contentLength := -1
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			break
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid header line: %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			contentLength, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %w", err)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+
+	content := make([]byte, contentLength)
+	_, err := io.ReadFull(c.reader, content)
+	return content, err
+}
+
+func (c *rpcConn) write(message interface{}) error {
+	content, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n%s", len(content), content)
+	return err
+}
+
+func (c *rpcConn) reply(id *json.RawMessage, result interface{}) error {
+	return c.write(&rpcResponse{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (c *rpcConn) replyError(id *json.RawMessage, err *rpcError) error {
+	return c.write(&rpcErrorResponse{JSONRPC: "2.0", ID: id, Error: err})
+}
+
+func (c *rpcConn) notify(method string, params interface{}) error {
+	return c.write(&rpcNotification{JSONRPC: "2.0", Method: method, Params: params})
+}
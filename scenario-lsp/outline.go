@@ -0,0 +1,281 @@
+package scenario_lsp
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// The outline is a lenient parse of the document, used to find out what the cursor points at.
+// Unlike the scenario parser, it accepts comments and incomplete documents, as they appear while typing.
+
+type nodeKind int
+
+const (
+	nodeObject nodeKind = iota
+	nodeArray
+	nodeString
+	nodeLiteral
+)
+
+type node struct {
+	kind nodeKind
+
+	// start and end are byte offsets, end points just after the node
+	start int
+	end   int
+
+	// value is the decoded string, or the literal as written
+	value string
+
+	entries []*entry
+	items   []*node
+
+	// unterminated nodes extend up to the end of the document, or line, for strings
+	unterminated bool
+}
+
+type entry struct {
+	key *node
+
+	// colon is the offset of the ':' following the key, or -1 if missing
+	colon int
+
+	// value is nil if missing
+	value *node
+}
+
+// field yields the value of the entry with the given key, or nil.
+func (n *node) field(key string) *node {
+	for _, e := range n.entries {
+		if e.key.value == key {
+			return e.value
+		}
+	}
+	return nil
+}
+
+func (n *node) stringValue() (string, bool) {
+	if n == nil || n.kind != nodeString {
+		return "", false
+	}
+	return n.value, true
+}
+
+type tokenKind int
+
+const (
+	tokenPunctuation tokenKind = iota
+	tokenString
+	tokenLiteral
+)
+
+type token struct {
+	kind  tokenKind
+	start int
+	end   int
+
+	unterminated bool
+}
+
+func tokenize(text string) []*token {
+	var tokens []*token
+	i := 0
+	for i < len(text) {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(text[i:], "//"):
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				i = len(text)
+			} else {
+				i += end + 4
+			}
+		case strings.IndexByte("{}[]:,", c) >= 0:
+			tokens = append(tokens, &token{kind: tokenPunctuation, start: i, end: i + 1})
+			i++
+		case c == '"':
+			tok := &token{kind: tokenString, start: i}
+			i++
+			for {
+				if i >= len(text) || text[i] == '\n' {
+					tok.unterminated = true
+					break
+				}
+				if text[i] == '\\' {
+					i += 2
+					continue
+				}
+				i++
+				if text[i-1] == '"' {
+					break
+				}
+			}
+			if i > len(text) {
+				i = len(text)
+			}
+			tok.end = i
+			tokens = append(tokens, tok)
+		default:
+			tok := &token{kind: tokenLiteral, start: i}
+			for i < len(text) && strings.IndexByte(" \t\r\n{}[]:,\"/", text[i]) < 0 {
+				i++
+			}
+			if i == tok.start {
+				// a lone '/'
+				i++
+			}
+			tok.end = i
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens
+}
+
+// tokenAt yields the token starting at the given offset, or nil.
+func tokenAt(tokens []*token, offset int) *token {
+	for _, tok := range tokens {
+		if tok.start == offset {
+			return tok
+		}
+	}
+	return nil
+}
+
+type outlineParser struct {
+	text   string
+	tokens []*token
+	next   int
+}
+
+// parseOutline parses the first value in the text. It yields nil for an empty document.
+func parseOutline(text string) *node {
+	p := &outlineParser{text: text, tokens: tokenize(text)}
+	return p.parseValue()
+}
+
+func (p *outlineParser) peek() *token {
+	if p.next >= len(p.tokens) {
+		return nil
+	}
+	return p.tokens[p.next]
+}
+
+func (p *outlineParser) isPunctuation(tok *token, c byte) bool {
+	return tok != nil && tok.kind == tokenPunctuation && p.text[tok.start] == c
+}
+
+func (p *outlineParser) parseValue() *node {
+	tok := p.peek()
+	if tok == nil {
+		return nil
+	}
+	switch {
+	case tok.kind == tokenString:
+		p.next++
+		return p.stringNode(tok)
+	case tok.kind == tokenLiteral:
+		p.next++
+		return &node{kind: nodeLiteral, start: tok.start, end: tok.end, value: p.text[tok.start:tok.end]}
+	case p.isPunctuation(tok, '{'):
+		p.next++
+		return p.parseObject(tok.start)
+	case p.isPunctuation(tok, '['):
+		p.next++
+		return p.parseArray(tok.start)
+	default:
+		return nil
+	}
+}
+
+func (p *outlineParser) stringNode(tok *token) *node {
+	raw := p.text[tok.start:tok.end]
+	if tok.unterminated {
+		raw += `"`
+	}
+	var value string
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		// keep the raw contents of strings with bad escapes
+		value = strings.TrimSuffix(raw[1:], `"`)
+	}
+	return &node{kind: nodeString, start: tok.start, end: tok.end, value: value, unterminated: tok.unterminated}
+}
+
+func (p *outlineParser) parseObject(start int) *node {
+	obj := &node{kind: nodeObject, start: start}
+	for {
+		tok := p.peek()
+		switch {
+		case tok == nil:
+			obj.end = len(p.text)
+			obj.unterminated = true
+			return obj
+		case p.isPunctuation(tok, '}'):
+			p.next++
+			obj.end = tok.end
+			return obj
+		case p.isPunctuation(tok, ']'):
+			// leave it to the enclosing array
+			obj.end = tok.start
+			obj.unterminated = true
+			return obj
+		case tok.kind == tokenString:
+			p.next++
+			e := &entry{key: p.stringNode(tok), colon: -1}
+			obj.entries = append(obj.entries, e)
+			if colon := p.peek(); p.isPunctuation(colon, ':') {
+				p.next++
+				e.colon = colon.start
+				e.value = p.parseValue()
+			}
+		default:
+			// stray punctuation or literal in key position
+			p.next++
+		}
+	}
+}
+
+func (p *outlineParser) parseArray(start int) *node {
+	arr := &node{kind: nodeArray, start: start}
+	for {
+		tok := p.peek()
+		switch {
+		case tok == nil:
+			arr.end = len(p.text)
+			arr.unterminated = true
+			return arr
+		case p.isPunctuation(tok, ']'):
+			p.next++
+			arr.end = tok.end
+			return arr
+		case p.isPunctuation(tok, '}'):
+			// leave it to the enclosing object
+			arr.end = tok.start
+			arr.unterminated = true
+			return arr
+		case p.isPunctuation(tok, ','), p.isPunctuation(tok, ':'):
+			p.next++
+		default:
+			item := p.parseValue()
+			if item == nil {
+				p.next++
+				continue
+			}
+			arr.items = append(arr.items, item)
+		}
+	}
+}
+
+// contains checks whether a cursor placed at the offset is inside the node.
+// A cursor right after an unterminated node is still inside it, since the user is likely typing it.
+func (n *node) contains(offset int) bool {
+	if n.kind == nodeLiteral {
+		return n.start <= offset && offset <= n.end
+	}
+	return n.start < offset && (offset < n.end || (n.unterminated && offset <= n.end))
+}
@@ -0,0 +1,224 @@
+package scenario_lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	fr "github.com/bhagyaraj1208117/andes-scenario-go/fileresolver"
+)
+
+const serverName = "scenario-lsp"
+
+// Server is a language server for scenario files, speaking LSP over a stream, usually stdio.
+// Documents are synchronized in full on every change.
+type Server struct {
+	// FileResolver is cloned for each document and resolves paths relative to it.
+	FileResolver fr.FileResolver
+
+	// ExprInterpreter holds the interpreter settings and registered functions.
+	// It is cloned for each document, with the document's resolver and constants.
+	ExprInterpreter ei.ExprInterpreter
+
+	conn      *rpcConn
+	documents map[string]*document
+	shutdown  bool
+}
+
+// NewServer creates a server reading requests from reader and writing responses to writer.
+func NewServer(reader io.Reader, writer io.Writer) *Server {
+	return &Server{
+		FileResolver: fr.NewDefaultFileResolver(),
+		conn:         newRPCConn(reader, writer),
+		documents:    make(map[string]*document),
+	}
+}
+
+// Run serves requests until the client sends "exit", or closes the stream.
+// It returns an error if the exit was not preceded by a shutdown request.
+func (s *Server) Run() error {
+	for {
+		content, err := s.conn.readMessage()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(content, &msg); err != nil {
+			if err := s.conn.replyError(nil, &rpcError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+
+		result, rpcErr := s.handleRecovering(&msg)
+		if msg.ID == nil {
+			// notifications get no response
+			continue
+		}
+		if rpcErr != nil {
+			err = s.conn.replyError(msg.ID, rpcErr)
+		} else {
+			err = s.conn.reply(msg.ID, result)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handleRecovering handles a message, turning a panic in any of the handlers,
+// e.g. from interpreting a malformed file, into an internal error for that request only.
+func (s *Server) handleRecovering(msg *rpcMessage) (result interface{}, rpcErr *rpcError) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			rpcErr = &rpcError{Code: codeInternalError, Message: fmt.Sprintf("internal error handling %s: %v", msg.Method, r)}
+		}
+	}()
+	return s.handle(msg)
+}
+
+func (s *Server) handle(msg *rpcMessage) (interface{}, *rpcError) {
+	switch msg.Method {
+	case "initialize":
+		return &initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:   textDocumentSyncFull,
+				HoverProvider:      true,
+				DefinitionProvider: true,
+				CompletionProvider: &completionOptions{TriggerCharacters: []string{`"`, ":"}},
+			},
+			ServerInfo: serverInfo{Name: serverName},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		return nil, s.openDocument(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// full synchronization, the last change holds the whole text
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.openDocument(params.TextDocument.URI, text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, s.publishDiagnostics(params.TextDocument.URI, []lspDiagnostic{})
+	case "textDocument/hover":
+		doc, offset, rpcErr := s.documentPosition(msg.Params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		if result := s.hover(doc, offset); result != nil {
+			return result, nil
+		}
+		return nil, nil
+	case "textDocument/definition":
+		doc, offset, rpcErr := s.documentPosition(msg.Params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		if result := s.definition(doc, offset); result != nil {
+			return result, nil
+		}
+		return nil, nil
+	case "textDocument/completion":
+		doc, offset, rpcErr := s.documentPosition(msg.Params)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		return s.completion(doc, offset), nil
+	default:
+		if msg.ID == nil {
+			// unknown notifications, e.g. "$/cancelRequest", are ignored
+			return nil, nil
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", msg.Method)}
+	}
+}
+
+func invalidParams(err error) *rpcError {
+	return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+}
+
+func (s *Server) openDocument(uri string, text string) *rpcError {
+	doc := newDocument(uri, text)
+	s.documents[uri] = doc
+	return s.publishDiagnostics(uri, s.diagnostics(doc))
+}
+
+func (s *Server) publishDiagnostics(uri string, diagnostics []lspDiagnostic) *rpcError {
+	err := s.conn.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+	if err != nil {
+		return &rpcError{Code: codeInternalError, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) documentPosition(params json.RawMessage) (*document, int, *rpcError) {
+	var positionParams textDocumentPositionParams
+	if err := json.Unmarshal(params, &positionParams); err != nil {
+		return nil, 0, invalidParams(err)
+	}
+	doc, found := s.documents[positionParams.TextDocument.URI]
+	if !found {
+		return nil, 0, &rpcError{
+			Code:    codeInvalidRequest,
+			Message: fmt.Sprintf("document not open: %s", positionParams.TextDocument.URI),
+		}
+	}
+	return doc, doc.offsetAt(positionParams.Position), nil
+}
+
+// resolverFor yields a file resolver for paths relative to the document.
+func (s *Server) resolverFor(doc *document) fr.FileResolver {
+	resolver := s.FileResolver.Clone()
+	resolver.SetContext(doc.path)
+	return resolver
+}
+
+// interpreterFor yields the interpreter of the document's last parse, which knows its constants,
+// or a fresh one for documents that are not parsed as scenarios.
+func (s *Server) interpreterFor(doc *document) *ei.ExprInterpreter {
+	if doc.interpreter != nil {
+		return doc.interpreter
+	}
+	interpreter := s.newInterpreter(doc)
+	return &interpreter
+}
+
+func (s *Server) newInterpreter(doc *document) ei.ExprInterpreter {
+	interpreter := s.ExprInterpreter.Clone()
+	interpreter.FileResolver = s.resolverFor(doc)
+	return interpreter
+}
@@ -0,0 +1,411 @@
+package scenario_lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ei "github.com/bhagyaraj1208117/andes-scenario-go/expression/interpreter"
+	mjparse "github.com/bhagyaraj1208117/andes-scenario-go/json/parse"
+	mj "github.com/bhagyaraj1208117/andes-scenario-go/model"
+	"github.com/stretchr/testify/require"
+)
+
+const testScenario = `{
+    "name": "test",
+    "steps": [
+        {
+            "step": "setState",
+            "accounts": {
+                "address:owner": {
+                    "nonce": "0",
+                    "balance": "1000"
+                }
+            },
+            "newAddresses": [
+                {
+                    "creatorAddress": "address:owner",
+                    "creatorNonce": "0",
+                    "newAddress": "sc:adder"
+                }
+            ]
+        },
+        {
+            "step": "externalSteps",
+            "path": "other.steps.json"
+        },
+        {
+            "step": "scDeploy",
+            "tx": {
+                "from": "address:owner",
+                "contractCode": "file:adder.wasm",
+                "arguments": ["str:abc"],
+                "gasLimit": "5,000,000",
+                "gasPrice": "0"
+            }
+        }
+    ]
+}
+`
+
+func newTestServer(t *testing.T, fileName string, text string) (*Server, *document) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, fileName)
+	server := NewServer(strings.NewReader(""), &bytes.Buffer{})
+	server.documents[pathToURI(path)] = newDocument(pathToURI(path), text)
+	return server, server.documents[pathToURI(path)]
+}
+
+// offsetOf yields the offset of the marker, plus shift.
+func offsetOf(t *testing.T, doc *document, marker string, shift int) int {
+	index := strings.Index(doc.text, marker)
+	require.GreaterOrEqual(t, index, 0, marker)
+	return index + shift
+}
+
+func labels(list *completionList) []string {
+	var result []string
+	for _, item := range list.Items {
+		result = append(result, item.Label)
+	}
+	return result
+}
+
+func TestDiagnostics(t *testing.T) {
+	text := strings.Replace(testScenario, `"nonce": "0",`, `"nonce": "0", "unknown": "1",`, 1)
+	text = strings.Replace(text, `"gasPrice": "0"`, `"gasPrice": "xyz:0"`, 1)
+	server, doc := newTestServer(t, "test.scen.json", text)
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(doc.path), "adder.wasm"), []byte{0, 1}, 0644))
+
+	diagnostics := server.diagnostics(doc)
+	require.Len(t, diagnostics, 2)
+
+	require.Equal(t, diagnosticSeverityError, diagnostics[0].Severity)
+	require.Contains(t, diagnostics[0].Message, "unknown")
+	require.Equal(t, 7, diagnostics[0].Range.Start.Line)
+
+	require.Contains(t, diagnostics[1].Message, "gasPrice")
+	require.Equal(t, 30, diagnostics[1].Range.Start.Line)
+
+	_, valid := newTestServer(t, "test.scen.json", testScenario)
+	diagnostics = server.diagnostics(valid)
+	require.Len(t, diagnostics, 1)
+	require.Contains(t, diagnostics[0].Message, "adder.wasm")
+}
+
+func TestDiagnosticsSyntaxError(t *testing.T) {
+	server, doc := newTestServer(t, "test.scen.json", "{\n  \"name\": \"x\",\n  \"steps\": [\n")
+	diagnostics := server.diagnostics(doc)
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, diagnosticSeverityError, diagnostics[0].Severity)
+}
+
+func TestDiagnosticsInvalidTx(t *testing.T) {
+	text := strings.Replace(testScenario, `"tx": {`, `"tx": 5, "expect": {"status": "0"}, "comment": {`, 1)
+	server, doc := newTestServer(t, "test.scen.json", text)
+	var messages []string
+	for _, diagnostic := range server.diagnostics(doc) {
+		messages = append(messages, diagnostic.Message)
+	}
+	require.Equal(t, []string{
+		"steps[2].tx: cannot parse tx step transaction: unmarshalled transaction is not a map",
+		"steps[2].expect: expect without a valid tx",
+		"steps[2].comment: bad tx step comment: not a string value",
+	}, messages)
+}
+
+func TestDiagnosticsParserPanic(t *testing.T) {
+	diagnostics := recoverDiagnostics(func() []*mjparse.Diagnostic {
+		var tx *mj.Transaction
+		return []*mjparse.Diagnostic{{Message: tx.Function}}
+	})
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, mjparse.SeverityError, diagnostics[0].Severity)
+	require.Contains(t, diagnostics[0].Message, "internal parser error: runtime error: invalid memory address or nil pointer dereference")
+}
+
+func TestHoverExpression(t *testing.T) {
+	server, doc := newTestServer(t, "test.scen.json", testScenario)
+
+	hover := server.hover(doc, offsetOf(t, doc, `"str:abc"`, 3))
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "bytes (3): `[97, 98, 99]`")
+	require.Contains(t, hover.Contents.Value, "hex: `0x616263`")
+	require.Contains(t, hover.Contents.Value, "reconstructed: `str:abc`")
+	require.Equal(t, doc.rangeOf(offsetOf(t, doc, `"str:abc"`, 0), offsetOf(t, doc, `"str:abc"`, 9)), *hover.Range)
+
+	hover = server.hover(doc, offsetOf(t, doc, `"5,000,000"`, 1))
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "hex: `0x4c4b40`")
+	require.Contains(t, hover.Contents.Value, "reconstructed: `5000000`")
+
+	// account keys are addresses
+	hover = server.hover(doc, offsetOf(t, doc, `"address:owner": {`, 2))
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "bytes (32)")
+	require.Contains(t, hover.Contents.Value, "reconstructed: `address:owner`")
+
+	// missing file
+	hover = server.hover(doc, offsetOf(t, doc, `"file:adder.wasm"`, 2))
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "cannot interpret")
+
+	// plain strings are not expressions
+	require.Nil(t, server.hover(doc, offsetOf(t, doc, `"other.steps.json"`, 2)))
+}
+
+func TestHoverDocumentInterpreter(t *testing.T) {
+	server, doc := newTestServer(t, "test.scen.json", `{
+    "constants": {
+        "total": "1,000"
+    },
+    "steps": [
+        {
+            "step": "setState",
+            "accounts": {
+                "address:owner": {
+                    "balance": "${total}",
+                    "storage": {
+                        "str:key": "twice:str:ab"
+                    }
+                }
+            }
+        }
+    ]
+}`)
+	require.NoError(t, server.ExprInterpreter.RegisterFunction(&ei.Function{
+		Name: "twice",
+		Args: []ei.FunctionArgKind{ei.ExpressionArg},
+		Call: func(args [][]byte) ([]byte, error) {
+			return append(args[0], args[0]...), nil
+		},
+	}))
+
+	// before the document is parsed, only the registered functions are known
+	hover := server.hover(doc, offsetOf(t, doc, `"${total}"`, 2))
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "cannot interpret")
+	hover = server.hover(doc, offsetOf(t, doc, `"twice:str:ab"`, 2))
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "hex: `0x61626162`")
+
+	require.Empty(t, server.diagnostics(doc))
+	hover = server.hover(doc, offsetOf(t, doc, `"${total}"`, 2))
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "hex: `0x03e8`")
+	hover = server.hover(doc, offsetOf(t, doc, `"twice:str:ab"`, 2))
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "hex: `0x61626162`")
+}
+
+func TestHoverField(t *testing.T) {
+	server, doc := newTestServer(t, "test.scen.json", testScenario)
+
+	hover := server.hover(doc, offsetOf(t, doc, `"path"`, 2))
+	require.NotNil(t, hover)
+	require.Contains(t, hover.Contents.Value, "**path** (externalStepsStep)")
+	require.Contains(t, hover.Contents.Value, "relative to the current file")
+}
+
+func TestDefinition(t *testing.T) {
+	server, doc := newTestServer(t, "test.scen.json", testScenario)
+	dir := filepath.Dir(doc.path)
+
+	// files do not exist yet
+	require.Nil(t, server.definition(doc, offsetOf(t, doc, `"other.steps.json"`, 2)))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.steps.json"), []byte(`{"steps":[]}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "adder.wasm"), []byte{0, 1}, 0644))
+
+	location := server.definition(doc, offsetOf(t, doc, `"other.steps.json"`, 2))
+	require.NotNil(t, location)
+	require.Equal(t, pathToURI(filepath.Join(dir, "other.steps.json")), location.URI)
+
+	location = server.definition(doc, offsetOf(t, doc, `"file:adder.wasm"`, 8))
+	require.NotNil(t, location)
+	require.Equal(t, pathToURI(filepath.Join(dir, "adder.wasm")), location.URI)
+
+	require.Nil(t, server.definition(doc, offsetOf(t, doc, `"str:abc"`, 2)))
+}
+
+func TestCompletionStepNames(t *testing.T) {
+	server, doc := newTestServer(t, "test.scen.json", `{
+    "steps": [
+        {
+            "step": "sc
+        }
+    ]
+}`)
+	list := server.completion(doc, offsetOf(t, doc, `"sc`, 3))
+	require.Contains(t, labels(list), "scCall")
+	require.Contains(t, labels(list), "setState")
+	require.Contains(t, labels(list), "externalSteps")
+
+	// the contents of the string are replaced
+	edit := list.Items[0].TextEdit
+	require.Equal(t, doc.rangeOf(offsetOf(t, doc, `"sc`, 1), offsetOf(t, doc, `"sc`, 3)), edit.Range)
+}
+
+func TestCompletionFieldNames(t *testing.T) {
+	server, doc := newTestServer(t, "test.scen.json", `{
+    "steps": [
+        {
+            "step": "scCall",
+            "id": "1",
+            
+        },
+        {
+            
+        }
+    ]
+}`)
+	list := server.completion(doc, offsetOf(t, doc, `"1",`, 17))
+	require.Contains(t, labels(list), "tx")
+	require.Contains(t, labels(list), "expect")
+	require.NotContains(t, labels(list), "id")
+	require.NotContains(t, labels(list), "accounts")
+	require.Equal(t, `"tx": `, list.Items[indexOf(labels(list), "tx")].TextEdit.NewText)
+
+	// without a step type, fields of all steps are offered
+	list = server.completion(doc, offsetOf(t, doc, "{\n            \n        }\n    ]", 14))
+	require.Contains(t, labels(list), "step")
+	require.Contains(t, labels(list), "tx")
+	require.Contains(t, labels(list), "accounts")
+	require.Contains(t, labels(list), "path")
+
+	// top level
+	list = server.completion(doc, 1)
+	require.Equal(t, []string{"name", "comment", "tags", "checkGas", "traceGas", "gasSchedule", "constants"}, labels(list))
+}
+
+func TestCompletionAddresses(t *testing.T) {
+	text := strings.Replace(testScenario, `"from": "address:owner"`, `"from": "ad"`, 1)
+	server, doc := newTestServer(t, "test.scen.json", text)
+
+	list := server.completion(doc, offsetOf(t, doc, `"ad"`, 3))
+	result := labels(list)
+	require.Equal(t, []string{"address:owner", "sc:adder"}, result[:2])
+	require.Contains(t, result, "str:")
+
+	// non-address fields only get prefixes
+	list = server.completion(doc, offsetOf(t, doc, `"5,000,000"`, 1))
+	require.NotContains(t, labels(list), "address:owner")
+	require.Contains(t, labels(list), "u64:")
+}
+
+func indexOf(list []string, str string) int {
+	for i, elem := range list {
+		if elem == str {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestPositionConversion(t *testing.T) {
+	doc := newDocument("file:///a.scen.json", "{\n  \"str:é𝄞\": \"x\"\n}")
+	pos := lspPosition{Line: 1, Character: 10}
+	offset := doc.offsetAt(pos)
+	require.Equal(t, `"`, doc.text[offset:offset+1])
+	require.Equal(t, pos, doc.positionAt(offset))
+}
+
+func writeMessage(buf *bytes.Buffer, message string) {
+	fmt.Fprintf(buf, "Content-Length: %d\r\n\r\n%s", len(message), message)
+}
+
+func readMessages(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	conn := newRPCConn(output, nil)
+	var messages []map[string]interface{}
+	for {
+		content, err := conn.readMessage()
+		if err != nil {
+			return messages
+		}
+		var message map[string]interface{}
+		require.NoError(t, json.Unmarshal(content, &message))
+		messages = append(messages, message)
+	}
+}
+
+func TestRun(t *testing.T) {
+	input := &bytes.Buffer{}
+	writeMessage(input, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	writeMessage(input, `{"jsonrpc":"2.0","method":"initialized","params":{}}`)
+	writeMessage(input, `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":`+
+		`{"uri":"file:///tmp/a.scen.json","languageId":"json","version":1,"text":"{\"name\": 1}"}}}`)
+	writeMessage(input, `{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{"textDocument":`+
+		`{"uri":"file:///tmp/a.scen.json"},"position":{"line":0,"character":3}}}`)
+	writeMessage(input, `{"jsonrpc":"2.0","id":3,"method":"unknown"}`)
+	writeMessage(input, `{"jsonrpc":"2.0","id":4,"method":"shutdown"}`)
+	writeMessage(input, `{"jsonrpc":"2.0","method":"exit"}`)
+
+	output := &bytes.Buffer{}
+	server := NewServer(input, output)
+	require.NoError(t, server.Run())
+
+	messages := readMessages(t, output)
+	require.Len(t, messages, 5)
+
+	require.Equal(t, float64(1), messages[0]["id"])
+	capabilities := messages[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	require.Equal(t, true, capabilities["hoverProvider"])
+
+	require.Equal(t, "textDocument/publishDiagnostics", messages[1]["method"])
+	diagnostics := messages[1]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	require.Len(t, diagnostics, 1)
+
+	require.Equal(t, float64(2), messages[2]["id"])
+	result, hasResult := messages[2]["result"]
+	require.True(t, hasResult)
+	require.Contains(t, result.(map[string]interface{})["contents"].(map[string]interface{})["value"], "**name**")
+
+	require.Equal(t, float64(codeMethodNotFound), messages[3]["error"].(map[string]interface{})["code"])
+
+	require.Equal(t, float64(4), messages[4]["id"])
+	require.Nil(t, messages[4]["result"])
+}
+
+func TestRunRecoversFromPanic(t *testing.T) {
+	dir := t.TempDir()
+	// the code of an mxsc file is expected to be a string
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.mxsc.json"), []byte(`{"code": 5}`), 0644))
+	uri := pathToURI(filepath.Join(dir, "a.scen.json"))
+	text := `{"steps": [{"step": "setState", "accounts": {"address:a": {"code": "mxsc:bad.mxsc.json"}}}]}`
+	openParams, err := json.Marshal(map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "json", "version": 1, "text": text},
+	})
+	require.NoError(t, err)
+	hoverParams, err := json.Marshal(map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     map[string]interface{}{"line": 0, "character": strings.Index(text, "mxsc:") + 1},
+	})
+	require.NoError(t, err)
+
+	input := &bytes.Buffer{}
+	writeMessage(input, `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":`+string(openParams)+`}`)
+	writeMessage(input, `{"jsonrpc":"2.0","id":1,"method":"textDocument/hover","params":`+string(hoverParams)+`}`)
+	writeMessage(input, `{"jsonrpc":"2.0","id":2,"method":"shutdown"}`)
+	writeMessage(input, `{"jsonrpc":"2.0","method":"exit"}`)
+
+	output := &bytes.Buffer{}
+	server := NewServer(input, output)
+	require.NoError(t, server.Run())
+
+	messages := readMessages(t, output)
+	require.Len(t, messages, 3)
+	require.Equal(t, "textDocument/publishDiagnostics", messages[0]["method"])
+
+	require.Equal(t, float64(1), messages[1]["id"])
+	rpcErr := messages[1]["error"].(map[string]interface{})
+	require.Equal(t, float64(codeInternalError), rpcErr["code"])
+	require.Contains(t, rpcErr["message"], "internal error handling textDocument/hover")
+
+	// the server keeps serving after the panic
+	require.Equal(t, float64(2), messages[2]["id"])
+	require.Nil(t, messages[2]["error"])
+}